@@ -5,11 +5,19 @@ go 1.25.5
 require (
 	github.com/amterp/ra v0.4.3
 	github.com/dustinkirkland/golang-petname v0.0.0-20240428194347-eebcea082ee0
+	modernc.org/sqlite v1.47.0
 )
 
 require (
 	github.com/amterp/color v1.20.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.31.0 // indirect
+	github.com/ncruces/go-strftime v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/sys v0.42.0 // indirect
+	modernc.org/libc v1.70.0 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
 )
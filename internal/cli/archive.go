@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	archiveCmd       *ra.Cmd
+	archiveSessionID *string
+)
+
+func setupArchiveCmd() *ra.Cmd {
+	archiveCmd = ra.NewCmd("archive")
+	archiveCmd.SetDescription("Archive a session (read-only, hidden from 'council list')")
+
+	archiveSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to archive").
+		Register(archiveCmd)
+
+	return archiveCmd
+}
+
+func handleArchive() {
+	if err := session.ArchiveSession(*archiveSessionID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Archived session %s.\n", *archiveSessionID)
+}
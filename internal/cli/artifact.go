@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	artifactCmd *ra.Cmd
+
+	artifactPutCmd         *ra.Cmd
+	artifactPutUsed        *bool
+	artifactPutSessionID   *string
+	artifactPutParticipant *string
+	artifactPutName        *string
+	artifactPutFile        *string
+
+	artifactGetCmd       *ra.Cmd
+	artifactGetUsed      *bool
+	artifactGetSessionID *string
+	artifactGetName      *string
+	artifactGetOut       *string
+
+	artifactListCmd       *ra.Cmd
+	artifactListUsed      *bool
+	artifactListSessionID *string
+)
+
+func setupArtifactCmd() *ra.Cmd {
+	artifactCmd = ra.NewCmd("artifact")
+	artifactCmd.SetDescription("Share files in a session without pasting them into messages")
+
+	artifactPutUsed, _ = artifactCmd.RegisterCmd(setupArtifactPutCmd())
+	artifactGetUsed, _ = artifactCmd.RegisterCmd(setupArtifactGetCmd())
+	artifactListUsed, _ = artifactCmd.RegisterCmd(setupArtifactListCmd())
+
+	return artifactCmd
+}
+
+func setupArtifactPutCmd() *ra.Cmd {
+	artifactPutCmd = ra.NewCmd("put")
+	artifactPutCmd.SetDescription("Add a file to the session's artifacts")
+
+	artifactPutSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to add the artifact to").
+		Register(artifactPutCmd)
+
+	artifactPutParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name adding the artifact").
+		Register(artifactPutCmd)
+
+	artifactPutName, _ = ra.NewString("name").
+		SetShort("n").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Artifact name (defaults to the file's name)").
+		Register(artifactPutCmd)
+
+	artifactPutFile, _ = ra.NewString("file").
+		SetShort("f").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Read content from file instead of stdin").
+		Register(artifactPutCmd)
+
+	return artifactPutCmd
+}
+
+func setupArtifactGetCmd() *ra.Cmd {
+	artifactGetCmd = ra.NewCmd("get")
+	artifactGetCmd.SetDescription("Print or save an artifact's content")
+
+	artifactGetSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the artifact").
+		Register(artifactGetCmd)
+
+	artifactGetName, _ = ra.NewString("name").
+		SetShort("n").
+		SetFlagOnly(true).
+		SetUsage("Artifact name, or its hash as shown by status (e.g. sha256:3f2a9c1b4d5e)").
+		Register(artifactGetCmd)
+
+	artifactGetOut, _ = ra.NewString("out").
+		SetShort("o").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Write to this file instead of stdout").
+		Register(artifactGetCmd)
+
+	return artifactGetCmd
+}
+
+func setupArtifactListCmd() *ra.Cmd {
+	artifactListCmd = ra.NewCmd("list")
+	artifactListCmd.SetDescription("List the session's artifacts")
+
+	artifactListSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to list artifacts of").
+		Register(artifactListCmd)
+
+	return artifactListCmd
+}
+
+func handleArtifact() {
+	switch {
+	case *artifactPutUsed:
+		handleArtifactPut()
+	case *artifactGetUsed:
+		handleArtifactGet()
+	case *artifactListUsed:
+		handleArtifactList()
+	default:
+		fmt.Fprintln(os.Stderr, "Error: choose what to do: 'council artifact put', 'get' or 'list'.")
+		os.Exit(1)
+	}
+}
+
+func handleArtifactPut() {
+	name := ""
+	if artifactPutCmd.Configured("name") {
+		name = *artifactPutName
+	} else if *artifactPutFile != "" {
+		name = filepath.Base(*artifactPutFile)
+	} else {
+		fmt.Fprintln(os.Stderr, "Error: --name is required when reading from stdin.")
+		os.Exit(1)
+	}
+
+	var data []byte
+	var err error
+	if *artifactPutFile != "" {
+		data, err = os.ReadFile(*artifactPutFile)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	eventNum, err := session.PutArtifact(*artifactPutSessionID, *artifactPutParticipant, name, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Added artifact %s (%s) as event #%d. Fetch it with 'council artifact get %s --name %s'.\n",
+		name, session.FormatSize(int64(len(data))), eventNum, *artifactPutSessionID, name)
+}
+
+func handleArtifactGet() {
+	sess, err := session.LoadHistory(*artifactGetSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	artifact, data, err := session.GetArtifact(sess, *artifactGetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *artifactGetOut == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*artifactGetOut, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write artifact: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s (%s) to %s\n", artifact.Name, session.FormatSize(artifact.Size), *artifactGetOut)
+}
+
+func handleArtifactList() {
+	sess, err := session.LoadHistory(*artifactListSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	artifacts := sess.Artifacts()
+	if len(artifacts) == 0 {
+		fmt.Printf("No artifacts in session %s. Add one with 'council artifact put'.\n", sess.ID)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tHASH\tBY\tEVENT\tVERSIONS")
+	for _, a := range artifacts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t#%d\t%d\n", a.Name, session.FormatSize(a.Size), a.ShortHash(), a.Participant, a.EventNum, a.Versions)
+	}
+	w.Flush()
+}
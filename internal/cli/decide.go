@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	decideCmd          *ra.Cmd
+	decideSessionID    *string
+	decideParticipant  *string
+	decideAfter        *int
+	decideTitle        *string
+	decideRationale    *string
+	decideFile         *string
+	decideAlternatives *[]string
+	decideSupersedes   *int
+)
+
+func setupDecideCmd() *ra.Cmd {
+	decideCmd = ra.NewCmd("decide")
+	decideCmd.SetDescription("Record a decision in the session's decision log")
+
+	decideSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to record the decision in").
+		Register(decideCmd)
+
+	decideParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name recording the decision").
+		Register(decideCmd)
+
+	decideAfter, _ = ra.NewInt("after").
+		SetFlagOnly(true).
+		SetUsage("Only record if latest event is exactly N").
+		Register(decideCmd)
+
+	decideTitle, _ = ra.NewString("title").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetUsage("What was decided, in one line").
+		Register(decideCmd)
+
+	decideRationale, _ = ra.NewString("rationale").
+		SetShort("r").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Why it was decided (read from --file or stdin if omitted)").
+		Register(decideCmd)
+
+	decideFile, _ = ra.NewString("file").
+		SetShort("f").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Read the rationale from file instead of stdin").
+		Register(decideCmd)
+
+	decideAlternatives, _ = ra.NewStringSlice("alternative").
+		SetShort("a").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("An option considered and not chosen (repeat for each)").
+		Register(decideCmd)
+
+	decideSupersedes, _ = ra.NewInt("supersedes").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Event number of an earlier decision this one replaces").
+		Register(decideCmd)
+
+	return decideCmd
+}
+
+func handleDecide() {
+	spec := session.DecisionSpec{Title: *decideTitle}
+	if decideCmd.Configured("rationale") {
+		spec.Rationale = *decideRationale
+	} else {
+		rationale, err := readContent(*decideFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		spec.Rationale = rationale
+	}
+	if decideAlternatives != nil {
+		spec.Alternatives = *decideAlternatives
+	}
+	if decideCmd.Configured("supersedes") {
+		spec.Supersedes = *decideSupersedes
+	}
+
+	eventNum, err := session.Decide(*decideSessionID, *decideParticipant, spec, *decideAfter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Recorded decision as event #%d.\n", eventNum)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	decisionsCmd       *ra.Cmd
+	decisionsSessionID *string
+	decisionsADRDir    *string
+	decisionsForce     *bool
+)
+
+func setupDecisionsCmd() *ra.Cmd {
+	decisionsCmd = ra.NewCmd("decisions")
+	decisionsCmd.SetDescription("List a session's decisions, or export them as ADRs")
+
+	decisionsSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to list decisions of").
+		Register(decisionsCmd)
+
+	decisionsADRDir, _ = ra.NewString("adr-dir").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Write each decision as an Architecture Decision Record into this directory").
+		Register(decisionsCmd)
+
+	decisionsForce, _ = ra.NewBool("force").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("With --adr-dir, rewrite records this session already exported instead of skipping them").
+		Register(decisionsCmd)
+
+	return decisionsCmd
+}
+
+func handleDecisions() {
+	sess, err := session.LoadSession(*decisionsSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !decisionsCmd.Configured("adr-dir") {
+		if *decisionsForce {
+			fmt.Fprintf(os.Stderr, "Error: --force requires --adr-dir\n")
+			os.Exit(1)
+		}
+		fmt.Print(session.FormatDecisions(sess))
+		return
+	}
+
+	if len(sess.SortedDecisions()) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: session '%s' has no decisions to export\n", sess.ID)
+		os.Exit(1)
+	}
+	adrs, err := session.ADRs(sess, *decisionsADRDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	written, skipped, err := session.WriteADRs(*decisionsADRDir, adrs, *decisionsForce)
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, path := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipped %s: already exported\n", path)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Pass --force to rewrite existing records from the session.\n")
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amterp/council/internal/config"
+)
+
+// parseSince parses a --since value: either a date (YYYY-MM-DD, local time)
+// or an age relative to now (e.g. 7d)
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := config.ParseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since '%s'. Use a date (2006-01-02) or an age (e.g. 7d, 12h).", s)
+	}
+	return now.Add(-d), nil
+}
+
+// formatMillis formats an epoch-millis timestamp for tabular output
+func formatMillis(millis int64) string {
+	if millis == 0 {
+		return "-"
+	}
+	return time.UnixMilli(millis).Format("2006-01-02 15:04")
+}
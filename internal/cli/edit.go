@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	editCmd         *ra.Cmd
+	editSessionID   *string
+	editParticipant *string
+	editEvent       *int
+	editFile        *string
+)
+
+func setupEditCmd() *ra.Cmd {
+	editCmd = ra.NewCmd("edit")
+	editCmd.SetDescription("Replace the content of a message you posted")
+
+	editSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the message").
+		Register(editCmd)
+
+	editParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name editing (the author, or Moderator)").
+		Register(editCmd)
+
+	editEvent, _ = ra.NewInt("event").
+		SetShort("e").
+		SetFlagOnly(true).
+		SetUsage("Event number of the message to edit").
+		Register(editCmd)
+
+	editFile, _ = ra.NewString("file").
+		SetShort("f").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Read the new content from file instead of stdin").
+		Register(editCmd)
+
+	return editCmd
+}
+
+func handleEdit() {
+	content, err := readContent(*editFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	eventNum, err := session.EditMessage(*editSessionID, *editParticipant, *editEvent, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Edited #%d as event #%d.\n", *editEvent, eventNum)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	exportCmd       *ra.Cmd
+	exportSessionID *string
+	exportFormat    *string
+	exportOut       *string
+)
+
+func setupExportCmd() *ra.Cmd {
+	exportCmd = ra.NewCmd("export")
+	exportCmd.SetDescription("Export a session's transcript as Markdown, HTML, JSON or CSV")
+
+	exportSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to export").
+		Register(exportCmd)
+
+	exportFormat, _ = ra.NewString("format").
+		SetShort("f").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetDefault(session.ExportMarkdown).
+		SetEnumConstraint(session.ExportFormats).
+		SetUsage("Output format").
+		Register(exportCmd)
+
+	exportOut, _ = ra.NewString("out").
+		SetShort("o").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Write to this file instead of stdout").
+		Register(exportCmd)
+
+	return exportCmd
+}
+
+func handleExport() {
+	sess, err := session.LoadHistory(*exportSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := session.Export(&buf, sess, *exportFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *exportOut == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*exportOut, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d events to %s\n", sess.EventCount(), *exportOut)
+}
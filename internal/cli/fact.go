@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	factCmd *ra.Cmd
+
+	factSetCmd         *ra.Cmd
+	factSetUsed        *bool
+	factSetSessionID   *string
+	factSetKey         *string
+	factSetValue       *string
+	factSetParticipant *string
+	factSetVersion     *int
+
+	factGetCmd       *ra.Cmd
+	factGetUsed      *bool
+	factGetSessionID *string
+	factGetKey       *string
+
+	factListCmd       *ra.Cmd
+	factListUsed      *bool
+	factListSessionID *string
+
+	factDeleteCmd         *ra.Cmd
+	factDeleteUsed        *bool
+	factDeleteSessionID   *string
+	factDeleteKey         *string
+	factDeleteParticipant *string
+	factDeleteVersion     *int
+)
+
+func setupFactCmd() *ra.Cmd {
+	factCmd = ra.NewCmd("fact")
+	factCmd.SetDescription("Read and update the session's shared facts")
+
+	factSetUsed, _ = factCmd.RegisterCmd(setupFactSetCmd())
+	factGetUsed, _ = factCmd.RegisterCmd(setupFactGetCmd())
+	factListUsed, _ = factCmd.RegisterCmd(setupFactListCmd())
+	factDeleteUsed, _ = factCmd.RegisterCmd(setupFactDeleteCmd())
+
+	return factCmd
+}
+
+func setupFactSetCmd() *ra.Cmd {
+	factSetCmd = ra.NewCmd("set")
+	factSetCmd.SetDescription("Set a fact if it's still at the version you read")
+
+	factSetSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to set the fact in").
+		Register(factSetCmd)
+
+	factSetKey, _ = ra.NewString("key").
+		SetUsage("Fact key, e.g. db.max_connections").
+		Register(factSetCmd)
+
+	factSetValue, _ = ra.NewString("value").
+		SetUsage("Fact value, on one line").
+		Register(factSetCmd)
+
+	factSetParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name setting the fact").
+		Register(factSetCmd)
+
+	factSetVersion, _ = ra.NewInt("version").
+		SetFlagOnly(true).
+		SetUsage("Only set if the fact is at version N (0 if it isn't set)").
+		Register(factSetCmd)
+
+	return factSetCmd
+}
+
+func setupFactGetCmd() *ra.Cmd {
+	factGetCmd = ra.NewCmd("get")
+	factGetCmd.SetDescription("Show a fact with its version")
+
+	factGetSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the fact").
+		Register(factGetCmd)
+
+	factGetKey, _ = ra.NewString("key").
+		SetUsage("Fact key").
+		Register(factGetCmd)
+
+	return factGetCmd
+}
+
+func setupFactListCmd() *ra.Cmd {
+	factListCmd = ra.NewCmd("list")
+	factListCmd.SetDescription("List the session's facts with their versions")
+
+	factListSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to list facts of").
+		Register(factListCmd)
+
+	return factListCmd
+}
+
+func setupFactDeleteCmd() *ra.Cmd {
+	factDeleteCmd = ra.NewCmd("delete")
+	factDeleteCmd.SetDescription("Delete a fact if it's still at the version you read")
+
+	factDeleteSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the fact").
+		Register(factDeleteCmd)
+
+	factDeleteKey, _ = ra.NewString("key").
+		SetUsage("Fact key").
+		Register(factDeleteCmd)
+
+	factDeleteParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name deleting the fact").
+		Register(factDeleteCmd)
+
+	factDeleteVersion, _ = ra.NewInt("version").
+		SetFlagOnly(true).
+		SetUsage("Only delete if the fact is at version N").
+		Register(factDeleteCmd)
+
+	return factDeleteCmd
+}
+
+func handleFact() {
+	switch {
+	case *factSetUsed:
+		handleFactSet()
+	case *factGetUsed:
+		handleFactGet()
+	case *factListUsed:
+		handleFactList()
+	case *factDeleteUsed:
+		handleFactDelete()
+	default:
+		fmt.Fprintln(os.Stderr, "Error: choose what to do: 'council fact set', 'get', 'list' or 'delete'.")
+		os.Exit(1)
+	}
+}
+
+func handleFactSet() {
+	eventNum, version, err := session.SetFact(*factSetSessionID, *factSetParticipant, *factSetKey, *factSetValue, *factSetVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Set fact %s to version %d as event #%d. Change it again with --version %d.\n", *factSetKey, version, eventNum, version)
+}
+
+func handleFactGet() {
+	sess, err := session.LoadSession(*factGetSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fact, err := session.GetFact(sess, *factGetKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s = %s (v%d, %s at #%d)\n", fact.Key, fact.Value, fact.Version, fact.Participant, fact.EventNum)
+}
+
+func handleFactList() {
+	sess, err := session.LoadSession(*factListSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	facts := sess.SortedFacts()
+	if len(facts) == 0 {
+		fmt.Printf("No facts in session %s. Set one with 'council fact set'.\n", sess.ID)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tVERSION\tBY\tEVENT")
+	for _, f := range facts {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t#%d\n", f.Key, f.Value, f.Version, f.Participant, f.EventNum)
+	}
+	w.Flush()
+}
+
+func handleFactDelete() {
+	eventNum, err := session.DeleteFact(*factDeleteSessionID, *factDeleteParticipant, *factDeleteKey, *factDeleteVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted fact %s as event #%d.\n", *factDeleteKey, eventNum)
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	forkCmd           *ra.Cmd
+	forkSessionID     *string
+	forkAt            *int
+	forkWithHistory   *bool
+	forkReferenceOnly *bool
+)
+
+func setupForkCmd() *ra.Cmd {
+	forkCmd = ra.NewCmd("fork")
+	forkCmd.SetDescription("Branch a new session from an event in an existing one")
+
+	forkSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to fork").
+		Register(forkCmd)
+
+	forkAt, _ = ra.NewInt("at").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Event number to fork at (default: latest)").
+		Register(forkCmd)
+
+	forkWithHistory, _ = ra.NewBool("with-history").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetExcludes([]string{"reference-only"}).
+		SetUsage("Copy events up to the fork point into the new session (default)").
+		Register(forkCmd)
+
+	forkReferenceOnly, _ = ra.NewBool("reference-only").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetExcludes([]string{"with-history"}).
+		SetUsage("Start the new session empty, only referencing the fork point").
+		Register(forkCmd)
+
+	return forkCmd
+}
+
+func handleFork() {
+	at := 0
+	if forkCmd.Configured("at") {
+		at = *forkAt
+	} else {
+		parent, err := session.LoadSession(*forkSessionID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		at = parent.EventCount()
+	}
+
+	withHistory := forkReferenceOnly == nil || !*forkReferenceOnly
+
+	childID, err := createWithGeneratedID(func(childID string) error {
+		return session.ForkSession(*forkSessionID, childID, at, withHistory)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(childID)
+}
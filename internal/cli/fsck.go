@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	fsckCmd       *ra.Cmd
+	fsckSessionID *string
+	fsckAll       *bool
+	fsckRepair    *bool
+)
+
+func setupFsckCmd() *ra.Cmd {
+	fsckCmd = ra.NewCmd("fsck")
+	fsckCmd.SetDescription("Check session logs for corruption and rule violations")
+
+	fsckSessionID, _ = ra.NewString("session-id").
+		SetOptional(true).
+		SetUsage("Session ID to check").
+		Register(fsckCmd)
+
+	fsckAll, _ = ra.NewBool("all").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Check every session, including archived ones").
+		Register(fsckCmd)
+
+	fsckRepair, _ = ra.NewBool("repair").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Move a torn final write into a separate file, keeping the events before it").
+		Register(fsckCmd)
+
+	return fsckCmd
+}
+
+func handleFsck() {
+	all := fsckAll != nil && *fsckAll
+	if all == (*fsckSessionID != "") {
+		fmt.Fprintln(os.Stderr, "Error: pass either a session ID or --all.")
+		os.Exit(1)
+	}
+
+	ids := []string{*fsckSessionID}
+	if all {
+		var err error
+		ids, err = allSessionIDs()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if !fsckSession(id) {
+			failed++
+		}
+	}
+
+	if all {
+		fmt.Printf("\nChecked %d sessions, %d with problems.\n", len(ids), failed)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// allSessionIDs returns active and archived session IDs
+func allSessionIDs() ([]string, error) {
+	store := session.CurrentStore()
+	ids, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := store.ListArchived()
+	if err != nil {
+		return nil, err
+	}
+	return append(ids, archived...), nil
+}
+
+// fsckSession checks (and optionally repairs) one session, printing the
+// results. Returns false if problems remain.
+func fsckSession(id string) bool {
+	report, err := session.CheckSession(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+
+	if report.OK() {
+		fmt.Printf("%s: OK (%d events)\n", id, report.Events)
+		return true
+	}
+
+	fmt.Printf("%s: %s\n", id, countProblems(len(report.Problems)))
+	for _, p := range report.Problems {
+		fmt.Printf("  line %d: %s\n", p.Line, p.Message)
+	}
+
+	if !report.Repairable() {
+		return false
+	}
+	if fsckRepair == nil || !*fsckRepair {
+		if report.TornLine > 0 {
+			fmt.Printf("  Run 'council fsck %s --repair' to quarantine line %d onwards.\n", id, report.TornLine)
+		} else {
+			fmt.Printf("  Run 'council fsck %s --repair' to add the missing newline.\n", id)
+		}
+		return false
+	}
+
+	quarantined, err := session.RepairSession(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if quarantined != "" {
+		fmt.Printf("  Quarantined line %d onwards to %s\n", report.TornLine, quarantined)
+	} else {
+		fmt.Println("  Added the missing newline.")
+	}
+
+	report, err = session.CheckSession(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if !report.OK() {
+		fmt.Printf("  %s remaining.\n", countProblems(len(report.Problems)))
+		return false
+	}
+	fmt.Printf("  Repaired (%d events).\n", report.Events)
+	return true
+}
+
+func countProblems(n int) string {
+	if n == 1 {
+		return "1 problem"
+	}
+	return fmt.Sprintf("%d problems", n)
+}
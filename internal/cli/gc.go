@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/amterp/council/internal/config"
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	gcCmd       *ra.Cmd
+	gcOlderThan *string
+	gcDelete    *bool
+	gcDryRun    *bool
+)
+
+func setupGcCmd() *ra.Cmd {
+	gcCmd = ra.NewCmd("gc")
+	gcCmd.SetDescription("Archive or delete sessions by last-activity age")
+
+	gcOlderThan, _ = ra.NewString("older-than").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Act on sessions idle at least this long, e.g. 30d (default: configured retention policy)").
+		Register(gcCmd)
+
+	gcDelete, _ = ra.NewBool("delete").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Delete matching sessions instead of archiving them (with --older-than)").
+		Register(gcCmd)
+
+	gcDryRun, _ = ra.NewBool("dry-run").
+		SetShort("n").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Show what would be archived or deleted without doing it").
+		Register(gcCmd)
+
+	return gcCmd
+}
+
+func handleGc() {
+	policy, err := gcPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	active, err := session.ListSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	archived, err := session.ListArchivedSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, s := range append(active, archived...) {
+		if s.Err != nil && !s.NoKey {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", s.ID, s.Err)
+		}
+	}
+
+	plan := session.PlanGC(active, archived, policy, time.Now())
+	if len(plan.Archive) == 0 && len(plan.Delete) == 0 {
+		fmt.Println("Nothing to clean up.")
+		return
+	}
+
+	dryRun := gcDryRun != nil && *gcDryRun
+	verb := func(done, planned string) string {
+		if dryRun {
+			return planned
+		}
+		return done
+	}
+
+	hasErrors := false
+	for _, s := range plan.Archive {
+		if !dryRun {
+			if err := session.ArchiveSession(s.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", s.ID, err)
+				hasErrors = true
+				continue
+			}
+		}
+		fmt.Printf("%s %s (last activity %s)\n", verb("Archived", "Would archive"), s.ID, formatMillis(s.LastActivityMillis))
+	}
+	for _, s := range plan.Delete {
+		if !dryRun {
+			if err := session.DeleteSession(s.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting %s: %v\n", s.ID, err)
+				hasErrors = true
+				continue
+			}
+		}
+		fmt.Printf("%s %s (last activity %s)\n", verb("Deleted", "Would delete"), s.ID, formatMillis(s.LastActivityMillis))
+	}
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+// gcPolicy builds the retention policy from --older-than or the config file
+func gcPolicy() (session.RetentionPolicy, error) {
+	deleteMode := gcDelete != nil && *gcDelete
+
+	if *gcOlderThan != "" {
+		age, err := config.ParseAge(*gcOlderThan)
+		if err != nil {
+			return session.RetentionPolicy{}, err
+		}
+		if age == 0 {
+			return session.RetentionPolicy{}, fmt.Errorf("--older-than must be greater than zero")
+		}
+		if deleteMode {
+			return session.RetentionPolicy{DeleteAfter: age}, nil
+		}
+		return session.RetentionPolicy{ArchiveAfter: age}, nil
+	}
+
+	if deleteMode {
+		return session.RetentionPolicy{}, fmt.Errorf("--delete requires --older-than")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return session.RetentionPolicy{}, err
+	}
+	if !cfg.Retention.IsSet() {
+		return session.RetentionPolicy{}, fmt.Errorf("no retention policy configured. Pass --older-than or set \"retention\" in ~/.council/config.json.")
+	}
+
+	archiveAfter, deleteAfter, err := cfg.Retention.Durations()
+	if err != nil {
+		return session.RetentionPolicy{}, err
+	}
+	return session.RetentionPolicy{ArchiveAfter: archiveAfter, DeleteAfter: deleteAfter}, nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	importCmd       *ra.Cmd
+	importFile      *string
+	importFormat    *string
+	importID        *string
+	importTitle     *string
+	importGoal      *string
+	importTags      *[]string
+	importHashChain *bool
+	importEncrypt   *bool
+)
+
+func setupImportCmd() *ra.Cmd {
+	importCmd = ra.NewCmd("import")
+	importCmd.SetDescription("Create a session from a Markdown or JSON transcript")
+
+	importFile, _ = ra.NewString("file").
+		SetUsage("Transcript to import").
+		Register(importCmd)
+
+	importFormat, _ = ra.NewString("format").
+		SetShort("f").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetEnumConstraint(session.ImportFormats).
+		SetUsage("Transcript format (default: from the file extension)").
+		Register(importCmd)
+
+	importID, _ = ra.NewString("id").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Use this session ID instead of generating one").
+		Register(importCmd)
+
+	importTitle, _ = ra.NewString("title").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Short title for the session (default: the transcript's top heading)").
+		Register(importCmd)
+
+	importGoal, _ = ra.NewString("goal").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("What the session should achieve").
+		Register(importCmd)
+
+	importTags, _ = ra.NewStringSlice("tag").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Tag the session (repeatable)").
+		Register(importCmd)
+
+	importHashChain, _ = ra.NewBool("hash-chain").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Make the transcript tamper-evident (check with 'council verify')").
+		Register(importCmd)
+
+	importEncrypt, _ = ra.NewBool("encrypt").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Encrypt the transcript at rest with the key from COUNCIL_KEY or ~/.council/key").
+		Register(importCmd)
+
+	return importCmd
+}
+
+func handleImport() {
+	format := *importFormat
+	if format == "" {
+		format = importFormatFromPath(*importFile)
+		if format == "" {
+			fmt.Fprintf(os.Stderr, "Error: can't tell the format of '%s' from its extension. Use --format %s.\n",
+				*importFile, strings.Join(session.ImportFormats, "|"))
+			os.Exit(1)
+		}
+	}
+
+	data, err := os.ReadFile(*importFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	transcript, err := session.ParseTranscript(data, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", *importFile, err)
+		os.Exit(1)
+	}
+
+	title := *importTitle
+	if title == "" {
+		title = transcript.Title
+	}
+	opts := session.CreateOptions{
+		Metadata: session.Metadata{
+			Title: title,
+			Goal:  *importGoal,
+			Tags:  *importTags,
+		},
+		HashChain: importHashChain != nil && *importHashChain,
+		Encrypt:   importEncrypt != nil && *importEncrypt,
+	}
+
+	var events int
+	create := func(id string) error {
+		var err error
+		events, err = session.ImportSession(id, transcript.Messages, opts)
+		return err
+	}
+
+	var sessionID string
+	if importCmd.Configured("id") {
+		sessionID = *importID
+		err = session.ValidateSessionID(sessionID)
+		if err == nil {
+			err = create(sessionID)
+		}
+	} else {
+		sessionID, err = createWithGeneratedID(create)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(sessionID)
+	fmt.Fprintf(os.Stderr, "Imported %d messages from %d speakers (%d events).\n",
+		len(transcript.Messages), countSpeakers(transcript.Messages), events)
+}
+
+// importFormatFromPath guesses a transcript's format from its file extension
+func importFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return session.ImportMarkdown
+	case ".json":
+		return session.ImportJSON
+	}
+	return ""
+}
+
+func countSpeakers(messages []session.ImportedMessage) int {
+	speakers := map[string]bool{}
+	for _, m := range messages {
+		speakers[m.Speaker] = true
+	}
+	return len(speakers)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var keygenCmd *ra.Cmd
+
+func setupKeygenCmd() *ra.Cmd {
+	keygenCmd = ra.NewCmd("keygen")
+	keygenCmd.SetDescription("Create the encryption key for 'council new --encrypt'")
+	return keygenCmd
+}
+
+func handleKeygen() {
+	path, err := session.CreateKeyFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote a new encryption key to %s\n", path)
+	fmt.Println("Back it up: encrypted sessions can't be read without it.")
+}
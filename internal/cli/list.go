@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	listCmd         *ra.Cmd
+	listActive      *bool
+	listSince       *string
+	listParticipant *string
+	listSort        *string
+	listArchived    *bool
+	listTag         *string
+	listSearch      *string
+)
+
+func setupListCmd() *ra.Cmd {
+	listCmd = ra.NewCmd("list")
+	listCmd.SetDescription("List sessions")
+
+	listActive, _ = ra.NewBool("active").
+		SetShort("a").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show sessions that haven't closed").
+		Register(listCmd)
+
+	listSince, _ = ra.NewString("since").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show sessions active since a date (2006-01-02) or age (e.g. 7d)").
+		Register(listCmd)
+
+	listParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show sessions this participant has joined").
+		Register(listCmd)
+
+	listSort, _ = ra.NewString("sort").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetDefault(session.SortByActivity).
+		SetEnumConstraint([]string{session.SortByActivity, session.SortByCreated, session.SortByID, session.SortByEvents}).
+		SetUsage("Sort order").
+		Register(listCmd)
+
+	listArchived, _ = ra.NewBool("archived").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("List archived sessions instead").
+		Register(listCmd)
+
+	listTag, _ = ra.NewString("tag").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show sessions with this tag").
+		Register(listCmd)
+
+	listSearch, _ = ra.NewString("search").
+		SetShort("s").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show sessions whose title, goal or description contains this text").
+		Register(listCmd)
+
+	return listCmd
+}
+
+func handleList() {
+	filter := session.ListFilter{
+		ActiveOnly:  listActive != nil && *listActive,
+		Participant: *listParticipant,
+		Tag:         *listTag,
+		Search:      *listSearch,
+	}
+
+	if *listSince != "" {
+		since, err := parseSince(*listSince, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		filter.SinceMillis = since.UnixMilli()
+	}
+
+	listFn := session.ListSessions
+	if listArchived != nil && *listArchived {
+		listFn = session.ListArchivedSessions
+	}
+
+	summaries, err := listFn()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var matched []session.Summary
+	for _, s := range summaries {
+		if filter.Matches(s) {
+			matched = append(matched, s)
+		}
+	}
+	session.SortSummaries(matched, *listSort)
+
+	if len(matched) == 0 {
+		fmt.Println("No sessions found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tCREATED\tLAST ACTIVITY\tEVENTS\tSTATUS\tPARTICIPANTS")
+	for _, s := range matched {
+		if s.NoKey {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\tencrypted (no key)\t-\n", s.ID)
+			continue
+		}
+		if s.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't read %s: %v\n", s.ID, s.Err)
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\tunreadable\t-\n", s.ID)
+			continue
+		}
+		status := "open"
+		if s.Archived {
+			status = "archived"
+		} else if s.Closed {
+			status = "closed"
+		}
+		participants := "(none)"
+		if len(s.Participants) > 0 {
+			participants = strings.Join(s.Participants, ", ")
+		}
+		title := s.Metadata.Title
+		if title == "" {
+			title = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			s.ID, title, formatMillis(s.CreatedMillis), formatMillis(s.LastActivityMillis), s.EventCount, status, participants)
+	}
+	w.Flush()
+}
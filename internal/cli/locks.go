@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var locksCmd *ra.Cmd
+
+func setupLocksCmd() *ra.Cmd {
+	locksCmd = ra.NewCmd("locks")
+	locksCmd.SetDescription("Show which processes hold session locks")
+	return locksCmd
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func handleLocks() {
+	locks, ok, err := session.ListLocks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Printf("The %s store doesn't use session locks.\n", userConfig.Store)
+		return
+	}
+	if len(locks) == 0 {
+		fmt.Println("No session locks are held.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tHOST\tPID\tPARTICIPANT\tHELD FOR\tCOMMAND")
+	for _, lock := range locks {
+		id := lock.SessionID
+		if lock.Archived {
+			id += " (archived)"
+		}
+		if lock.Info == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t(unknown holder)\n", id)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			id, orDash(lock.Info.Host), strconv.Itoa(lock.Info.PID), orDash(lock.Info.Participant), lock.Info.HeldFor(), lock.Info.Command)
+	}
+	w.Flush()
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	metaCmd       *ra.Cmd
+	metaSessionID *string
+
+	metaSetCmd         *ra.Cmd
+	metaSetUsed        *bool
+	metaSetTitle       *string
+	metaSetGoal        *string
+	metaSetDescription *string
+	metaSetTags        *[]string
+	metaSetAddTags     *[]string
+	metaSetRemoveTags  *[]string
+)
+
+func setupMetaCmd() *ra.Cmd {
+	metaCmd = ra.NewCmd("meta")
+	metaCmd.SetDescription("Show or update a session's title, goal, description and tags")
+
+	metaSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID").
+		Register(metaCmd)
+
+	metaSetUsed, _ = metaCmd.RegisterCmd(setupMetaSetCmd())
+
+	return metaCmd
+}
+
+func setupMetaSetCmd() *ra.Cmd {
+	metaSetCmd = ra.NewCmd("set")
+	metaSetCmd.SetDescription("Update session metadata (unspecified fields are kept)")
+
+	metaSetTitle, _ = ra.NewString("title").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Short title for the session").
+		Register(metaSetCmd)
+
+	metaSetGoal, _ = ra.NewString("goal").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("What the session should achieve").
+		Register(metaSetCmd)
+
+	metaSetDescription, _ = ra.NewString("description").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Background participants should know").
+		Register(metaSetCmd)
+
+	metaSetTags, _ = ra.NewStringSlice("tag").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Replace all tags (repeatable)").
+		Register(metaSetCmd)
+
+	metaSetAddTags, _ = ra.NewStringSlice("add-tag").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Add a tag (repeatable)").
+		Register(metaSetCmd)
+
+	metaSetRemoveTags, _ = ra.NewStringSlice("remove-tag").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Remove a tag (repeatable)").
+		Register(metaSetCmd)
+
+	return metaSetCmd
+}
+
+func handleMeta() {
+	if *metaSetUsed {
+		handleMetaSet()
+		return
+	}
+
+	sess, err := session.LoadSession(*metaSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	meta := sess.Metadata
+	if meta.IsEmpty() {
+		fmt.Printf("No metadata set. Add some with 'council meta %s set --title ...'.\n", sess.ID)
+		return
+	}
+	fmt.Printf("Title: %s\n", orNone(meta.Title))
+	fmt.Printf("Goal: %s\n", orNone(meta.Goal))
+	fmt.Printf("Tags: %s\n", orNone(strings.Join(meta.Tags, ", ")))
+	fmt.Printf("Description: %s\n", orNone(meta.Description))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func handleMetaSet() {
+	update := session.MetadataUpdate{
+		AddTags:    *metaSetAddTags,
+		RemoveTags: *metaSetRemoveTags,
+	}
+	if metaSetCmd.Configured("title") {
+		update.Title = metaSetTitle
+	}
+	if metaSetCmd.Configured("goal") {
+		update.Goal = metaSetGoal
+	}
+	if metaSetCmd.Configured("description") {
+		update.Description = metaSetDescription
+	}
+	if metaSetCmd.Configured("tag") {
+		update.Tags = *metaSetTags
+	}
+
+	if update.Title == nil && update.Goal == nil && update.Description == nil &&
+		update.Tags == nil && len(update.AddTags) == 0 && len(update.RemoveTags) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: nothing to update. Pass at least one of --title, --goal, --description, --tag, --add-tag or --remove-tag.")
+		os.Exit(1)
+	}
+
+	eventNum, err := session.UpdateMetadata(*metaSessionID, update)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Updated metadata as event #%d.\n", eventNum)
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	migrateCmd       *ra.Cmd
+	migrateSessionID *string
+	migrateAll       *bool
+	migrateDryRun    *bool
+)
+
+func setupMigrateCmd() *ra.Cmd {
+	migrateCmd = ra.NewCmd("migrate")
+	migrateCmd.SetDescription("Rewrite session logs in the current event schema version")
+
+	migrateSessionID, _ = ra.NewString("session-id").
+		SetOptional(true).
+		SetUsage("Session ID to migrate").
+		Register(migrateCmd)
+
+	migrateAll, _ = ra.NewBool("all").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Migrate every session that isn't archived").
+		Register(migrateCmd)
+
+	migrateDryRun, _ = ra.NewBool("dry-run").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Show what would be migrated without changing anything").
+		Register(migrateCmd)
+
+	return migrateCmd
+}
+
+func handleMigrate() {
+	all := migrateAll != nil && *migrateAll
+	if all == (*migrateSessionID != "") {
+		fmt.Fprintln(os.Stderr, "Error: pass either a session ID or --all.")
+		os.Exit(1)
+	}
+	dryRun := migrateDryRun != nil && *migrateDryRun
+
+	ids := []string{*migrateSessionID}
+	if all {
+		// Archived sessions are read-only; readers upgrade their events anyway
+		var err error
+		ids, err = session.CurrentStore().List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		result, err := session.MigrateSession(id, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+			continue
+		}
+
+		switch {
+		case result.Migrated == 0:
+			fmt.Printf("%s: up to date (schema version %d)\n", id, session.SchemaVersion)
+		case dryRun:
+			fmt.Printf("%s: would migrate %d of %d events to schema version %d\n",
+				id, result.Migrated, result.Events, session.SchemaVersion)
+		default:
+			fmt.Printf("%s: migrated %d of %d events to schema version %d\n",
+				id, result.Migrated, result.Events, session.SchemaVersion)
+			if result.Backup != "" {
+				fmt.Printf("  Previous log saved to %s\n", result.Backup)
+			}
+		}
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
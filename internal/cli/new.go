@@ -7,17 +7,28 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/amterp/council/internal/errors"
 	"github.com/amterp/council/internal/session"
 	"github.com/amterp/ra"
 	petname "github.com/dustinkirkland/golang-petname"
 )
 
 var (
-	newCmd   *ra.Cmd
-	newCopy  *bool
-	newWatch *bool
+	newCmd         *ra.Cmd
+	newCopy        *bool
+	newWatch       *bool
+	newTitle       *string
+	newGoal        *string
+	newDescription *string
+	newTags        *[]string
+	newID          *string
+	newHashChain   *bool
+	newEncrypt     *bool
 )
 
+// maxIDAttempts bounds retries when a generated session ID is already taken
+const maxIDAttempts = 10
+
 func setupNewCmd() *ra.Cmd {
 	newCmd = ra.NewCmd("new")
 	newCmd.SetDescription("Create a new collaboration session")
@@ -36,15 +47,78 @@ func setupNewCmd() *ra.Cmd {
 		SetUsage("Open web interface after creating session").
 		Register(newCmd)
 
+	newID, _ = ra.NewString("id").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Use this session ID instead of generating one").
+		Register(newCmd)
+
+	newHashChain, _ = ra.NewBool("hash-chain").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Make the transcript tamper-evident (check with 'council verify')").
+		Register(newCmd)
+
+	newEncrypt, _ = ra.NewBool("encrypt").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Encrypt the transcript at rest with the key from COUNCIL_KEY or ~/.council/key").
+		Register(newCmd)
+
+	newTitle, _ = ra.NewString("title").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Short title for the session").
+		Register(newCmd)
+
+	newGoal, _ = ra.NewString("goal").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("What the session should achieve").
+		Register(newCmd)
+
+	newDescription, _ = ra.NewString("description").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Background participants should know").
+		Register(newCmd)
+
+	newTags, _ = ra.NewStringSlice("tag").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Tag the session (repeatable)").
+		Register(newCmd)
+
 	return newCmd
 }
 
 func handleNew() {
-	// Generate session ID (3 words, hyphen-separated)
-	sessionID := petname.Generate(3, "-")
+	opts := session.CreateOptions{
+		Metadata: session.Metadata{
+			Title:       *newTitle,
+			Goal:        *newGoal,
+			Description: *newDescription,
+			Tags:        *newTags,
+		},
+		HashChain: newHashChain != nil && *newHashChain,
+		Encrypt:   newEncrypt != nil && *newEncrypt,
+	}
+	create := func(id string) error {
+		return session.CreateSessionWithOptions(id, opts)
+	}
 
-	// Create session file with session_created event
-	err := session.CreateSession(sessionID)
+	var sessionID string
+	var err error
+	if newCmd.Configured("id") {
+		sessionID = *newID
+		err = session.ValidateSessionID(sessionID)
+		if err == nil {
+			err = create(sessionID)
+		}
+	} else {
+		sessionID, err = createWithGeneratedID(create)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -66,6 +140,20 @@ func handleNew() {
 	}
 }
 
+// createWithGeneratedID creates a session under a generated petname ID,
+// generating a new one if the ID is already taken
+func createWithGeneratedID(create func(sessionID string) error) (string, error) {
+	var err error
+	for i := 0; i < maxIDAttempts; i++ {
+		sessionID := petname.Generate(userConfig.SessionIDWords, "-")
+		err = create(sessionID)
+		if _, taken := err.(*errors.SessionExistsError); !taken {
+			return sessionID, err
+		}
+	}
+	return "", err
+}
+
 func copyToClipboard(text string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
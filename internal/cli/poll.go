@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	pollCmd         *ra.Cmd
+	pollSessionID   *string
+	pollParticipant *string
+	pollQuestion    *string
+	pollOptions     *[]string
+	pollSecret      *bool
+	pollClosesAfter *int
+)
+
+func setupPollCmd() *ra.Cmd {
+	pollCmd = ra.NewCmd("poll")
+	pollCmd.SetDescription("Open a poll that participants vote in with 'council vote'")
+
+	pollSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to open the poll in").
+		Register(pollCmd)
+
+	pollParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name opening the poll").
+		Register(pollCmd)
+
+	pollQuestion, _ = ra.NewString("question").
+		SetShort("q").
+		SetFlagOnly(true).
+		SetUsage("What to vote on").
+		Register(pollCmd)
+
+	pollOptions, _ = ra.NewStringSlice("option").
+		SetShort("o").
+		SetFlagOnly(true).
+		SetUsage("A choice to vote for (repeat for each option)").
+		Register(pollCmd)
+
+	pollSecret, _ = ra.NewBool("secret").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Show only vote counts, not who voted for what").
+		Register(pollCmd)
+
+	pollClosesAfter, _ = ra.NewInt("closes-after").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Stop accepting votes after event number N").
+		Register(pollCmd)
+
+	return pollCmd
+}
+
+func handlePoll() {
+	spec := session.PollSpec{
+		Question: *pollQuestion,
+		Options:  *pollOptions,
+		Secret:   pollSecret != nil && *pollSecret,
+	}
+	if pollCmd.Configured("closes-after") {
+		spec.ClosesAfter = *pollClosesAfter
+	}
+
+	eventNum, err := session.OpenPoll(*pollSessionID, *pollParticipant, spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Opened poll as event #%d. Vote with 'council vote %s --poll %d --choice <option>'.\n",
+		eventNum, *pollSessionID, eventNum)
+}
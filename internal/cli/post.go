@@ -16,6 +16,7 @@ var (
 	postAfter       *int
 	postFile        *string
 	postNext        *string
+	postReplyTo     *int
 )
 
 func setupPostCmd() *ra.Cmd {
@@ -51,6 +52,13 @@ func setupPostCmd() *ra.Cmd {
 		SetUsage("Designate the next speaker (defaults to previous speaker)").
 		Register(postCmd)
 
+	postReplyTo, _ = ra.NewInt("reply-to").
+		SetShort("r").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Event number of the message this replies to").
+		Register(postCmd)
+
 	return postCmd
 }
 
@@ -67,7 +75,12 @@ func handlePost() {
 		next = *postNext
 	}
 
-	eventNum, err := session.PostMessage(*postSessionID, *postParticipant, content, next, *postAfter)
+	replyTo := 0
+	if postCmd.Configured("reply-to") {
+		replyTo = *postReplyTo
+	}
+
+	eventNum, err := session.PostReply(*postSessionID, *postParticipant, content, next, replyTo, *postAfter)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	retractCmd         *ra.Cmd
+	retractSessionID   *string
+	retractParticipant *string
+	retractEvent       *int
+)
+
+func setupRetractCmd() *ra.Cmd {
+	retractCmd = ra.NewCmd("retract")
+	retractCmd.SetDescription("Withdraw a message you posted, leaving a tombstone")
+
+	retractSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the message").
+		Register(retractCmd)
+
+	retractParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name retracting (the author, or Moderator)").
+		Register(retractCmd)
+
+	retractEvent, _ = ra.NewInt("event").
+		SetShort("e").
+		SetFlagOnly(true).
+		SetUsage("Event number of the message to retract").
+		Register(retractCmd)
+
+	return retractCmd
+}
+
+func handleRetract() {
+	eventNum, err := session.RetractMessage(*retractSessionID, *retractParticipant, *retractEvent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Retracted #%d as event #%d.\n", *retractEvent, eventNum)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	rmCmd       *ra.Cmd
+	rmSessionID *string
+	rmYes       *bool
+)
+
+func setupRmCmd() *ra.Cmd {
+	rmCmd = ra.NewCmd("rm")
+	rmCmd.SetDescription("Permanently delete a session")
+
+	rmSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to delete").
+		Register(rmCmd)
+
+	rmYes, _ = ra.NewBool("yes").
+		SetShort("y").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Skip the confirmation prompt").
+		Register(rmCmd)
+
+	return rmCmd
+}
+
+func handleRm() {
+	archived, err := session.SessionArchived(*rmSessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if rmYes == nil || !*rmYes {
+		kind := "session"
+		if archived {
+			kind = "archived session"
+		}
+		// The event count is only for the prompt: damaged sessions and ones
+		// whose key is missing can be deleted too
+		size := ""
+		if sess, err := session.LoadSession(*rmSessionID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't read session '%s': %v\n", *rmSessionID, err)
+		} else {
+			size = fmt.Sprintf(" with %d events", sess.EventCount())
+		}
+		prompt := fmt.Sprintf("Delete %s '%s'%s? This cannot be undone. [y/N]: ", kind, *rmSessionID, size)
+		answer := strings.ToLower(promptForName(prompt))
+		if answer != "y" && answer != "yes" {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			os.Exit(1)
+		}
+	}
+
+	if err := session.DeleteSession(*rmSessionID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Deleted session %s.\n", *rmSessionID)
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/amterp/council/internal/config"
+	"github.com/amterp/council/internal/session"
 	"github.com/amterp/ra"
 )
 
@@ -15,14 +17,40 @@ var skillMd string
 var (
 	rootCmd *ra.Cmd
 
+	// userConfig is loaded from ~/.council/config.json before dispatch
+	userConfig config.Config
+
 	// Subcommand used flags
-	newUsed     *bool
-	joinUsed    *bool
-	leaveUsed   *bool
-	statusUsed  *bool
-	postUsed    *bool
-	installUsed *bool
-	watchUsed   *bool
+	newUsed       *bool
+	joinUsed      *bool
+	leaveUsed     *bool
+	statusUsed    *bool
+	postUsed      *bool
+	installUsed   *bool
+	watchUsed     *bool
+	listUsed      *bool
+	archiveUsed   *bool
+	rmUsed        *bool
+	gcUsed        *bool
+	forkUsed      *bool
+	metaUsed      *bool
+	fsckUsed      *bool
+	verifyUsed    *bool
+	migrateUsed   *bool
+	locksUsed     *bool
+	keygenUsed    *bool
+	exportUsed    *bool
+	importUsed    *bool
+	pollUsed      *bool
+	voteUsed      *bool
+	decideUsed    *bool
+	decisionsUsed *bool
+	editUsed      *bool
+	retractUsed   *bool
+	artifactUsed  *bool
+	factUsed      *bool
+	taskUsed      *bool
+	tasksUsed     *bool
 )
 
 // Run is the main entry point for the CLI
@@ -39,9 +67,37 @@ func Run() {
 	postUsed, _ = rootCmd.RegisterCmd(setupPostCmd())
 	installUsed, _ = rootCmd.RegisterCmd(setupInstallCmd())
 	watchUsed, _ = rootCmd.RegisterCmd(setupWatchCmd())
+	listUsed, _ = rootCmd.RegisterCmd(setupListCmd())
+	archiveUsed, _ = rootCmd.RegisterCmd(setupArchiveCmd())
+	rmUsed, _ = rootCmd.RegisterCmd(setupRmCmd())
+	gcUsed, _ = rootCmd.RegisterCmd(setupGcCmd())
+	forkUsed, _ = rootCmd.RegisterCmd(setupForkCmd())
+	metaUsed, _ = rootCmd.RegisterCmd(setupMetaCmd())
+	fsckUsed, _ = rootCmd.RegisterCmd(setupFsckCmd())
+	verifyUsed, _ = rootCmd.RegisterCmd(setupVerifyCmd())
+	migrateUsed, _ = rootCmd.RegisterCmd(setupMigrateCmd())
+	locksUsed, _ = rootCmd.RegisterCmd(setupLocksCmd())
+	keygenUsed, _ = rootCmd.RegisterCmd(setupKeygenCmd())
+	exportUsed, _ = rootCmd.RegisterCmd(setupExportCmd())
+	importUsed, _ = rootCmd.RegisterCmd(setupImportCmd())
+	pollUsed, _ = rootCmd.RegisterCmd(setupPollCmd())
+	voteUsed, _ = rootCmd.RegisterCmd(setupVoteCmd())
+	decideUsed, _ = rootCmd.RegisterCmd(setupDecideCmd())
+	decisionsUsed, _ = rootCmd.RegisterCmd(setupDecisionsCmd())
+	editUsed, _ = rootCmd.RegisterCmd(setupEditCmd())
+	retractUsed, _ = rootCmd.RegisterCmd(setupRetractCmd())
+	artifactUsed, _ = rootCmd.RegisterCmd(setupArtifactCmd())
+	factUsed, _ = rootCmd.RegisterCmd(setupFactCmd())
+	taskUsed, _ = rootCmd.RegisterCmd(setupTaskCmd())
+	tasksUsed, _ = rootCmd.RegisterCmd(setupTasksCmd())
 
 	rootCmd.ParseOrExit(os.Args[1:])
 
+	if err := configureStore(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Dispatch to the appropriate handler
 	switch {
 	case *newUsed:
@@ -58,7 +114,100 @@ func Run() {
 		handleInstall()
 	case *watchUsed:
 		handleWatch()
+	case *listUsed:
+		handleList()
+	case *archiveUsed:
+		handleArchive()
+	case *rmUsed:
+		handleRm()
+	case *gcUsed:
+		handleGc()
+	case *forkUsed:
+		handleFork()
+	case *metaUsed:
+		handleMeta()
+	case *fsckUsed:
+		handleFsck()
+	case *verifyUsed:
+		handleVerify()
+	case *migrateUsed:
+		handleMigrate()
+	case *locksUsed:
+		handleLocks()
+	case *keygenUsed:
+		handleKeygen()
+	case *exportUsed:
+		handleExport()
+	case *importUsed:
+		handleImport()
+	case *pollUsed:
+		handlePoll()
+	case *voteUsed:
+		handleVote()
+	case *decideUsed:
+		handleDecide()
+	case *decisionsUsed:
+		handleDecisions()
+	case *editUsed:
+		handleEdit()
+	case *retractUsed:
+		handleRetract()
+	case *artifactUsed:
+		handleArtifact()
+	case *factUsed:
+		handleFact()
+	case *taskUsed:
+		handleTask()
+	case *tasksUsed:
+		handleTasks()
+	}
+}
+
+// configureStore loads the user config and selects the session storage backend
+func configureStore() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	userConfig = cfg
+	session.SetKeyFile(cfg.KeyFile)
+	session.SetLockCommand(commandName())
+
+	store, err := session.OpenStore(cfg)
+	if err != nil {
+		return err
+	}
+	session.SetStore(store)
+	return nil
+}
+
+// commandName returns the invoked command without its arguments, e.g.
+// "council post" or "council fact set"
+func commandName() string {
+	for name, used := range map[string]*bool{
+		"meta set":      metaSetUsed,
+		"fact set":      factSetUsed,
+		"fact get":      factGetUsed,
+		"fact list":     factListUsed,
+		"fact delete":   factDeleteUsed,
+		"artifact put":  artifactPutUsed,
+		"artifact get":  artifactGetUsed,
+		"artifact list": artifactListUsed,
+		"task add":      taskAddUsed,
+		"task assign":   taskAssignUsed,
+		"task done":     taskDoneUsed,
+	} {
+		if *used {
+			return "council " + name
+		}
+	}
+	// The root command takes no arguments, so the first one names the command
+	for _, arg := range os.Args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return "council " + arg
+		}
 	}
+	return "council"
 }
 
 func printUsage(isLongHelp bool) {
@@ -16,6 +16,8 @@ var (
 	statusAwait       *bool
 	statusParticipant *string
 	statusTimeout     *int
+	statusThread      *int
+	statusWakeMention *bool
 )
 
 func setupStatusCmd() *ra.Cmd {
@@ -51,6 +53,18 @@ func setupStatusCmd() *ra.Cmd {
 		SetUsage("Timeout in seconds for --await (default: 300)").
 		Register(statusCmd)
 
+	statusWakeMention, _ = ra.NewBool("wake-on-mention").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("With --await, also return when someone @mentions you, even if it's not your turn").
+		Register(statusCmd)
+
+	statusThread, _ = ra.NewInt("thread").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only show the message at event N and its replies").
+		Register(statusCmd)
+
 	return statusCmd
 }
 
@@ -62,17 +76,30 @@ func handleStatus() {
 
 	// Check if await mode
 	awaitMode := statusAwait != nil && *statusAwait
+	wakeOnMention := statusWakeMention != nil && *statusWakeMention
+	if wakeOnMention && !awaitMode {
+		fmt.Fprintf(os.Stderr, "Error: --wake-on-mention requires --await\n")
+		os.Exit(1)
+	}
+	if statusCmd.Configured("thread") {
+		if awaitMode || statusCmd.Configured("after") {
+			fmt.Fprintf(os.Stderr, "Error: --thread can't be combined with --after or --await\n")
+			os.Exit(1)
+		}
+		handleThread(*statusSessionID, *statusThread)
+		return
+	}
 	if awaitMode {
 		if statusParticipant == nil || *statusParticipant == "" {
 			fmt.Fprintf(os.Stderr, "Error: --await requires --participant\n")
 			os.Exit(1)
 		}
-		handleAwait(*statusSessionID, *statusParticipant, afterN)
+		handleAwait(*statusSessionID, *statusParticipant, afterN, wakeOnMention)
 		return
 	}
 
 	// Normal status mode
-	sess, err := session.LoadSession(*statusSessionID)
+	sess, err := session.LoadSessionAfter(*statusSessionID, afterN)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -82,7 +109,22 @@ func handleStatus() {
 	fmt.Print(output)
 }
 
-func handleAwait(sessionID, participant string, afterN int) {
+func handleThread(sessionID string, root int) {
+	sess, err := session.LoadHistory(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	output, err := session.FormatThread(sess, root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(output)
+}
+
+func handleAwait(sessionID, participant string, afterN int, wakeOnMention bool) {
 	timeout := 300 // default 5 minutes
 	if statusTimeout != nil && *statusTimeout > 0 {
 		timeout = *statusTimeout
@@ -99,7 +141,7 @@ func handleAwait(sessionID, participant string, afterN int) {
 			os.Exit(1)
 		}
 
-		sess, err := session.LoadSession(sessionID)
+		sess, err := session.LoadSessionAfter(sessionID, afterN)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -127,6 +169,16 @@ func handleAwait(sessionID, participant string, afterN int) {
 				return
 			}
 
+			// Not our turn, but someone asked us something directly
+			if mentions := sess.Mentions(participant, currentAfter); wakeOnMention && len(mentions) > 0 {
+				latest := mentions[len(mentions)-1]
+				output := session.FormatStatus(sess, afterN)
+				fmt.Print(output)
+				fmt.Printf("You were mentioned in #%d, but it's %s's turn. To answer without taking the turn, post with --reply-to %d --next %s.\n",
+					latest, nextSpeaker, latest, nextSpeaker)
+				return
+			}
+
 			// Not our turn, update currentAfter and keep waiting
 			currentAfter = sess.EventCount()
 		}
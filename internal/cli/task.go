@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	taskCmd *ra.Cmd
+
+	taskAddCmd         *ra.Cmd
+	taskAddUsed        *bool
+	taskAddSessionID   *string
+	taskAddDescription *string
+	taskAddParticipant *string
+	taskAddOwner       *string
+
+	taskAssignCmd         *ra.Cmd
+	taskAssignUsed        *bool
+	taskAssignSessionID   *string
+	taskAssignParticipant *string
+	taskAssignTask        *int
+	taskAssignOwner       *string
+
+	taskDoneCmd         *ra.Cmd
+	taskDoneUsed        *bool
+	taskDoneSessionID   *string
+	taskDoneParticipant *string
+	taskDoneTask        *int
+)
+
+func setupTaskCmd() *ra.Cmd {
+	taskCmd = ra.NewCmd("task")
+	taskCmd.SetDescription("Track action items that outlive the session")
+
+	taskAddUsed, _ = taskCmd.RegisterCmd(setupTaskAddCmd())
+	taskAssignUsed, _ = taskCmd.RegisterCmd(setupTaskAssignCmd())
+	taskDoneUsed, _ = taskCmd.RegisterCmd(setupTaskDoneCmd())
+
+	return taskCmd
+}
+
+func setupTaskAddCmd() *ra.Cmd {
+	taskAddCmd = ra.NewCmd("add")
+	taskAddCmd.SetDescription("Add an action item")
+
+	taskAddSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to add the task to").
+		Register(taskAddCmd)
+
+	taskAddDescription, _ = ra.NewString("description").
+		SetUsage("What needs doing, on one line").
+		Register(taskAddCmd)
+
+	taskAddParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name adding the task").
+		Register(taskAddCmd)
+
+	taskAddOwner, _ = ra.NewString("owner").
+		SetShort("o").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Who will do it; a person or team, not necessarily a participant").
+		Register(taskAddCmd)
+
+	return taskAddCmd
+}
+
+func setupTaskAssignCmd() *ra.Cmd {
+	taskAssignCmd = ra.NewCmd("assign")
+	taskAssignCmd.SetDescription("Hand an open task to a new owner")
+
+	taskAssignSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the task").
+		Register(taskAssignCmd)
+
+	taskAssignParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name assigning the task").
+		Register(taskAssignCmd)
+
+	taskAssignTask, _ = ra.NewInt("task").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetUsage("Event number of the task").
+		Register(taskAssignCmd)
+
+	taskAssignOwner, _ = ra.NewString("owner").
+		SetShort("o").
+		SetFlagOnly(true).
+		SetUsage("New owner").
+		Register(taskAssignCmd)
+
+	return taskAssignCmd
+}
+
+func setupTaskDoneCmd() *ra.Cmd {
+	taskDoneCmd = ra.NewCmd("done")
+	taskDoneCmd.SetDescription("Mark an open task as done")
+
+	taskDoneSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the task").
+		Register(taskDoneCmd)
+
+	taskDoneParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name marking the task done").
+		Register(taskDoneCmd)
+
+	taskDoneTask, _ = ra.NewInt("task").
+		SetShort("t").
+		SetFlagOnly(true).
+		SetUsage("Event number of the task").
+		Register(taskDoneCmd)
+
+	return taskDoneCmd
+}
+
+func handleTask() {
+	switch {
+	case *taskAddUsed:
+		handleTaskAdd()
+	case *taskAssignUsed:
+		handleTaskAssign()
+	case *taskDoneUsed:
+		handleTaskDone()
+	default:
+		fmt.Fprintln(os.Stderr, "Error: choose what to do: 'council task add', 'assign' or 'done'.")
+		os.Exit(1)
+	}
+}
+
+func handleTaskAdd() {
+	eventNum, err := session.AddTask(*taskAddSessionID, *taskAddParticipant, *taskAddDescription, *taskAddOwner)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added task #%d.\n", eventNum)
+}
+
+func handleTaskAssign() {
+	eventNum, err := session.AssignTask(*taskAssignSessionID, *taskAssignParticipant, *taskAssignTask, *taskAssignOwner)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Assigned task #%d to %s as event #%d.\n", *taskAssignTask, *taskAssignOwner, eventNum)
+}
+
+func handleTaskDone() {
+	eventNum, err := session.CompleteTask(*taskDoneSessionID, *taskDoneParticipant, *taskDoneTask)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Marked task #%d done as event #%d.\n", *taskDoneTask, eventNum)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	tasksCmd       *ra.Cmd
+	tasksSessionID *string
+	tasksAll       *bool
+	tasksOpen      *bool
+)
+
+func setupTasksCmd() *ra.Cmd {
+	tasksCmd = ra.NewCmd("tasks")
+	tasksCmd.SetDescription("List the tasks of a session, or of every session")
+
+	tasksSessionID, _ = ra.NewString("session-id").
+		SetOptional(true).
+		SetUsage("Session ID to list tasks of").
+		Register(tasksCmd)
+
+	tasksAll, _ = ra.NewBool("all").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("List tasks of every session, including archived ones").
+		Register(tasksCmd)
+
+	tasksOpen, _ = ra.NewBool("open").
+		SetFlagOnly(true).
+		SetOptional(true).
+		SetUsage("Only list tasks not yet done").
+		Register(tasksCmd)
+
+	return tasksCmd
+}
+
+func handleTasks() {
+	all := tasksAll != nil && *tasksAll
+	if all == (*tasksSessionID != "") {
+		fmt.Fprintln(os.Stderr, "Error: pass either a session ID or --all.")
+		os.Exit(1)
+	}
+	openOnly := tasksOpen != nil && *tasksOpen
+
+	if !all {
+		sess, err := session.LoadSession(*tasksSessionID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(session.FormatTasks(sess, openOnly))
+		return
+	}
+
+	ids, err := allSessionIDs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Sessions without matching tasks are left out
+	listed := 0
+	for _, id := range ids {
+		sess, err := session.LoadSession(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", id, err)
+			continue
+		}
+		if len(sess.Tasks) == 0 || (openOnly && len(sess.OpenTasks()) == 0) {
+			continue
+		}
+		if listed > 0 {
+			fmt.Println()
+		}
+		fmt.Print(session.FormatTasks(sess, openOnly))
+		listed++
+	}
+
+	if listed == 0 {
+		if openOnly {
+			fmt.Println("No open tasks in any session.")
+		} else {
+			fmt.Println("No tasks in any session. Add one with 'council task add'.")
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	verifyCmd       *ra.Cmd
+	verifySessionID *string
+)
+
+func setupVerifyCmd() *ra.Cmd {
+	verifyCmd = ra.NewCmd("verify")
+	verifyCmd.SetDescription("Check a hash-chained session for edits to its transcript")
+
+	verifySessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID to verify").
+		Register(verifyCmd)
+
+	return verifyCmd
+}
+
+func handleVerify() {
+	report, err := session.VerifyChain(*verifySessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !report.Enabled && report.BrokenLine == 0 {
+		fmt.Fprintf(os.Stderr, "Error: session '%s' has no hash chain. Create sessions with 'council new --hash-chain' to enable one.\n", report.SessionID)
+		os.Exit(1)
+	}
+
+	if !report.Intact() {
+		fmt.Printf("Chain broken at line %d: %s\n", report.BrokenLine, report.Reason)
+		fmt.Printf("Line %d or the line before it was changed, or lines were inserted or removed between them.\n",
+			report.BrokenLine)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Chain intact: %d events.\n", report.Events)
+	fmt.Printf("Head: %s\n", report.Head)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/amterp/council/internal/session"
+	"github.com/amterp/ra"
+)
+
+var (
+	voteCmd         *ra.Cmd
+	voteSessionID   *string
+	voteParticipant *string
+	votePoll        *int
+	voteChoice      *string
+)
+
+func setupVoteCmd() *ra.Cmd {
+	voteCmd = ra.NewCmd("vote")
+	voteCmd.SetDescription("Vote in a poll, replacing any earlier vote")
+
+	voteSessionID, _ = ra.NewString("session-id").
+		SetUsage("Session ID of the poll").
+		Register(voteCmd)
+
+	voteParticipant, _ = ra.NewString("participant").
+		SetShort("p").
+		SetFlagOnly(true).
+		SetUsage("Participant name voting").
+		Register(voteCmd)
+
+	votePoll, _ = ra.NewInt("poll").
+		SetFlagOnly(true).
+		SetUsage("Event number of the poll").
+		Register(voteCmd)
+
+	voteChoice, _ = ra.NewString("choice").
+		SetShort("c").
+		SetFlagOnly(true).
+		SetUsage("The option to vote for").
+		Register(voteCmd)
+
+	return voteCmd
+}
+
+func handleVote() {
+	eventNum, err := session.Vote(*voteSessionID, *voteParticipant, *votePoll, *voteChoice)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Voted as event #%d.\n", eventNum)
+}
@@ -6,7 +6,7 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/amterp/council/internal/storage"
+	"github.com/amterp/council/internal/session"
 	"github.com/amterp/council/internal/web"
 	"github.com/amterp/ra"
 )
@@ -91,7 +91,7 @@ func handleWatch() {
 	}
 
 	// Validate session exists
-	exists, err := storage.SessionExists(*watchSessionID)
+	exists, err := session.CurrentStore().Exists(*watchSessionID)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
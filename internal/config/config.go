@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amterp/council/internal/storage"
+)
+
+// Storage backends selectable via the "store" setting
+const (
+	StoreFile   = "file"
+	StoreSQLite = "sqlite"
+)
+
+// Session locking strategies selectable via the "lock_strategy" setting
+const (
+	LockFlock    = "flock"
+	LockLockfile = "lockfile"
+)
+
+// Bounds and default for the number of words in generated session IDs
+const (
+	DefaultSessionIDWords = 3
+	MinSessionIDWords     = 1
+	MaxSessionIDWords     = 10
+)
+
+// DefaultLockTimeout is the lock timeout used when none is configured
+const DefaultLockTimeout = 10 * time.Second
+
+// DefaultLockLease is the lockfile lease used when none is configured
+const DefaultLockLease = 30 * time.Second
+
+// Config holds user settings read from ~/.council/config.json
+type Config struct {
+	// Store selects the session storage backend ("file" or "sqlite")
+	Store string `json:"store"`
+
+	// Retention is the policy applied by 'council gc' when no flags are given
+	Retention Retention `json:"retention"`
+
+	// SessionIDWords is the number of words in generated session IDs
+	SessionIDWords int `json:"session_id_words"`
+
+	// Checksums adds a CRC-32 to each event written, verified on read
+	Checksums bool `json:"checksums"`
+
+	// LockTimeout is how long to wait for a session lock held by another
+	// process, in ParseAge syntax (e.g. "10s")
+	LockTimeout string `json:"lock_timeout"`
+
+	// LockStrategy selects how the file store locks sessions ("flock" or
+	// "lockfile"). Lockfiles work on network filesystems such as NFS.
+	LockStrategy string `json:"lock_strategy"`
+
+	// LockLease is how long a lockfile stays valid without a heartbeat
+	// before other processes may break it, in ParseAge syntax (e.g. "30s").
+	// Only used by the "lockfile" strategy.
+	LockLease string `json:"lock_lease"`
+
+	// KeyFile is the path of the key for encrypted sessions, used when
+	// COUNCIL_KEY isn't set. Defaults to ~/.council/key.
+	KeyFile string `json:"key_file"`
+}
+
+// LockTimeoutDuration returns the parsed lock timeout, or
+// DefaultLockTimeout if it's unset or invalid
+func (c Config) LockTimeoutDuration() time.Duration {
+	d, err := ParseAge(c.LockTimeout)
+	if err != nil || d <= 0 {
+		return DefaultLockTimeout
+	}
+	return d
+}
+
+// LockLeaseDuration returns the parsed lockfile lease, or
+// DefaultLockLease if it's unset or invalid
+func (c Config) LockLeaseDuration() time.Duration {
+	d, err := ParseAge(c.LockLease)
+	if err != nil || d <= 0 {
+		return DefaultLockLease
+	}
+	return d
+}
+
+// Retention configures automatic archival and deletion by last-activity age.
+// Ages use ParseAge syntax (e.g. "30d"); empty disables that step.
+type Retention struct {
+	ArchiveAfter string `json:"archive_after"`
+	DeleteAfter  string `json:"delete_after"`
+}
+
+// IsSet checks if any retention step is configured
+func (r Retention) IsSet() bool {
+	return r.ArchiveAfter != "" || r.DeleteAfter != ""
+}
+
+// Durations returns the parsed archive and delete ages (0 when disabled)
+func (r Retention) Durations() (archiveAfter, deleteAfter time.Duration, err error) {
+	if r.ArchiveAfter != "" {
+		if archiveAfter, err = ParseAge(r.ArchiveAfter); err != nil {
+			return 0, 0, err
+		}
+	}
+	if r.DeleteAfter != "" {
+		if deleteAfter, err = ParseAge(r.DeleteAfter); err != nil {
+			return 0, 0, err
+		}
+	}
+	return archiveAfter, deleteAfter, nil
+}
+
+// Default returns the configuration used when no config file exists
+func Default() Config {
+	return Config{
+		Store:          StoreFile,
+		SessionIDWords: DefaultSessionIDWords,
+		LockStrategy:   LockFlock,
+	}
+}
+
+// Load reads the config file, falling back to defaults for missing values
+func Load() (Config, error) {
+	path, err := storage.ConfigPath()
+	if err != nil {
+		return Config{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes config JSON and applies defaults for missing values
+func Parse(data []byte) (Config, error) {
+	cfg := Default()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	if cfg.Store == "" {
+		cfg.Store = StoreFile
+	}
+
+	switch cfg.Store {
+	case StoreFile, StoreSQLite:
+	default:
+		return Config{}, fmt.Errorf("invalid config file: unknown store %q (expected %q or %q)", cfg.Store, StoreFile, StoreSQLite)
+	}
+
+	if cfg.SessionIDWords < MinSessionIDWords || cfg.SessionIDWords > MaxSessionIDWords {
+		return Config{}, fmt.Errorf("invalid config file: session_id_words must be between %d and %d, got %d",
+			MinSessionIDWords, MaxSessionIDWords, cfg.SessionIDWords)
+	}
+
+	if cfg.LockStrategy == "" {
+		cfg.LockStrategy = LockFlock
+	}
+
+	switch cfg.LockStrategy {
+	case LockFlock, LockLockfile:
+	default:
+		return Config{}, fmt.Errorf("invalid config file: unknown lock_strategy %q (expected %q or %q)", cfg.LockStrategy, LockFlock, LockLockfile)
+	}
+
+	if cfg.LockTimeout != "" {
+		if d, err := ParseAge(cfg.LockTimeout); err != nil {
+			return Config{}, fmt.Errorf("invalid config file: lock_timeout: %w", err)
+		} else if d <= 0 {
+			return Config{}, fmt.Errorf("invalid config file: lock_timeout must be positive, got '%s'", cfg.LockTimeout)
+		}
+	}
+
+	if cfg.LockLease != "" {
+		if d, err := ParseAge(cfg.LockLease); err != nil {
+			return Config{}, fmt.Errorf("invalid config file: lock_lease: %w", err)
+		} else if d <= 0 {
+			return Config{}, fmt.Errorf("invalid config file: lock_lease must be positive, got '%s'", cfg.LockLease)
+		}
+	}
+
+	if _, _, err := cfg.Retention.Durations(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file: retention: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// ParseAge parses a duration that may use day ("30d") or week ("2w") units
+// in addition to the units supported by time.ParseDuration
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(num)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration '%s'. Use e.g. 30d, 2w or 12h.", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration '%s'. Use e.g. 30d, 2w or 12h.", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDefaults(t *testing.T) {
+	cfg, err := Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Store != StoreFile {
+		t.Errorf("expected default store %q, got %q", StoreFile, cfg.Store)
+	}
+	if cfg.SessionIDWords != DefaultSessionIDWords {
+		t.Errorf("expected default session ID words %d, got %d", DefaultSessionIDWords, cfg.SessionIDWords)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	cfg, err := Parse([]byte(`{"checksums":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Checksums {
+		t.Error("expected checksums to be enabled")
+	}
+}
+
+func TestParseKeyFile(t *testing.T) {
+	cfg, err := Parse([]byte(`{"key_file":"/secrets/council.key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.KeyFile != "/secrets/council.key" {
+		t.Errorf("expected key file to be set, got %q", cfg.KeyFile)
+	}
+}
+
+func TestParseLockTimeout(t *testing.T) {
+	cfg, err := Parse([]byte(`{"lock_timeout":"30s"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LockTimeoutDuration() != 30*time.Second {
+		t.Errorf("expected 30s, got %s", cfg.LockTimeoutDuration())
+	}
+
+	if Default().LockTimeoutDuration() != DefaultLockTimeout {
+		t.Errorf("expected default timeout, got %s", Default().LockTimeoutDuration())
+	}
+
+	for _, bad := range []string{`{"lock_timeout":"soon"}`, `{"lock_timeout":"0s"}`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestParseLockLease(t *testing.T) {
+	cfg, err := Parse([]byte(`{"lock_lease":"2m"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LockLeaseDuration() != 2*time.Minute {
+		t.Errorf("expected 2m, got %s", cfg.LockLeaseDuration())
+	}
+
+	if Default().LockLeaseDuration() != DefaultLockLease {
+		t.Errorf("expected default lease, got %s", Default().LockLeaseDuration())
+	}
+
+	for _, bad := range []string{`{"lock_lease":"forever"}`, `{"lock_lease":"0s"}`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestParseLockStrategy(t *testing.T) {
+	cfg, _ := Parse([]byte(`{}`))
+	if cfg.LockStrategy != LockFlock {
+		t.Errorf("expected default strategy %q, got %q", LockFlock, cfg.LockStrategy)
+	}
+
+	cfg, err := Parse([]byte(`{"lock_strategy":"lockfile"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LockStrategy != LockLockfile {
+		t.Errorf("expected %q, got %q", LockLockfile, cfg.LockStrategy)
+	}
+
+	if _, err := Parse([]byte(`{"lock_strategy":"fcntl"}`)); err == nil {
+		t.Error("expected error for unknown lock strategy")
+	}
+}
+
+func TestParseSessionIDWords(t *testing.T) {
+	cfg, err := Parse([]byte(`{"session_id_words":2}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SessionIDWords != 2 {
+		t.Errorf("expected 2 words, got %d", cfg.SessionIDWords)
+	}
+
+	for _, bad := range []string{`{"session_id_words":0}`, `{"session_id_words":11}`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestParseSQLiteStore(t *testing.T) {
+	cfg, err := Parse([]byte(`{"store":"sqlite"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Store != StoreSQLite {
+		t.Errorf("expected store %q, got %q", StoreSQLite, cfg.Store)
+	}
+}
+
+func TestParseUnknownStore(t *testing.T) {
+	_, err := Parse([]byte(`{"store":"postgres"}`))
+	if err == nil {
+		t.Error("expected error for unknown store")
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	_, err := Parse([]byte(`not json`))
+	if err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg != Default() {
+		t.Errorf("expected default config, got %+v", cfg)
+	}
+}
+
+func TestLoadFromFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".council")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"store":"sqlite"}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Store != StoreSQLite {
+		t.Errorf("expected store %q, got %q", StoreSQLite, cfg.Store)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	cfg, err := Parse([]byte(`{"retention":{"archive_after":"30d","delete_after":"2w"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Retention.IsSet() {
+		t.Error("retention should be set")
+	}
+
+	archiveAfter, deleteAfter, err := cfg.Retention.Durations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archiveAfter != 30*24*time.Hour {
+		t.Errorf("expected 30 days, got %v", archiveAfter)
+	}
+	if deleteAfter != 14*24*time.Hour {
+		t.Errorf("expected 14 days, got %v", deleteAfter)
+	}
+}
+
+func TestParseInvalidRetention(t *testing.T) {
+	_, err := Parse([]byte(`{"retention":{"archive_after":"soon"}}`))
+	if err == nil {
+		t.Error("expected error for invalid retention age")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"0d", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if err != nil {
+			t.Errorf("ParseAge(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q): expected %v, got %v", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestParseAgeInvalid(t *testing.T) {
+	for _, input := range []string{"", "d", "-3d", "abc", "1.5d", "-1h"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("ParseAge(%q): expected error", input)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // SessionNotFoundError indicates the session file does not exist
 type SessionNotFoundError struct {
@@ -69,3 +73,339 @@ type InvalidNextParticipantError struct {
 func (e *InvalidNextParticipantError) Error() string {
 	return fmt.Sprintf("'%s' is not an active participant or 'Moderator'. Cannot use as --next.", e.Name)
 }
+
+// SessionArchivedError indicates a write to an archived (read-only) session
+type SessionArchivedError struct {
+	SessionID string
+}
+
+func (e *SessionArchivedError) Error() string {
+	return fmt.Sprintf("Session '%s' is archived and read-only. View it with 'council status %s'.", e.SessionID, e.SessionID)
+}
+
+// InvalidForkPointError indicates a fork was requested at an event that doesn't exist
+type InvalidForkPointError struct {
+	SessionID  string
+	EventNum   int
+	EventCount int
+}
+
+func (e *InvalidForkPointError) Error() string {
+	return fmt.Sprintf("Cannot fork session '%s' at #%d: it has events #1 to #%d.",
+		e.SessionID, e.EventNum, e.EventCount)
+}
+
+// InvalidTagError indicates a session tag that can't be used
+type InvalidTagError struct {
+	Tag string
+}
+
+func (e *InvalidTagError) Error() string {
+	return fmt.Sprintf("Invalid tag '%s'. Tags can't be empty or contain spaces or commas.", e.Tag)
+}
+
+// SessionExistsError indicates a session with the requested ID already exists
+type SessionExistsError struct {
+	SessionID string
+}
+
+func (e *SessionExistsError) Error() string {
+	return fmt.Sprintf("Session '%s' already exists. Choose a different ID.", e.SessionID)
+}
+
+// InvalidSessionIDError indicates a user-provided session ID with disallowed characters
+type InvalidSessionIDError struct {
+	SessionID string
+}
+
+func (e *InvalidSessionIDError) Error() string {
+	return fmt.Sprintf("Invalid session ID '%s'. Use 1-64 letters, digits, '-' or '_', starting with a letter or digit.", e.SessionID)
+}
+
+// CorruptSessionError indicates a line in a session log that can't be parsed
+type CorruptSessionError struct {
+	SessionID string
+	Line      int
+	Reason    string
+}
+
+func (e *CorruptSessionError) Error() string {
+	return fmt.Sprintf("Session '%s' has an unreadable event at line %d (%s). Run 'council fsck %s' to inspect it.",
+		e.SessionID, e.Line, e.Reason, e.SessionID)
+}
+
+// NewerSchemaError indicates a session written with a newer event schema than
+// this version of council supports
+type NewerSchemaError struct {
+	SessionID string
+	Version   int
+	Supported int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("Session '%s' has events from schema version %d, but this version of council supports up to %d. Upgrade council to migrate it.",
+		e.SessionID, e.Version, e.Supported)
+}
+
+// LockTimeoutError indicates a session lock couldn't be acquired in time.
+// The holder fields are empty if the holder didn't record itself.
+type LockTimeoutError struct {
+	SessionID         string
+	Waited            time.Duration
+	HolderPID         int
+	HolderHost        string
+	HolderCommand     string
+	HolderParticipant string
+	HeldFor           time.Duration
+}
+
+func (e *LockTimeoutError) Error() string {
+	msg := fmt.Sprintf("Timed out after %s waiting for the lock on session '%s'", e.Waited, e.SessionID)
+	if e.HolderPID == 0 {
+		return msg + ". Run 'council locks' to see who holds it, then retry."
+	}
+
+	holder := fmt.Sprintf("pid %d", e.HolderPID)
+	if e.HolderHost != "" {
+		holder += " on " + e.HolderHost
+	}
+	if e.HolderParticipant != "" {
+		holder += fmt.Sprintf(" (%s)", e.HolderParticipant)
+	}
+	return fmt.Sprintf("%s: held for %s by %s running '%s'. If that process is suspended or stuck, resume or stop it, then retry.",
+		msg, e.HeldFor, holder, e.HolderCommand)
+}
+
+// EncryptionKeyMissingError indicates an encryption key was needed but none
+// is configured. SessionID is empty when creating an encrypted session.
+type EncryptionKeyMissingError struct {
+	SessionID string
+	KeyFile   string
+}
+
+func (e *EncryptionKeyMissingError) Error() string {
+	msg := "No encryption key is configured"
+	if e.SessionID != "" {
+		msg = fmt.Sprintf("Session '%s' is encrypted, but no encryption key is configured", e.SessionID)
+	}
+	return fmt.Sprintf("%s. Set COUNCIL_KEY or put the key in %s (create one with 'council keygen').", msg, e.KeyFile)
+}
+
+// EncryptionKeyMismatchError indicates an encrypted session was sealed with a
+// different key than the configured one. Keys are identified by fingerprint.
+type EncryptionKeyMismatchError struct {
+	SessionID     string
+	SessionKey    string
+	ConfiguredKey string
+}
+
+func (e *EncryptionKeyMismatchError) Error() string {
+	return fmt.Sprintf("Session '%s' was encrypted with key %s, but the configured key is %s. Set COUNCIL_KEY or key_file to the key the session was created with.",
+		e.SessionID, e.SessionKey, e.ConfiguredKey)
+}
+
+// InvalidEncryptionKeyError indicates a configured encryption key that can't be used
+type InvalidEncryptionKeyError struct {
+	Source string // COUNCIL_KEY or the key file's path
+	Reason string
+}
+
+func (e *InvalidEncryptionKeyError) Error() string {
+	return fmt.Sprintf("Invalid encryption key in %s: %s. Keys are 32 random bytes in base64; create one with 'council keygen'.",
+		e.Source, e.Reason)
+}
+
+// InvalidPollError indicates a poll that can't be opened as specified
+type InvalidPollError struct {
+	Reason string
+}
+
+func (e *InvalidPollError) Error() string {
+	return fmt.Sprintf("Invalid poll: %s.", e.Reason)
+}
+
+// PollNotFoundError indicates a vote in a poll that doesn't exist
+type PollNotFoundError struct {
+	SessionID string
+	EventNum  int
+}
+
+func (e *PollNotFoundError) Error() string {
+	return fmt.Sprintf("Event #%d of session '%s' is not a poll. Open polls are listed by 'council status %s'.",
+		e.EventNum, e.SessionID, e.SessionID)
+}
+
+// PollClosedError indicates a vote in a poll that no longer accepts votes
+type PollClosedError struct {
+	EventNum    int
+	ClosesAfter int
+}
+
+func (e *PollClosedError) Error() string {
+	return fmt.Sprintf("Poll #%d closed after event #%d. Votes can no longer be cast or changed.", e.EventNum, e.ClosesAfter)
+}
+
+// InvalidChoiceError indicates a vote for something that isn't one of the poll's options
+type InvalidChoiceError struct {
+	EventNum int
+	Choice   string
+	Options  []string
+}
+
+func (e *InvalidChoiceError) Error() string {
+	return fmt.Sprintf("'%s' is not an option in poll #%d. Choose one of: %s.", e.Choice, e.EventNum, strings.Join(e.Options, ", "))
+}
+
+// InvalidDecisionError indicates a decision that can't be recorded as given
+type InvalidDecisionError struct {
+	Reason string
+}
+
+func (e *InvalidDecisionError) Error() string {
+	return fmt.Sprintf("Invalid decision: %s.", e.Reason)
+}
+
+// DecisionNotFoundError indicates a reference to a decision that doesn't exist
+type DecisionNotFoundError struct {
+	SessionID string
+	EventNum  int
+}
+
+func (e *DecisionNotFoundError) Error() string {
+	return fmt.Sprintf("Event #%d of session '%s' is not a decision. List decisions with 'council decisions %s'.",
+		e.EventNum, e.SessionID, e.SessionID)
+}
+
+// DecisionSupersededError indicates superseding a decision that was already superseded
+type DecisionSupersededError struct {
+	EventNum     int
+	SupersededBy int
+}
+
+func (e *DecisionSupersededError) Error() string {
+	return fmt.Sprintf("Decision #%d was already superseded by #%d. Supersede #%d instead.",
+		e.EventNum, e.SupersededBy, e.SupersededBy)
+}
+
+// MessageNotFoundError indicates a reference to a message that doesn't exist
+type MessageNotFoundError struct {
+	SessionID string
+	EventNum  int
+}
+
+func (e *MessageNotFoundError) Error() string {
+	return fmt.Sprintf("Event #%d of session '%s' is not a message. Check event numbers with 'council status %s'.",
+		e.EventNum, e.SessionID, e.SessionID)
+}
+
+// NotMessageAuthorError indicates changing a message someone else posted
+type NotMessageAuthorError struct {
+	Name     string
+	EventNum int
+	Author   string
+}
+
+func (e *NotMessageAuthorError) Error() string {
+	return fmt.Sprintf("'%s' can't change message #%d. Only its author, '%s', or the Moderator can edit or retract it.",
+		e.Name, e.EventNum, e.Author)
+}
+
+// MessageRetractedError indicates changing a message that was retracted
+type MessageRetractedError struct {
+	EventNum    int
+	RetractedAt int
+}
+
+func (e *MessageRetractedError) Error() string {
+	return fmt.Sprintf("Message #%d was retracted at #%d and can no longer be edited or retracted.", e.EventNum, e.RetractedAt)
+}
+
+// InvalidArtifactNameError indicates an artifact name that can't be used as a file name
+type InvalidArtifactNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidArtifactNameError) Error() string {
+	return fmt.Sprintf("Invalid artifact name '%s': %s. Use a plain file name like 'schema.sql'.", e.Name, e.Reason)
+}
+
+// ArtifactNotFoundError indicates a reference to an artifact that doesn't exist
+type ArtifactNotFoundError struct {
+	SessionID string
+	Name      string
+}
+
+func (e *ArtifactNotFoundError) Error() string {
+	return fmt.Sprintf("No artifact '%s' in session '%s'. List artifacts with 'council artifact list %s'.",
+		e.Name, e.SessionID, e.SessionID)
+}
+
+// InvalidFactError indicates a fact key or value that can't be stored
+type InvalidFactError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidFactError) Error() string {
+	return fmt.Sprintf("Invalid fact '%s': %s.", e.Key, e.Reason)
+}
+
+// FactNotFoundError indicates a reference to a fact that isn't set
+type FactNotFoundError struct {
+	SessionID string
+	Key       string
+}
+
+func (e *FactNotFoundError) Error() string {
+	return fmt.Sprintf("No fact '%s' in session '%s'. List facts with 'council fact list %s'.",
+		e.Key, e.SessionID, e.SessionID)
+}
+
+// FactConflictError indicates a compare-and-set of a fact that changed since
+// it was read, the fact equivalent of StaleStateError
+type FactConflictError struct {
+	SessionID       string
+	Key             string
+	ExpectedVersion int
+	ActualVersion   int
+}
+
+func (e *FactConflictError) Error() string {
+	if e.ActualVersion == 0 {
+		return fmt.Sprintf("Fact '%s' isn't set, so its version is 0, not %d. Re-read with 'council fact list %s' before changing it.",
+			e.Key, e.ExpectedVersion, e.SessionID)
+	}
+	return fmt.Sprintf("Fact '%s' is at version %d, not %d. Re-read with 'council fact get %s %s' before changing it.",
+		e.Key, e.ActualVersion, e.ExpectedVersion, e.SessionID, e.Key)
+}
+
+// InvalidTaskError indicates a task that can't be added or assigned as given
+type InvalidTaskError struct {
+	Reason string
+}
+
+func (e *InvalidTaskError) Error() string {
+	return fmt.Sprintf("Invalid task: %s.", e.Reason)
+}
+
+// TaskNotFoundError indicates a reference to a task that doesn't exist
+type TaskNotFoundError struct {
+	SessionID string
+	EventNum  int
+}
+
+func (e *TaskNotFoundError) Error() string {
+	return fmt.Sprintf("Event #%d of session '%s' is not a task. List tasks with 'council tasks %s'.",
+		e.EventNum, e.SessionID, e.SessionID)
+}
+
+// TaskDoneError indicates changing a task that was already done
+type TaskDoneError struct {
+	EventNum int
+	DoneAt   int
+}
+
+func (e *TaskDoneError) Error() string {
+	return fmt.Sprintf("Task #%d was already marked done at #%d. Add a new task for follow-up work.", e.EventNum, e.DoneAt)
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSessionNotFoundError(t *testing.T) {
@@ -102,6 +103,239 @@ func TestInvalidNextParticipantError(t *testing.T) {
 	}
 }
 
+func TestSessionArchivedError(t *testing.T) {
+	err := &SessionArchivedError{SessionID: "old-session"}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "old-session") {
+		t.Errorf("error should contain session ID, got %q", msg)
+	}
+	if !strings.Contains(msg, "archived") {
+		t.Errorf("error should mention 'archived', got %q", msg)
+	}
+	if !strings.Contains(msg, "council status old-session") {
+		t.Errorf("error should suggest 'council status', got %q", msg)
+	}
+}
+
+func TestInvalidForkPointError(t *testing.T) {
+	err := &InvalidForkPointError{SessionID: "parent", EventNum: 12, EventCount: 5}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "#12") {
+		t.Errorf("error should contain requested event, got %q", msg)
+	}
+	if !strings.Contains(msg, "#1 to #5") {
+		t.Errorf("error should contain valid range, got %q", msg)
+	}
+}
+
+func TestInvalidTagError(t *testing.T) {
+	err := &InvalidTagError{Tag: "two words"}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "'two words'") {
+		t.Errorf("error should contain the tag, got %q", msg)
+	}
+}
+
+func TestSessionExistsError(t *testing.T) {
+	err := &SessionExistsError{SessionID: "design-review"}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "'design-review' already exists") {
+		t.Errorf("error should name the existing session, got %q", msg)
+	}
+}
+
+func TestInvalidSessionIDError(t *testing.T) {
+	err := &InvalidSessionIDError{SessionID: "../etc"}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "'../etc'") {
+		t.Errorf("error should contain the ID, got %q", msg)
+	}
+	if !strings.Contains(msg, "letters, digits") {
+		t.Errorf("error should describe allowed characters, got %q", msg)
+	}
+}
+
+func TestCorruptSessionError(t *testing.T) {
+	err := &CorruptSessionError{SessionID: "broken", Line: 7, Reason: "unexpected end of JSON input"}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "line 7") {
+		t.Errorf("error should contain the line number, got %q", msg)
+	}
+	if !strings.Contains(msg, "council fsck broken") {
+		t.Errorf("error should suggest 'council fsck', got %q", msg)
+	}
+}
+
+func TestNewerSchemaError(t *testing.T) {
+	err := &NewerSchemaError{SessionID: "future", Version: 3, Supported: 1}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "schema version 3") || !strings.Contains(msg, "up to 1") {
+		t.Errorf("error should contain both versions, got %q", msg)
+	}
+	if !strings.Contains(msg, "Upgrade council") {
+		t.Errorf("error should suggest upgrading, got %q", msg)
+	}
+}
+
+func TestLockTimeoutError(t *testing.T) {
+	err := &LockTimeoutError{
+		SessionID:         "busy",
+		Waited:            10 * time.Second,
+		HolderPID:         4242,
+		HolderCommand:     "council post busy --participant Alice",
+		HolderParticipant: "Alice",
+		HeldFor:           3 * time.Minute,
+	}
+	msg := err.Error()
+
+	for _, want := range []string{"10s", "'busy'", "pid 4242 (Alice)", "3m0s", "council post busy", "resume or stop it"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestLockTimeoutErrorHost(t *testing.T) {
+	err := &LockTimeoutError{SessionID: "busy", Waited: time.Second, HolderPID: 7, HolderHost: "build-2", HolderCommand: "council join"}
+
+	if !strings.Contains(err.Error(), "pid 7 on build-2") {
+		t.Errorf("error should name the holder's host, got %q", err.Error())
+	}
+}
+
+func TestLockTimeoutErrorUnknownHolder(t *testing.T) {
+	err := &LockTimeoutError{SessionID: "busy", Waited: time.Second}
+	msg := err.Error()
+
+	if !strings.Contains(msg, "council locks") {
+		t.Errorf("error should suggest 'council locks', got %q", msg)
+	}
+}
+
+func TestEncryptionKeyMissingError(t *testing.T) {
+	err := &EncryptionKeyMissingError{SessionID: "secret", KeyFile: "/home/me/.council/key"}
+	msg := err.Error()
+
+	for _, want := range []string{"'secret' is encrypted", "COUNCIL_KEY", "/home/me/.council/key", "council keygen"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should contain %q, got %q", want, msg)
+		}
+	}
+
+	// Creating an encrypted session has no session to name yet
+	err = &EncryptionKeyMissingError{KeyFile: "/home/me/.council/key"}
+	if msg := err.Error(); strings.Contains(msg, "''") || !strings.HasPrefix(msg, "No encryption key") {
+		t.Errorf("unexpected message without a session: %q", msg)
+	}
+}
+
+func TestEncryptionKeyMismatchError(t *testing.T) {
+	err := &EncryptionKeyMismatchError{SessionID: "secret", SessionKey: "1a2b3c4d", ConfiguredKey: "5e6f7a8b"}
+	msg := err.Error()
+
+	for _, want := range []string{"'secret'", "1a2b3c4d", "5e6f7a8b"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestPollErrors(t *testing.T) {
+	msg := (&PollNotFoundError{SessionID: "s", EventNum: 3}).Error()
+	if !strings.Contains(msg, "#3") || !strings.Contains(msg, "council status s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&PollClosedError{EventNum: 4, ClosesAfter: 9}).Error()
+	if !strings.Contains(msg, "#4") || !strings.Contains(msg, "#9") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&InvalidChoiceError{EventNum: 4, Choice: "SOAP", Options: []string{"REST", "GraphQL"}}).Error()
+	if !strings.Contains(msg, "'SOAP'") || !strings.Contains(msg, "REST, GraphQL") {
+		t.Errorf("error should list the options, got %q", msg)
+	}
+}
+
+func TestDecisionErrors(t *testing.T) {
+	msg := (&DecisionNotFoundError{SessionID: "s", EventNum: 3}).Error()
+	if !strings.Contains(msg, "#3") || !strings.Contains(msg, "council decisions s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&DecisionSupersededError{EventNum: 4, SupersededBy: 9}).Error()
+	if !strings.Contains(msg, "Supersede #9 instead") {
+		t.Errorf("error should point at the standing decision, got %q", msg)
+	}
+}
+
+func TestMessageNotFoundError(t *testing.T) {
+	msg := (&MessageNotFoundError{SessionID: "s", EventNum: 3}).Error()
+	if !strings.Contains(msg, "#3") || !strings.Contains(msg, "council status s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
+func TestRevisionErrors(t *testing.T) {
+	msg := (&NotMessageAuthorError{Name: "Alice", EventNum: 5, Author: "Bob"}).Error()
+	if !strings.Contains(msg, "'Bob'") || !strings.Contains(msg, "Moderator") {
+		t.Errorf("error should name who may change the message, got %q", msg)
+	}
+
+	msg = (&MessageRetractedError{EventNum: 4, RetractedAt: 7}).Error()
+	if !strings.Contains(msg, "#4") || !strings.Contains(msg, "#7") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
+func TestArtifactErrors(t *testing.T) {
+	msg := (&InvalidArtifactNameError{Name: "../x", Reason: "it can't be a path"}).Error()
+	if !strings.Contains(msg, "'../x'") || !strings.Contains(msg, "it can't be a path") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&ArtifactNotFoundError{SessionID: "s", Name: "plan.md"}).Error()
+	if !strings.Contains(msg, "'plan.md'") || !strings.Contains(msg, "council artifact list s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
+func TestFactErrors(t *testing.T) {
+	msg := (&FactConflictError{SessionID: "s", Key: "region", ExpectedVersion: 1, ActualVersion: 2}).Error()
+	if !strings.Contains(msg, "version 2, not 1") || !strings.Contains(msg, "council fact get s region") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&FactConflictError{SessionID: "s", Key: "region", ExpectedVersion: 3}).Error()
+	if !strings.Contains(msg, "isn't set") || !strings.Contains(msg, "council fact list s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&FactNotFoundError{SessionID: "s", Key: "region"}).Error()
+	if !strings.Contains(msg, "'region'") || !strings.Contains(msg, "council fact list s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
+func TestTaskErrors(t *testing.T) {
+	msg := (&TaskNotFoundError{SessionID: "s", EventNum: 3}).Error()
+	if !strings.Contains(msg, "#3") || !strings.Contains(msg, "council tasks s") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	msg = (&TaskDoneError{EventNum: 4, DoneAt: 9}).Error()
+	if !strings.Contains(msg, "#4") || !strings.Contains(msg, "#9") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
 func TestErrorInterface(t *testing.T) {
 	// Verify all error types implement the error interface
 	var _ error = &SessionNotFoundError{}
@@ -111,4 +345,33 @@ func TestErrorInterface(t *testing.T) {
 	var _ error = &NotAParticipantError{}
 	var _ error = &ParticipantNotInSessionError{}
 	var _ error = &InvalidNextParticipantError{}
+	var _ error = &SessionArchivedError{}
+	var _ error = &InvalidForkPointError{}
+	var _ error = &InvalidTagError{}
+	var _ error = &SessionExistsError{}
+	var _ error = &InvalidSessionIDError{}
+	var _ error = &CorruptSessionError{}
+	var _ error = &NewerSchemaError{}
+	var _ error = &LockTimeoutError{}
+	var _ error = &EncryptionKeyMissingError{}
+	var _ error = &EncryptionKeyMismatchError{}
+	var _ error = &InvalidEncryptionKeyError{}
+	var _ error = &InvalidPollError{}
+	var _ error = &PollNotFoundError{}
+	var _ error = &PollClosedError{}
+	var _ error = &InvalidChoiceError{}
+	var _ error = &InvalidDecisionError{}
+	var _ error = &DecisionNotFoundError{}
+	var _ error = &DecisionSupersededError{}
+	var _ error = &MessageNotFoundError{}
+	var _ error = &NotMessageAuthorError{}
+	var _ error = &MessageRetractedError{}
+	var _ error = &InvalidArtifactNameError{}
+	var _ error = &ArtifactNotFoundError{}
+	var _ error = &InvalidFactError{}
+	var _ error = &FactNotFoundError{}
+	var _ error = &FactConflictError{}
+	var _ error = &InvalidTaskError{}
+	var _ error = &TaskNotFoundError{}
+	var _ error = &TaskDoneError{}
 }
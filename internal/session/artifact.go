@@ -0,0 +1,265 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// artifactHashPrefix starts every artifact hash, naming the algorithm
+const artifactHashPrefix = "sha256:"
+
+// artifactHashPattern matches a full artifact hash
+var artifactHashPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// maxArtifactNameLength is the longest artifact name allowed, in bytes
+const maxArtifactNameLength = 255
+
+// Artifact is the latest version of a named artifact in a session
+type Artifact struct {
+	EventNum        int    `json:"event_num"` // where this version was added
+	TimestampMillis int64  `json:"timestamp_millis"`
+	Participant     string `json:"participant"`
+	Name            string `json:"name"`
+	Size            int64  `json:"size"`
+	Hash            string `json:"hash"`
+	Versions        int    `json:"versions"` // how many times the name was added
+}
+
+// ShortHash returns the hash cut down to the length shown in status output,
+// which is still accepted by GetArtifact
+func (a *Artifact) ShortHash() string {
+	return shortHash(a.Hash)
+}
+
+// shortHash cuts a hash down to the length shown in status output
+func shortHash(hash string) string {
+	if len(hash) > len(artifactHashPrefix)+12 {
+		return hash[:len(artifactHashPrefix)+12]
+	}
+	return hash
+}
+
+// artifactHash returns the content hash of data
+func artifactHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return artifactHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// artifactStoreName returns the name an artifact with the given content hash
+// is stored under in a session's artifact store: the hash itself, or for an
+// encrypted session a keyed hash of it, so that stored names don't let
+// anyone without the key check whether a guessed document was shared
+func artifactStoreName(sessionID, hash string, encrypted bool) (string, error) {
+	if !encrypted {
+		return strings.TrimPrefix(hash, artifactHashPrefix), nil
+	}
+	name, err := keyedHash([]byte(sessionID + "/" + hash))
+	if err != nil {
+		return "", withSessionID(err, sessionID)
+	}
+	return name, nil
+}
+
+// sealArtifact prepares content for a session's artifact store, sealing it
+// for encrypted sessions. The seal is bound to the session and the content
+// hash, so sealed content can't be swapped for another's.
+func sealArtifact(sessionID, hash string, data []byte, encrypted bool) ([]byte, error) {
+	if !encrypted {
+		return data, nil
+	}
+	sealed, err := sealLine(data, artifactAD(sessionID, hash))
+	if err != nil {
+		return nil, withSessionID(err, sessionID)
+	}
+	return sealed, nil
+}
+
+// openArtifact reverses sealArtifact
+func openArtifact(sessionID, hash string, stored []byte, encrypted bool) ([]byte, error) {
+	if !encrypted {
+		return stored, nil
+	}
+	data, err := openLine(stored, artifactAD(sessionID, hash))
+	if err != nil {
+		return nil, withSessionID(err, sessionID)
+	}
+	return data, nil
+}
+
+// validArtifactName checks that an artifact name can be used as a file name
+// when the artifact is downloaded
+func validArtifactName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return &errors.InvalidArtifactNameError{Name: name, Reason: "it's empty"}
+	case len(name) > maxArtifactNameLength:
+		return &errors.InvalidArtifactNameError{Name: name, Reason: fmt.Sprintf("it's longer than %d bytes", maxArtifactNameLength)}
+	case strings.ContainsAny(name, `/\`) || name == "." || name == "..":
+		return &errors.InvalidArtifactNameError{Name: name, Reason: "it can't be a path"}
+	case strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+		return &errors.InvalidArtifactNameError{Name: name, Reason: "it can't contain control characters"}
+	}
+	return nil
+}
+
+// artifactStore returns the current store as an ArtifactStore
+func artifactStore() (ArtifactStore, error) {
+	store, ok := currentStore.(ArtifactStore)
+	if !ok {
+		return nil, fmt.Errorf("the configured store doesn't support artifacts")
+	}
+	return store, nil
+}
+
+// PutArtifact stores data as an artifact of a session under name, replacing
+// any earlier artifact of that name for readers while keeping it stored.
+// Like posting, it's open to active participants and the Moderator. The
+// content is sealed for encrypted sessions.
+// Returns the new event number (1-indexed for display)
+func PutArtifact(sessionID, participant, name string, data []byte) (int, error) {
+	store, err := artifactStore()
+	if err != nil {
+		return 0, err
+	}
+	if err := validArtifactName(name); err != nil {
+		return 0, err
+	}
+	hash := artifactHash(data)
+
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+			return nil, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		}
+
+		storeName, err := artifactStoreName(sessionID, hash, session.Encrypted)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := sealArtifact(sessionID, hash, data, session.Encrypted)
+		if err != nil {
+			return nil, err
+		}
+		// Stored before the event is appended, so every event's content exists
+		if err := store.PutArtifact(sessionID, storeName, stored); err != nil {
+			return nil, err
+		}
+		return NewArtifactAddedEvent(participant, name, int64(len(data)), hash), nil
+	})
+}
+
+// Artifacts returns the latest version of each artifact among the session's
+// loaded events, sorted by name
+func (s *Session) Artifacts() []*Artifact {
+	byName := map[string]*Artifact{}
+	for i, event := range s.Events {
+		e, ok := event.(*ArtifactAddedEvent)
+		if !ok {
+			continue
+		}
+		versions := 1
+		if prev := byName[e.Name]; prev != nil {
+			versions = prev.Versions + 1
+		}
+		byName[e.Name] = &Artifact{
+			EventNum:        s.Offset + i + 1,
+			TimestampMillis: e.GetTimestamp(),
+			Participant:     e.Participant,
+			Name:            e.Name,
+			Size:            e.Size,
+			Hash:            e.Hash,
+			Versions:        versions,
+		}
+	}
+
+	artifacts := make([]*Artifact, 0, len(byName))
+	for _, a := range byName {
+		artifacts = append(artifacts, a)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+	return artifacts
+}
+
+// findArtifact finds an artifact of a fully loaded session by name, giving
+// its latest version, or by hash or hash prefix such as the short hash in
+// status output, giving the latest version with that content
+func findArtifact(sess *Session, ref string) *Artifact {
+	var found *Artifact
+	for i, event := range sess.Events {
+		e, ok := event.(*ArtifactAddedEvent)
+		if !ok {
+			continue
+		}
+		byHash := strings.HasPrefix(ref, artifactHashPrefix) && len(ref) > len(artifactHashPrefix) && strings.HasPrefix(e.Hash, ref)
+		if e.Name == ref || byHash {
+			found = &Artifact{
+				EventNum:        sess.Offset + i + 1,
+				TimestampMillis: e.GetTimestamp(),
+				Participant:     e.Participant,
+				Name:            e.Name,
+				Size:            e.Size,
+				Hash:            e.Hash,
+			}
+		}
+	}
+	return found
+}
+
+// GetArtifact returns an artifact of a fully loaded session and its content.
+// ref is the artifact's name or (a prefix of) its hash. The content is
+// checked against the hash, so damaged artifacts are never returned.
+func GetArtifact(sess *Session, ref string) (*Artifact, []byte, error) {
+	store, err := artifactStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	artifact := findArtifact(sess, ref)
+	if artifact == nil {
+		return nil, nil, &errors.ArtifactNotFoundError{SessionID: sess.ID, Name: ref}
+	}
+	if !artifactHashPattern.MatchString(artifact.Hash) {
+		return nil, nil, fmt.Errorf("artifact '%s' has a malformed hash '%s'", artifact.Name, artifact.Hash)
+	}
+
+	storeName, err := artifactStoreName(sess.ID, artifact.Hash, sess.Encrypted)
+	if err != nil {
+		return nil, nil, err
+	}
+	stored, err := store.GetArtifact(sess.ID, storeName)
+	if os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("the content of artifact '%s' (%s) is missing from the store", artifact.Name, artifact.ShortHash())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := openArtifact(sess.ID, artifact.Hash, stored, sess.Encrypted)
+	if err != nil {
+		return nil, nil, err
+	}
+	if artifactHash(data) != artifact.Hash {
+		return nil, nil, fmt.Errorf("artifact '%s' is damaged: its content no longer matches %s", artifact.Name, artifact.ShortHash())
+	}
+	return artifact, data, nil
+}
+
+// FormatSize formats a size in bytes for display, like "512 B" or "2.1 KB"
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, prefix := float64(size)/unit, "K"
+	for _, p := range []string{"M", "G"} {
+		if value < unit {
+			break
+		}
+		value, prefix = value/unit, p
+	}
+	return fmt.Sprintf("%.1f %sB", value, prefix)
+}
@@ -0,0 +1,255 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+func TestPutAndGetArtifact(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newAliceAndBobSession(t)
+
+			if num, err := PutArtifact("sess", "Alice", "schema.sql", []byte("CREATE TABLE a;")); err != nil || num != 4 {
+				t.Fatalf("expected the artifact at #4, got #%d: %v", num, err)
+			}
+			PutArtifact("sess", "Bob", "notes.md", []byte("# Notes"))
+			PutArtifact("sess", "Alice", "schema.sql", []byte("CREATE TABLE b;"))
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			artifacts := sess.Artifacts()
+			if len(artifacts) != 2 || artifacts[0].Name != "notes.md" || artifacts[1].Name != "schema.sql" {
+				t.Fatalf("expected the latest version of each artifact by name, got %+v", artifacts)
+			}
+			if latest := artifacts[1]; latest.EventNum != 6 || latest.Versions != 2 || latest.Size != 15 {
+				t.Errorf("unexpected latest version: %+v", latest)
+			}
+
+			artifact, data, err := GetArtifact(sess, "schema.sql")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != "CREATE TABLE b;" || artifact.EventNum != 6 {
+				t.Errorf("expected the latest content, got #%d %q", artifact.EventNum, data)
+			}
+
+			// Earlier versions stay reachable by hash
+			old := sess.Events[3].(*ArtifactAddedEvent)
+			if _, data, err := GetArtifact(sess, shortHash(old.Hash)); err != nil || string(data) != "CREATE TABLE a;" {
+				t.Errorf("expected the first version by short hash, got %q: %v", data, err)
+			}
+
+			if _, _, err := GetArtifact(sess, "missing.txt"); !isErr[*errors.ArtifactNotFoundError](err) {
+				t.Errorf("expected ArtifactNotFoundError, got %T: %v", err, err)
+			}
+			if _, _, err := GetArtifact(sess, "sha256:"); !isErr[*errors.ArtifactNotFoundError](err) {
+				t.Errorf("a bare prefix shouldn't match, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPutArtifactRules(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+
+	for _, name := range []string{"", "  ", "../etc/passwd", "a/b", `a\b`, "..", "bad\nname", strings.Repeat("x", 256)} {
+		if _, err := PutArtifact("sess", "Alice", name, []byte("x")); !isErr[*errors.InvalidArtifactNameError](err) {
+			t.Errorf("expected InvalidArtifactNameError for %q, got %v", name, err)
+		}
+	}
+	if _, err := PutArtifact("sess", "Carol", "a.txt", []byte("x")); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, err := PutArtifact("sess", "Moderator", "a.txt", []byte("x")); err != nil {
+		t.Errorf("the Moderator should add artifacts, got %v", err)
+	}
+	if _, err := PutArtifact("missing", "Alice", "a.txt", []byte("x")); !isErr[*errors.SessionNotFoundError](err) {
+		t.Errorf("expected SessionNotFoundError, got %T: %v", err, err)
+	}
+}
+
+func TestGetArtifactDetectsDamage(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+	PutArtifact("sess", "Alice", "a.txt", []byte("original"))
+
+	sess, _ := LoadSession("sess")
+	dir, _ := storage.SessionDirPath("sess")
+	hash := strings.TrimPrefix(sess.Artifacts()[0].Hash, artifactHashPrefix)
+	path := filepath.Join(dir, storage.ArtifactsDir, hash)
+
+	if err := os.WriteFile(path, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := GetArtifact(sess, "a.txt"); err == nil || !strings.Contains(err.Error(), "damaged") {
+		t.Errorf("expected a damaged artifact error, got %v", err)
+	}
+
+	os.Remove(path)
+	if _, _, err := GetArtifact(sess, "a.txt"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected a missing content error, got %v", err)
+	}
+}
+
+func TestEncryptedArtifact(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			useStore(t, s)
+			useKey(t)
+			newEncryptedSession(t)
+
+			content := []byte("the launch code is 1234")
+			if _, err := PutArtifact("sess", "Alice", "code.txt", content); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Stored under a keyed name, so a guessed document can't be
+			// confirmed by hashing it
+			sess, _ := LoadSession("sess")
+			hash := sess.Artifacts()[0].Hash
+			if _, err := s.(ArtifactStore).GetArtifact("sess", strings.TrimPrefix(hash, artifactHashPrefix)); !os.IsNotExist(err) {
+				t.Errorf("content of encrypted sessions shouldn't be stored under its hash: %v", err)
+			}
+			storeName, _ := artifactStoreName("sess", hash, true)
+			stored, err := s.(ArtifactStore).GetArtifact("sess", storeName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bytes.Contains(stored, content) {
+				t.Error("artifacts of encrypted sessions should be stored sealed")
+			}
+
+			if _, data, err := GetArtifact(sess, "code.txt"); err != nil || !bytes.Equal(data, content) {
+				t.Errorf("expected the opened content, got %q: %v", data, err)
+			}
+		})
+	}
+}
+
+func TestEncryptedArtifactBoundToSession(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+	if err := CreateSessionWithOptions("other", CreateOptions{Encrypt: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	JoinSession("other", "Alice")
+	PutArtifact("sess", "Alice", "code.txt", []byte("the launch code is 1234"))
+	PutArtifact("other", "Alice", "code.txt", []byte("nothing to see"))
+
+	// Sealed content moved into another session under the name that session
+	// expects doesn't open there
+	sess, _ := LoadSession("sess")
+	other, _ := LoadSession("other")
+	fromName, _ := artifactStoreName("sess", sess.Artifacts()[0].Hash, true)
+	toName, _ := artifactStoreName("other", other.Artifacts()[0].Hash, true)
+	fromDir, _ := storage.SessionDirPath("sess")
+	toDir, _ := storage.SessionDirPath("other")
+	sealed, err := os.ReadFile(filepath.Join(fromDir, storage.ArtifactsDir, fromName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(toDir, storage.ArtifactsDir, toName), sealed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := GetArtifact(other, "code.txt"); err == nil {
+		t.Error("content sealed for another session should fail to open")
+	}
+}
+
+func TestForkCopiesArtifacts(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newAliceAndBobSession(t)
+			PutArtifact("sess", "Alice", "a.txt", []byte("before the fork"))
+
+			if err := ForkSession("sess", "child", 4, true); err != nil {
+				t.Fatalf("fork failed: %v", err)
+			}
+
+			child, _ := LoadSession("child")
+			if _, data, err := GetArtifact(child, "a.txt"); err != nil || string(data) != "before the fork" {
+				t.Errorf("expected the artifact in the fork, got %q: %v", data, err)
+			}
+		})
+	}
+}
+
+func TestForkCopiesEncryptedArtifacts(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+	if _, err := PutArtifact("sess", "Alice", "code.txt", []byte("the launch code is 1234")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ForkSession("sess", "child", 5, true); err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	child, _ := LoadSession("child")
+	if _, data, err := GetArtifact(child, "code.txt"); err != nil || string(data) != "the launch code is 1234" {
+		t.Errorf("expected the artifact in the fork, got %q: %v", data, err)
+	}
+}
+
+func TestFormatStatusShowsArtifacts(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+	PutArtifact("sess", "Alice", "plan.md", bytes.Repeat([]byte("x"), 2048))
+
+	sess, _ := LoadSession("sess")
+	want := "--- #4 | Alice added artifact plan.md (2.0 KB, " + sess.Artifacts()[0].ShortHash() + ") ---"
+	if out := FormatStatus(sess, 0); !strings.Contains(out, want) {
+		t.Errorf("status should contain %q:\n%s", want, out)
+	}
+}
+
+func TestCheckSessionArtifacts(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+
+		`{"type":"artifact_added","timestamp_millis":4,"participant":"Alice","name":"a/b","size":1,"hash":"sha256:`+strings.Repeat("0", 64)+`"}
+{"type":"artifact_added","timestamp_millis":5,"participant":"Alice","name":"c.txt","size":1,"hash":"md5:123"}
+`)
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %+v", report.Problems)
+	}
+	if report.Problems[0].Line != 4 || !strings.Contains(report.Problems[0].Message, "can't be a path") {
+		t.Errorf("unexpected problem: %+v", report.Problems[0])
+	}
+	if report.Problems[1].Line != 5 || !strings.Contains(report.Problems[1].Message, "malformed hash") {
+		t.Errorf("unexpected problem: %+v", report.Problems[1])
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1024:            "1.0 KB",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+		3 << 30:         "3.0 GB",
+	} {
+		if got := FormatSize(size); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package session
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// hashLine returns the chain hash of a stored event line
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(bytes.TrimRight(line, "\r"))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeAppend serializes events for appending to a session's log, which
+// holds count events and whose first and last stored lines are given (nil
+// for a new session). In a hash-chained session each event's prev_hash is
+// set to the hash of the line before it; otherwise any prev_hash is cleared,
+// e.g. on events copied by a fork. In an encrypted session each line is
+// encrypted, bound to its session and event number, and chained as stored.
+func encodeAppend(sessionID string, count int, first, last []byte, events []Event, checksums bool) ([][]byte, error) {
+	created, err := createdEvent(sessionID, first, events)
+	if err != nil {
+		return nil, err
+	}
+	chained := created != nil && created.HashChain
+	encrypted := created != nil && created.Encrypted
+
+	lines := make([][]byte, 0, len(events))
+	for i, event := range events {
+		prevHash := ""
+		if chained && last != nil {
+			prevHash = hashLine(last)
+		}
+		if chainable, ok := event.(interface{ setPrevHash(string) }); ok {
+			chainable.setPrevHash(prevHash)
+		}
+
+		line, err := MarshalEventLine(event, checksums)
+		if err != nil {
+			return nil, err
+		}
+		if encrypted {
+			if line, err = sealLine(line, lineAD(adEvent, sessionID, count+i+1)); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, line)
+		last = line
+	}
+	return lines, nil
+}
+
+// createdEvent returns a session's session_created event, which holds its
+// options, from its stored first line or, for a new session, the events
+// being written. Returns nil if there isn't one.
+func createdEvent(sessionID string, first []byte, events []Event) (*SessionCreatedEvent, error) {
+	var event Event
+	if first != nil {
+		var err error
+		if event, err = parseStoredEvent(sessionID, 1, bytes.TrimRight(first, "\r"), false); err != nil {
+			return nil, err
+		}
+	} else if len(events) > 0 {
+		event = events[0]
+	}
+
+	created, _ := event.(*SessionCreatedEvent)
+	return created, nil
+}
+
+// ChainReport is the result of verifying a session's hash chain
+type ChainReport struct {
+	SessionID string
+	Enabled   bool // the session was created with a hash chain
+	Events    int  // events verified before the first broken link, if any
+
+	// BrokenLine is the first line whose prev_hash doesn't match the line
+	// before it, or that can't be read. 0 if the chain is intact.
+	BrokenLine int
+	Reason     string
+
+	// Head is the hash of the last line. Recording it elsewhere makes later
+	// edits to that line detectable too.
+	Head string
+
+	// Position of the last verified line, where VerifyChainSince resumes,
+	// and whether the session's lines are encrypted
+	lastNum    int
+	lastOffset int64
+	encrypted  bool
+}
+
+// Intact checks if the chain is enabled and unbroken
+func (r *ChainReport) Intact() bool {
+	return r.Enabled && r.BrokenLine == 0
+}
+
+// VerifyChain checks every link of a session's hash chain
+func VerifyChain(sessionID string) (*ChainReport, error) {
+	lines, err := currentStore.ReadRaw(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireKey(sessionID, lines); err != nil {
+		return nil, err
+	}
+	return verifyLines(sessionID, lines), nil
+}
+
+// VerifyChainSince checks the links added to a session's hash chain since an
+// earlier report of it, re-reading only the last line that report verified.
+// Stores that can't read from a position, and logs that no longer hold that
+// line as it was (e.g. rewritten by a migration), are verified in full.
+// Unchained sessions stay unchained, so their report is returned as is.
+func VerifyChainSince(sessionID string, prev *ChainReport) (*ChainReport, error) {
+	if prev != nil && !prev.Enabled && prev.BrokenLine == 0 {
+		return prev, nil
+	}
+	reader, ok := currentStore.(RawTailReader)
+	if !ok || prev == nil || !prev.Intact() || prev.lastNum == 0 {
+		return VerifyChain(sessionID)
+	}
+
+	lines, err := reader.ReadRawFrom(sessionID, prev.lastNum, prev.lastOffset)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !lines[0].Terminated || hashLine(lines[0].Data) != prev.Head {
+		return VerifyChain(sessionID)
+	}
+	if err := requireKey(sessionID, lines); err != nil {
+		return nil, err
+	}
+
+	report := *prev
+	report.verify(lines[1:], lines[0].Data)
+	return &report, nil
+}
+
+// verifyLines checks the hash chain over a session's raw log lines
+func verifyLines(sessionID string, lines []RawLine) *ChainReport {
+	report := &ChainReport{SessionID: sessionID}
+	report.verify(lines, nil)
+	return report
+}
+
+// verify extends the report with lines following prev, the last line it
+// verified (nil for none)
+func (r *ChainReport) verify(lines []RawLine, prev []byte) {
+	for _, line := range lines {
+		data := bytes.TrimRight(line.Data, "\r")
+		if len(data) == 0 {
+			continue
+		}
+
+		if prev == nil {
+			r.encrypted = isEncrypted(data)
+		}
+		event, err := parseStoredEvent(r.SessionID, r.Events+1, data, r.encrypted)
+		if err != nil {
+			r.BrokenLine = line.Num
+			r.Reason = fmt.Sprintf("unreadable event: %v", err)
+			return
+		}
+
+		if prev == nil {
+			created, ok := event.(*SessionCreatedEvent)
+			r.Enabled = ok && created.HashChain
+			if !r.Enabled {
+				return
+			}
+		} else if got, want := event.getPrevHash(), hashLine(prev); got != want {
+			r.BrokenLine = line.Num
+			if got == "" {
+				r.Reason = "missing prev_hash"
+			} else {
+				r.Reason = fmt.Sprintf("prev_hash %.12s… doesn't match the previous line (%.12s…)", got, want)
+			}
+			return
+		}
+
+		r.Events++
+		r.Head = hashLine(data)
+		r.lastNum = line.Num
+		r.lastOffset = line.Offset
+		prev = data
+	}
+}
@@ -0,0 +1,216 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/amterp/council/internal/storage"
+)
+
+func newChainedSession(t *testing.T) {
+	t.Helper()
+	if err := CreateSessionWithOptions("sess", CreateOptions{HashChain: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Bob")
+	PostMessage("sess", "Alice", "hello", "Bob", 3)
+}
+
+func TestVerifyChainIntact(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newChainedSession(t)
+
+			report, err := VerifyChain("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !report.Intact() {
+				t.Fatalf("expected intact chain, got %+v", report)
+			}
+			if report.Events != 4 {
+				t.Errorf("expected 4 events, got %d", report.Events)
+			}
+			if len(report.Head) != 64 {
+				t.Errorf("expected a sha256 head, got %q", report.Head)
+			}
+
+			sess, _ := LoadSession("sess")
+			if sess.Events[0].getPrevHash() != "" {
+				t.Error("session_created should have no prev_hash")
+			}
+			for i, event := range sess.Events[1:] {
+				if event.getPrevHash() == "" {
+					t.Errorf("event #%d is missing its prev_hash", i+2)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyChainDetectsEdit(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte(`"participant":"Bob"`), []byte(`"participant":"Mallory"`), 1), 0644)
+
+	report, err := VerifyChain("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Intact() {
+		t.Fatal("expected broken chain after editing a line")
+	}
+	// Line 3 was edited, so line 4's link to it breaks
+	if report.BrokenLine != 4 {
+		t.Errorf("expected break at line 4, got %d (%s)", report.BrokenLine, report.Reason)
+	}
+	if report.Events != 3 {
+		t.Errorf("expected 3 events verified before the break, got %d", report.Events)
+	}
+}
+
+func TestVerifyChainDetectsRemovedLine(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	os.WriteFile(path, bytes.Join(append(lines[:1], lines[2:]...), nil), 0644)
+
+	report, _ := VerifyChain("sess")
+	if report.BrokenLine != 2 {
+		t.Errorf("expected break at line 2, got %+v", report)
+	}
+}
+
+func TestVerifyChainNotEnabled(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+
+	report, err := VerifyChain("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Enabled || report.Intact() {
+		t.Errorf("expected chain to be disabled, got %+v", report)
+	}
+
+	sess, _ := LoadSession("sess")
+	if sess.Events[1].getPrevHash() != "" {
+		t.Error("unchained sessions shouldn't record prev_hash")
+	}
+}
+
+func TestForkStartsOwnChain(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+
+	if err := ForkSession("sess", "child", 4, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := VerifyChain("child")
+	if !report.Intact() || report.Events != 5 {
+		t.Errorf("expected intact 5-event chain in fork, got %+v", report)
+	}
+
+	// The parent's chain continues through its forked event
+	report, _ = VerifyChain("sess")
+	if !report.Intact() || report.Events != 5 {
+		t.Errorf("expected intact 5-event chain in parent, got %+v", report)
+	}
+}
+
+func TestForkOfUnchainedSessionHasNoPrevHash(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+
+	ForkSession("sess", "child", 2, true)
+
+	child, _ := LoadSession("child")
+	for _, event := range child.Events {
+		if event.getPrevHash() != "" {
+			t.Errorf("expected no prev_hash in unchained fork, got %+v", event)
+		}
+	}
+}
+
+func TestVerifyChainSince(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newChainedSession(t)
+
+			prev, err := VerifyChainSince("sess", nil)
+			if err != nil || !prev.Intact() || prev.Events != 4 {
+				t.Fatalf("expected 4 verified events, got %+v (%v)", prev, err)
+			}
+			PostMessage("sess", "Bob", "hi", "Alice", 4)
+
+			report, err := VerifyChainSince("sess", prev)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !report.Intact() || report.Events != 5 || report.Head == prev.Head {
+				t.Errorf("expected the new event to extend the chain, got %+v", report)
+			}
+			if prev.Events != 4 {
+				t.Errorf("the earlier report shouldn't change, got %+v", prev)
+			}
+		})
+	}
+}
+
+func TestVerifyChainSinceReadsOnlyNewLines(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+	prev, _ := VerifyChainSince("sess", nil)
+	PostMessage("sess", "Bob", "hi", "Alice", 4)
+
+	// An edit to a line verified before isn't seen, as it isn't re-read;
+	// VerifyChain still catches it
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte(`"participant":"Bob"`), []byte(`"participant":"Bub"`), 1), 0644)
+
+	if report, err := VerifyChainSince("sess", prev); err != nil || !report.Intact() || report.Events != 5 {
+		t.Errorf("expected only the new line to be verified, got %+v (%v)", report, err)
+	}
+	if report, _ := VerifyChain("sess"); report.Intact() {
+		t.Error("expected VerifyChain to re-read every line")
+	}
+}
+
+func TestVerifyChainSinceRereadsChangedLog(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+	prev, _ := VerifyChainSince("sess", nil)
+
+	// The last verified line changed, so the log is verified from the start
+	PostMessage("sess", "Bob", "hi", "Alice", 4)
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte(`"content":"hello"`), []byte(`"content":"HELLO"`), 1), 0644)
+
+	report, err := VerifyChainSince("sess", prev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Intact() || report.BrokenLine != 5 {
+		t.Errorf("expected a break at line 5, got %+v", report)
+	}
+
+	// A truncated log no longer holds the last verified line either
+	os.WriteFile(path, data[:bytes.IndexByte(data, '\n')+1], 0644)
+	if report, _ := VerifyChainSince("sess", prev); !report.Intact() || report.Events != 1 {
+		t.Errorf("expected the shorter log to be verified in full, got %+v", report)
+	}
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+)
+
+// checksumField is the key appended to checksummed event lines. JSON string
+// values escape their quotes, so these bytes can only appear as a key.
+const checksumField = `,"crc32":"`
+
+// MarshalEventLine serializes an event for storage. With checksum set, a
+// crc32 field holding the CRC-32 (IEEE) of the event's canonical JSON is
+// appended, so readers can tell a damaged line from a valid event.
+func MarshalEventLine(e Event, checksum bool) ([]byte, error) {
+	data, err := MarshalEvent(e)
+	if err != nil || !checksum {
+		return data, err
+	}
+
+	sum := crc32.ChecksumIEEE(data)
+	line := append(data[:len(data)-1:len(data)-1], checksumField...)
+	return fmt.Appendf(line, `%08x"}`, sum), nil
+}
+
+// verifyChecksum checks the crc32 field of a line, if present, returning the
+// canonical JSON it covers. Lines without a checksum are returned unchanged.
+func verifyChecksum(line []byte) ([]byte, error) {
+	i := bytes.LastIndex(line, []byte(checksumField))
+	if i < 0 {
+		return line, nil
+	}
+
+	// The field is always last: 8 hex digits, a closing quote and brace
+	suffix := line[i+len(checksumField):]
+	if len(suffix) != 10 || suffix[8] != '"' || suffix[9] != '}' {
+		return nil, fmt.Errorf("malformed crc32 field")
+	}
+	want, err := strconv.ParseUint(string(suffix[:8]), 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("malformed crc32 field")
+	}
+
+	canonical := append(line[:i:i], '}')
+	if got := crc32.ChecksumIEEE(canonical); got != uint32(want) {
+		return nil, fmt.Errorf("checksum mismatch (stored %08x, computed %08x)", want, got)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,146 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+func TestMarshalEventLineChecksum(t *testing.T) {
+	event := NewMessageEvent("Alice", "hello", "Bob")
+
+	line, err := MarshalEventLine(event, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(line, []byte(`,"crc32":"`)) {
+		t.Fatalf("expected crc32 field, got %s", line)
+	}
+
+	parsed, err := ParseEvent(line)
+	if err != nil {
+		t.Fatalf("expected checksummed line to parse: %v", err)
+	}
+	if msg := parsed.(*MessageEvent); msg.Content != "hello" {
+		t.Errorf("expected content 'hello', got %q", msg.Content)
+	}
+}
+
+func TestMarshalEventLineWithoutChecksum(t *testing.T) {
+	event := NewJoinedEvent("Alice")
+
+	line, _ := MarshalEventLine(event, false)
+	plain, _ := MarshalEvent(event)
+	if !bytes.Equal(line, plain) {
+		t.Errorf("expected plain JSON, got %s", line)
+	}
+}
+
+func TestParseEventChecksumMismatch(t *testing.T) {
+	line, _ := MarshalEventLine(NewMessageEvent("Alice", "approve", "Bob"), true)
+	tampered := bytes.Replace(line, []byte("approve"), []byte("decline"), 1)
+
+	_, err := ParseEvent(tampered)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestParseEventMalformedChecksum(t *testing.T) {
+	input := `{"type":"joined","timestamp_millis":1,"participant":"Alice","crc32":"xyz"}`
+
+	if _, err := ParseEvent([]byte(input)); err == nil {
+		t.Error("expected error for malformed crc32 field")
+	}
+}
+
+func TestChecksumFieldInContentIsEscaped(t *testing.T) {
+	event := NewMessageEvent("Alice", `tricky ,"crc32":"00000000"} content`, "Bob")
+
+	for _, checksum := range []bool{false, true} {
+		line, _ := MarshalEventLine(event, checksum)
+		parsed, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("checksum=%v: unexpected error: %v", checksum, err)
+		}
+		if parsed.(*MessageEvent).Content != event.Content {
+			t.Errorf("checksum=%v: content not preserved", checksum)
+		}
+	}
+}
+
+func TestStoresWriteChecksums(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			switch st := s.(type) {
+			case *FileStore:
+				st.Checksums = true
+			case *SQLiteStore:
+				st.Checksums = true
+			}
+			useStore(t, s)
+
+			CreateSession("sess")
+			JoinSession("sess", "Alice")
+
+			lines, _ := s.ReadRaw("sess")
+			for _, line := range lines {
+				if !bytes.Contains(line.Data, []byte(`"crc32"`)) {
+					t.Errorf("line %d has no checksum: %s", line.Num, line.Data)
+				}
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sess.IsActiveParticipant("Alice") {
+				t.Error("expected Alice to be active")
+			}
+		})
+	}
+}
+
+func TestLoadSessionDetectsCorruptedEvent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, &FileStore{Checksums: true})
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+
+	// Flip a character without breaking the JSON
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte("Alice"), []byte("Alicf"), 1), 0644)
+
+	_, err := LoadSession("sess")
+	corrupt, ok := err.(*errors.CorruptSessionError)
+	if !ok || corrupt.Line != 2 {
+		t.Errorf("expected CorruptSessionError at line 2, got %v", err)
+	}
+}
+
+func TestAppendAfterUnterminatedEvent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+	CreateSession("sess")
+
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.TrimSuffix(data, []byte("\n")), 0644)
+
+	if _, err := JoinSession("sess", "Alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("expected appended event on its own line: %v", err)
+	}
+	if sess.EventCount() != 2 {
+		t.Errorf("expected 2 events, got %d", sess.EventCount())
+	}
+}
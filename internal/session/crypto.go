@@ -0,0 +1,343 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// KeyEnvVar is the environment variable holding the key for encrypted
+// sessions. It takes precedence over the key file.
+const KeyEnvVar = "COUNCIL_KEY"
+
+// keySize is the length of encryption keys in bytes, for AES-256
+const keySize = 32
+
+// encryptedPrefix starts every encrypted line, so encrypted sessions can be
+// recognized without the key
+const encryptedPrefix = `{"enc":"`
+
+// encryptedLine is the stored form of an encrypted event line. Each line is
+// sealed on its own with AES-256-GCM, so appends never touch earlier lines.
+type encryptedLine struct {
+	Enc string `json:"enc"` // base64 of the nonce followed by the sealed line
+	Key string `json:"key"` // fingerprint of the key it was sealed with
+}
+
+var (
+	keyMu sync.Mutex
+
+	// keyFile overrides storage.KeyPath when set
+	keyFile string
+
+	// keyAEAD and keyID cache the key once it has been loaded
+	keyAEAD cipher.AEAD
+	keyID   string
+
+	// keyNames is the HMAC key for artifact store names, derived from the key
+	keyNames []byte
+)
+
+// SetKeyFile sets the path of the encryption key file, or restores the
+// default (~/.council/key) if path is empty
+func SetKeyFile(path string) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	keyFile = path
+	keyAEAD, keyID, keyNames = nil, "", nil
+}
+
+// KeyFilePath returns the path of the encryption key file in use
+func KeyFilePath() (string, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	return keyFilePath()
+}
+
+func keyFilePath() (string, error) {
+	if keyFile != "" {
+		return keyFile, nil
+	}
+	return storage.KeyPath()
+}
+
+// GenerateKey returns a new random encryption key, encoded as stored in key
+// files and COUNCIL_KEY
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// CreateKeyFile writes a new random key to the key file, readable only by
+// the current user. It never replaces an existing key file, since sessions
+// encrypted with that key couldn't be read without it.
+func CreateKeyFile() (string, error) {
+	path, err := KeyFilePath()
+	if err != nil {
+		return "", err
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return "", fmt.Errorf("key file %s already exists. Sessions encrypted with it can't be read without it, so it won't be replaced.", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(key + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// encryptionKey returns the configured key, from COUNCIL_KEY or else the key
+// file, and its fingerprint. Returns *errors.EncryptionKeyMissingError
+// without a session ID if neither is set.
+func encryptionKey() (cipher.AEAD, string, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if keyAEAD != nil {
+		return keyAEAD, keyID, nil
+	}
+
+	path, err := keyFilePath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	source := KeyEnvVar
+	encoded := os.Getenv(KeyEnvVar)
+	if encoded == "" {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, "", &errors.EncryptionKeyMissingError{KeyFile: path}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		source, encoded = path, string(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, "", &errors.InvalidEncryptionKeyError{Source: source, Reason: "not valid base64"}
+	}
+	if len(key) != keySize {
+		return nil, "", &errors.InvalidEncryptionKeyError{Source: source, Reason: fmt.Sprintf("%d bytes instead of %d", len(key), keySize)}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, "", err
+	}
+
+	names := hmac.New(sha256.New, key)
+	names.Write([]byte("council/artifact-names"))
+
+	keyAEAD, keyID, keyNames = aead, keyFingerprint(key), names.Sum(nil)
+	return keyAEAD, keyID, nil
+}
+
+// keyedHash returns the HMAC-SHA256 of data under a key derived from the
+// configured key, hex-encoded. Unlike a plain hash, it can't be computed
+// for a guessed input without the key.
+func keyedHash(data []byte) (string, error) {
+	if _, _, err := encryptionKey(); err != nil {
+		return "", err
+	}
+	keyMu.Lock()
+	mac := hmac.New(sha256.New, keyNames)
+	keyMu.Unlock()
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// keyFingerprint identifies a key without revealing it
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// isEncrypted checks if a stored line is encrypted
+func isEncrypted(line []byte) bool {
+	return bytes.HasPrefix(line, []byte(encryptedPrefix))
+}
+
+// Kinds of sealed data, for lineAD and artifactAD
+const (
+	adEvent    = "event"
+	adIndex    = "index"
+	adSnapshot = "snapshot"
+	adArtifact = "artifact"
+)
+
+// lineAD returns the additional data a line is sealed with, binding it to
+// where it's stored: what kind of data it is, the session it belongs to and
+// its number there (an event number, or the event a snapshot is as of). A
+// sealed line moved anywhere else fails authentication.
+func lineAD(kind, sessionID string, num int) []byte {
+	return []byte(fmt.Sprintf("council/%s/%s/%d", kind, sessionID, num))
+}
+
+// artifactAD returns the additional data artifact content is sealed with:
+// like lineAD, but artifacts are numbered by their content hash, since
+// they're stored before their event is appended
+func artifactAD(sessionID, hash string) []byte {
+	return []byte(fmt.Sprintf("council/%s/%s/%s", adArtifact, sessionID, hash))
+}
+
+// sealLine encrypts a line with the configured key, authenticating ad along
+// with it
+func sealLine(line, ad []byte) ([]byte, error) {
+	aead, id, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, line, ad)
+	return json.Marshal(encryptedLine{
+		Enc: base64.StdEncoding.EncodeToString(sealed),
+		Key: id,
+	})
+}
+
+// openLine decrypts a line sealed by sealLine with the same additional data
+func openLine(line, ad []byte) ([]byte, error) {
+	if !isEncrypted(line) {
+		return nil, fmt.Errorf("not encrypted")
+	}
+
+	var enc encryptedLine
+	if err := json.Unmarshal(line, &enc); err != nil {
+		return nil, fmt.Errorf("malformed encrypted event: %w", err)
+	}
+	aead, id, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	if enc.Key != id {
+		return nil, &errors.EncryptionKeyMismatchError{SessionKey: enc.Key, ConfiguredKey: id}
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(enc.Enc)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted event")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted event failed authentication; it was modified, damaged or moved from another position")
+	}
+	return plain, nil
+}
+
+// openEventLine returns the plaintext of a stored event line, the num'th
+// event of a session. encrypted says if the session is, i.e. if its first
+// line is encrypted; it's ignored for the first line itself. Every line of
+// an encrypted session must be encrypted, so a plaintext line slipped into
+// one is rejected rather than read as the session's own.
+func openEventLine(sessionID string, num int, line []byte, encrypted bool) ([]byte, error) {
+	if num == 1 {
+		encrypted = isEncrypted(line)
+	}
+	switch {
+	case encrypted && !isEncrypted(line):
+		return nil, fmt.Errorf("unencrypted event in an encrypted session")
+	case !encrypted && isEncrypted(line):
+		return nil, fmt.Errorf("encrypted event in an unencrypted session")
+	case !encrypted:
+		return line, nil
+	}
+	return openLine(line, lineAD(adEvent, sessionID, num))
+}
+
+// parseStoredEvent parses a stored event line, decrypting it as
+// openEventLine does. A session_created event must be encrypted exactly
+// when it says the session is.
+func parseStoredEvent(sessionID string, num int, line []byte, encrypted bool) (Event, error) {
+	plain, err := openEventLine(sessionID, num, line, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	event, err := ParseEvent(plain)
+	if err != nil {
+		return nil, err
+	}
+	if created, ok := event.(*SessionCreatedEvent); ok && created.Encrypted != isEncrypted(line) {
+		return nil, fmt.Errorf("session_created event doesn't match how the session is stored (encrypted: %t)", isEncrypted(line))
+	}
+	return event, nil
+}
+
+// isKeyError checks if an error means an encrypted session couldn't be read
+// for lack of the right key, rather than because it's damaged
+func isKeyError(err error) bool {
+	switch err.(type) {
+	case *errors.EncryptionKeyMissingError, *errors.EncryptionKeyMismatchError, *errors.InvalidEncryptionKeyError:
+		return true
+	}
+	return false
+}
+
+// eventError reports an error parsing line of a session log. Key errors are
+// returned as they are, with the session ID filled in; anything else means
+// the line is corrupt.
+func eventError(err error, sessionID string, line int) error {
+	if isKeyError(err) {
+		return withSessionID(err, sessionID)
+	}
+	return &errors.CorruptSessionError{SessionID: sessionID, Line: line, Reason: err.Error()}
+}
+
+// requireKey checks that a session's raw lines can be decrypted, if they're
+// encrypted, so integrity checks fail with a key error instead of reporting
+// every line as unreadable
+func requireKey(sessionID string, lines []RawLine) error {
+	for _, line := range lines {
+		if len(line.Data) == 0 {
+			continue
+		}
+		if _, err := openEventLine(sessionID, 1, line.Data, false); isKeyError(err) {
+			return withSessionID(err, sessionID)
+		}
+		return nil
+	}
+	return nil
+}
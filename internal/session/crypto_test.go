@@ -0,0 +1,342 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// useKey configures a fresh encryption key for the duration of a test,
+// returning it as stored in COUNCIL_KEY
+func useKey(t *testing.T) string {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv(KeyEnvVar, key)
+	SetKeyFile(filepath.Join(t.TempDir(), "key"))
+	t.Cleanup(func() { SetKeyFile("") })
+	return key
+}
+
+// switchKey replaces the configured key mid-test; "" removes it
+func switchKey(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(KeyEnvVar, key)
+	path, _ := KeyFilePath()
+	SetKeyFile(path)
+}
+
+func newEncryptedSession(t *testing.T) {
+	t.Helper()
+	opts := CreateOptions{Encrypt: true, Metadata: Metadata{Title: "Launch plan"}}
+	if err := CreateSessionWithOptions("sess", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	JoinSession("sess", "Alice")
+	if _, err := PostMessage("sess", "Alice", "the launch code is 1234", "Moderator", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEncryptedSessionRoundTrip(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			useKey(t)
+			newEncryptedSession(t)
+
+			lines, err := currentStore.ReadRaw("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, line := range lines {
+				if !isEncrypted(line.Data) {
+					t.Errorf("line %d is stored in plaintext: %s", line.Num, line.Data)
+				}
+				if bytes.Contains(line.Data, []byte("launch")) {
+					t.Errorf("line %d leaks its content", line.Num)
+				}
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sess.EventCount() != 4 || sess.Metadata.Title != "Launch plan" || !sess.IsActiveParticipant("Alice") {
+				t.Fatalf("unexpected session state: %+v", sess.State)
+			}
+			msg := sess.Events[3].(*MessageEvent)
+			if msg.Content != "the launch code is 1234" {
+				t.Errorf("unexpected content: %q", msg.Content)
+			}
+		})
+	}
+}
+
+func TestEncryptedLinesDecryptIndependently(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+
+	lines, _ := currentStore.ReadRaw("sess")
+	event, err := parseStoredEvent("sess", 4, lines[3].Data, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg, ok := event.(*MessageEvent); !ok || msg.Participant != "Alice" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestEncryptedSessionMissingKey(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+	switchKey(t, "")
+
+	_, err := LoadSession("sess")
+	missing, ok := err.(*errors.EncryptionKeyMissingError)
+	if !ok {
+		t.Fatalf("expected EncryptionKeyMissingError, got %T: %v", err, err)
+	}
+	if missing.SessionID != "sess" {
+		t.Errorf("expected session ID 'sess', got %q", missing.SessionID)
+	}
+
+	// The tail reader must not fall back to a plaintext index either
+	if _, err := LoadSessionAfter("sess", 3); !isKeyError(err) {
+		t.Errorf("expected a key error from LoadSessionAfter, got %v", err)
+	}
+	if _, err := CheckSession("sess"); !isKeyError(err) {
+		t.Errorf("expected a key error from CheckSession, got %v", err)
+	}
+	if _, err := PostMessage("sess", "Alice", "more", "Moderator", 4); !isKeyError(err) {
+		t.Errorf("expected a key error from PostMessage, got %v", err)
+	}
+}
+
+func TestEncryptedSessionWrongKey(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+
+	other, _ := GenerateKey()
+	switchKey(t, other)
+
+	_, err := LoadSession("sess")
+	if _, ok := err.(*errors.EncryptionKeyMismatchError); !ok {
+		t.Fatalf("expected EncryptionKeyMismatchError, got %T: %v", err, err)
+	}
+}
+
+func TestCreateEncryptedSessionWithoutKey(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	switchKey(t, "")
+
+	err := CreateSessionWithOptions("sess", CreateOptions{Encrypt: true})
+	if _, ok := err.(*errors.EncryptionKeyMissingError); !ok {
+		t.Fatalf("expected EncryptionKeyMissingError, got %T: %v", err, err)
+	}
+	if exists, _ := currentStore.Exists("sess"); exists {
+		t.Error("session should not have been created")
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	switchKey(t, "not a key")
+
+	err := CreateSessionWithOptions("sess", CreateOptions{Encrypt: true})
+	if _, ok := err.(*errors.InvalidEncryptionKeyError); !ok {
+		t.Fatalf("expected InvalidEncryptionKeyError, got %T: %v", err, err)
+	}
+}
+
+func TestEncryptedLineTamperDetected(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(string(data), "\n")
+	lines[3] = strings.Replace(lines[3], `"enc":"`, `"enc":"AAAA`, 1)
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+	os.Remove(filepath.Join(filepath.Dir(path), storage.IndexFile))
+
+	_, err := LoadSession("sess")
+	corrupt, ok := err.(*errors.CorruptSessionError)
+	if !ok {
+		t.Fatalf("expected CorruptSessionError, got %T: %v", err, err)
+	}
+	if corrupt.Line != 4 {
+		t.Errorf("expected line 4, got %d", corrupt.Line)
+	}
+}
+
+func TestEncryptedSessionRejectsPlaintextLine(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+
+	path, _ := storage.SessionEventsPath("sess")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"message","timestamp_millis":9,"participant":"Alice","content":"forged","next":"Moderator"}` + "\n")
+	f.Close()
+
+	_, err := LoadSessionAfter("sess", 4)
+	corrupt, ok := err.(*errors.CorruptSessionError)
+	if !ok || corrupt.Line != 5 || !strings.Contains(corrupt.Reason, "unencrypted event") {
+		t.Fatalf("expected the plaintext line to be rejected, got %T: %v", err, err)
+	}
+
+	report, err := CheckSession("sess")
+	if err != nil || len(report.Problems) != 1 || report.Problems[0].Line != 5 {
+		t.Errorf("expected fsck to report line 5, got %+v, %v", report, err)
+	}
+}
+
+func TestEncryptedLinesBoundToPosition(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			useKey(t)
+			newEncryptedSession(t)
+			if err := CreateSessionWithOptions("other", CreateOptions{Encrypt: true}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			JoinSession("other", "Alice")
+			if _, err := PostMessage("other", "Alice", "hello", "Moderator", 2); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lines, _ := currentStore.ReadRaw("sess")
+			others, _ := currentStore.ReadRaw("other")
+
+			// Each line opens only as its own event of its own session
+			if _, err := openEventLine("sess", 4, lines[3].Data, true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, moved := range []struct {
+				sessionID string
+				num       int
+				line      []byte
+			}{
+				{"sess", 3, lines[3].Data},
+				{"sess", 4, lines[2].Data},
+				{"sess", 3, others[2].Data},
+				{"other", 4, lines[3].Data},
+			} {
+				if _, err := openEventLine(moved.sessionID, moved.num, moved.line, true); err == nil {
+					t.Errorf("line opened as event #%d of %s", moved.num, moved.sessionID)
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptedCachesAreEncrypted(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+	for i := 0; i < snapshotInterval; i++ {
+		if _, err := PostMessage("sess", "Alice", "more", "Moderator", 4+i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := LoadSessionAfter("sess", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := sessionDirPath(t, "sess")
+	nums, _ := snapshotNums(dir)
+	if len(nums) == 0 {
+		t.Fatal("expected a snapshot")
+	}
+	for _, path := range []string{filepath.Join(dir, storage.IndexFile), snapshotPath(dir, nums[len(nums)-1])} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !isEncrypted(data) || bytes.Contains(data, []byte("Alice")) {
+			t.Errorf("%s is stored in plaintext", filepath.Base(path))
+		}
+	}
+
+	// Both caches must still be usable
+	snap, err := NewFileStore().LatestSnapshot("sess")
+	if err != nil || snap == nil || !snap.State.Participants["Alice"] {
+		t.Errorf("expected a usable snapshot, got %+v, %v", snap, err)
+	}
+	idx := loadIndex(dir)
+	if idx == nil || !idx.State.Encrypted {
+		t.Fatalf("expected a usable index, got %+v", idx)
+	}
+
+	// A plaintext index can't stand in for the encrypted one, whatever it
+	// claims about the session
+	for _, encrypted := range []bool{true, false} {
+		idx.State.Encrypted = encrypted
+		data, _ := json.Marshal(idx)
+		os.WriteFile(filepath.Join(dir, storage.IndexFile), data, 0644)
+		file, _ := os.Open(filepath.Join(dir, storage.EventsFile))
+		if forged := loadIndex(dir); forged != nil && forged.validFor(file) {
+			t.Errorf("plaintext index claiming encrypted=%t was used", encrypted)
+		}
+		file.Close()
+	}
+}
+
+func TestEncryptedChainAndFork(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	if err := CreateSessionWithOptions("sess", CreateOptions{Encrypt: true, HashChain: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	JoinSession("sess", "Alice")
+
+	report, err := VerifyChain("sess")
+	if err != nil || !report.Intact() || report.Events != 2 {
+		t.Fatalf("expected an intact chain of 2 events, got %+v, %v", report, err)
+	}
+
+	if err := ForkSession("sess", "child", 2, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines, _ := currentStore.ReadRaw("child")
+	for _, line := range lines {
+		if !isEncrypted(line.Data) {
+			t.Errorf("fork line %d is stored in plaintext", line.Num)
+		}
+	}
+}
+
+func TestListSessionsWithoutKey(t *testing.T) {
+	useFileStore(t)
+	useKey(t)
+	newEncryptedSession(t)
+	CreateSession("plain")
+	switchKey(t, "")
+
+	summaries, err := ListSessions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	noKey := map[string]bool{}
+	for _, s := range summaries {
+		noKey[s.ID] = s.NoKey
+	}
+	if !noKey["sess"] || noKey["plain"] {
+		t.Errorf("expected only 'sess' to be unreadable, got %v", noKey)
+	}
+}
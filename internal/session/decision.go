@@ -0,0 +1,386 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// Decision is a decision recorded in a session, and what later replaced it
+type Decision struct {
+	EventNum        int      `json:"event_num"`
+	TimestampMillis int64    `json:"timestamp_millis"`
+	Participant     string   `json:"participant"` // who recorded it
+	Title           string   `json:"title"`
+	Rationale       string   `json:"rationale"`
+	Alternatives    []string `json:"alternatives,omitempty"`
+	Supersedes      int      `json:"supersedes,omitempty"`
+	SupersededBy    int      `json:"superseded_by,omitempty"` // 0 while the decision stands
+}
+
+// DecisionSpec describes a decision to record
+type DecisionSpec struct {
+	Title        string
+	Rationale    string
+	Alternatives []string // options considered and not chosen
+	Supersedes   int      // event number of the decision this replaces; 0 for none
+}
+
+// addDecision records a decision made at event number eventNum, marking the
+// decision it supersedes
+func (s *State) addDecision(eventNum int, e *DecisionEvent) {
+	if s.Decisions == nil {
+		s.Decisions = make(map[int]*Decision)
+	}
+	s.Decisions[eventNum] = &Decision{
+		EventNum:        eventNum,
+		TimestampMillis: e.GetTimestamp(),
+		Participant:     e.Participant,
+		Title:           e.Title,
+		Rationale:       e.Rationale,
+		Alternatives:    e.Alternatives,
+		Supersedes:      e.Supersedes,
+	}
+	if old := s.Decisions[e.Supersedes]; old != nil {
+		old.SupersededBy = eventNum
+	}
+}
+
+// SortedDecisions returns the session's decisions in the order they were made
+func (s *State) SortedDecisions() []*Decision {
+	decisions := make([]*Decision, 0, len(s.Decisions))
+	for _, d := range s.Decisions {
+		decisions = append(decisions, d)
+	}
+	sort.Slice(decisions, func(i, j int) bool { return decisions[i].EventNum < decisions[j].EventNum })
+	return decisions
+}
+
+// normalized returns the spec with whitespace trimmed and empty alternatives
+// dropped
+func (spec DecisionSpec) normalized() (DecisionSpec, error) {
+	spec.Title = strings.TrimSpace(spec.Title)
+	if spec.Title == "" {
+		return spec, &errors.InvalidDecisionError{Reason: "the title is empty"}
+	}
+	spec.Rationale = strings.TrimSpace(spec.Rationale)
+	if spec.Rationale == "" {
+		return spec, &errors.InvalidDecisionError{Reason: "the rationale is empty"}
+	}
+
+	var alternatives []string
+	for _, alt := range spec.Alternatives {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			alternatives = append(alternatives, alt)
+		}
+	}
+	spec.Alternatives = alternatives
+	return spec, nil
+}
+
+// Decide records a decision in a session. Like posting, it's open to active
+// participants and the Moderator, and uses the same optimistic lock: the
+// session must still be at afterEventNum.
+// Returns the new event number (1-indexed for display), which later
+// decisions refer to when superseding it
+func Decide(sessionID, participant string, spec DecisionSpec, afterEventNum int) (int, error) {
+	session, err := LoadSession(sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Optimistic lock check (re-checked atomically by the store on append)
+	if session.EventCount() != afterEventNum {
+		return 0, &errors.StaleStateError{
+			ExpectedEventNum: afterEventNum,
+			ActualEventNum:   session.EventCount(),
+			SessionID:        sessionID,
+		}
+	}
+
+	if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+		return 0, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+	}
+	normalized, err := spec.normalized()
+	if err != nil {
+		return 0, err
+	}
+	if err := validSupersedes(session, normalized.Supersedes); err != nil {
+		return 0, err
+	}
+
+	if err := currentStore.Append(sessionID, afterEventNum, NewDecisionEvent(participant, normalized)); err != nil {
+		return 0, err
+	}
+	return afterEventNum + 1, nil
+}
+
+// validSupersedes checks that a decision can supersede the one at event
+// number supersedes: it must exist and still stand
+func validSupersedes(session *Session, supersedes int) error {
+	if supersedes == 0 {
+		return nil
+	}
+	old := session.Decisions[supersedes]
+	if old == nil {
+		return &errors.DecisionNotFoundError{SessionID: session.ID, EventNum: supersedes}
+	}
+	if old.SupersededBy != 0 {
+		return &errors.DecisionSupersededError{EventNum: supersedes, SupersededBy: old.SupersededBy}
+	}
+	return nil
+}
+
+// FormatDecisions generates the human-readable decision log. Superseded
+// decisions are struck out, with a pointer to what replaced them.
+func FormatDecisions(sess *Session) string {
+	decisions := sess.SortedDecisions()
+	if len(decisions) == 0 {
+		return fmt.Sprintf("No decisions in session %s. Record one with 'council decide'.\n", sess.ID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== Decisions: %s ===\n", sess.ID)
+	for _, d := range decisions {
+		b.WriteString("\n")
+		if d.SupersededBy != 0 {
+			fmt.Fprintf(&b, "~~#%d %s~~ (%s) superseded by #%d\n", d.EventNum, d.Title, d.Participant, d.SupersededBy)
+			continue
+		}
+		fmt.Fprintf(&b, "#%d %s (%s)\n", d.EventNum, d.Title, d.Participant)
+		fmt.Fprintf(&b, "  Rationale: %s\n", indentContinuation(d.Rationale, "  "))
+		if len(d.Alternatives) > 0 {
+			fmt.Fprintf(&b, "  Alternatives considered: %s\n", strings.Join(d.Alternatives, "; "))
+		}
+		if d.Supersedes != 0 {
+			fmt.Fprintf(&b, "  Supersedes: #%d\n", d.Supersedes)
+		}
+	}
+	return b.String()
+}
+
+// indentContinuation indents every line of s after the first
+func indentContinuation(s, indent string) string {
+	return strings.ReplaceAll(s, "\n", "\n"+indent)
+}
+
+// ADR is one decision rendered as an Architecture Decision Record
+type ADR struct {
+	Number   int    // sequential in the order decisions were made
+	FileName string // e.g. 0001-use-cursor-pagination.md
+	Content  string // Markdown
+	Exported bool   // the directory already has this decision's record
+}
+
+// adrMarker is the first line of every exported record. It identifies the
+// decision the record came from, so a later export of the same session finds
+// it instead of writing a second copy.
+func adrMarker(sessionID string, eventNum int) string {
+	return fmt.Sprintf("<!-- council-decision: %s#%d -->", sessionID, eventNum)
+}
+
+// parseADRMarker returns the session ID and event number in a record's marker
+// line
+func parseADRMarker(line string) (string, int, bool) {
+	inner, ok := strings.CutPrefix(strings.TrimSpace(line), "<!-- council-decision: ")
+	if !ok {
+		return "", 0, false
+	}
+	if inner, ok = strings.CutSuffix(inner, " -->"); !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndex(inner, "#")
+	if i < 0 {
+		return "", 0, false
+	}
+	eventNum, err := strconv.Atoi(inner[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return inner[:i], eventNum, true
+}
+
+// adrDir is what an ADR directory already holds: the highest record number,
+// and the records exported from one session by decision event number
+type adrDir struct {
+	highest  int
+	exported map[int]string // event number -> file name
+	numbers  map[int]int    // event number -> record number
+}
+
+// readADRDir scans the records (NNNN-*.md) in dir for ones exported from
+// sessionID. A missing directory is empty.
+func readADRDir(dir, sessionID string) (*adrDir, error) {
+	d := &adrDir{exported: map[int]string{}, numbers: map[int]int{}}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "-")
+		if !ok || len(prefix) < 4 || !strings.HasSuffix(entry.Name(), ".md") || entry.IsDir() {
+			continue
+		}
+		n, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+		d.highest = max(d.highest, n)
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		first, _, _ := strings.Cut(string(data), "\n")
+		if id, eventNum, ok := parseADRMarker(first); ok && id == sessionID {
+			d.exported[eventNum] = entry.Name()
+			d.numbers[eventNum] = n
+		}
+	}
+	return d, nil
+}
+
+// ADRs renders each of a session's decisions as an Architecture Decision
+// Record for the directory dir. Decisions exported there before keep their
+// record's number and file name; the rest are numbered in the order they
+// were made, after the highest-numbered record in dir. Supersession links
+// between decisions become links between the records.
+func ADRs(sess *Session, dir string) ([]ADR, error) {
+	existing, err := readADRDir(dir, sess.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ADR directory: %w", err)
+	}
+
+	decisions := sess.SortedDecisions()
+	files := map[int]string{}
+	numbers := map[int]int{}
+	next := existing.highest + 1
+	for _, d := range decisions {
+		if name, ok := existing.exported[d.EventNum]; ok {
+			files[d.EventNum] = name
+			numbers[d.EventNum] = existing.numbers[d.EventNum]
+			continue
+		}
+		numbers[d.EventNum] = next
+		files[d.EventNum] = fmt.Sprintf("%04d-%s.md", next, slugify(d.Title))
+		next++
+	}
+	link := func(eventNum int) string {
+		return fmt.Sprintf("[ADR %04d](%s)", numbers[eventNum], files[eventNum])
+	}
+
+	adrs := make([]ADR, 0, len(decisions))
+	for _, d := range decisions {
+		var b strings.Builder
+		b.WriteString(adrMarker(sess.ID, d.EventNum) + "\n")
+		fmt.Fprintf(&b, "# %d. %s\n\n", numbers[d.EventNum], d.Title)
+		fmt.Fprintf(&b, "Date: %s\n\n", time.UnixMilli(d.TimestampMillis).UTC().Format("2006-01-02"))
+
+		b.WriteString("## Status\n\n")
+		if d.SupersededBy != 0 {
+			fmt.Fprintf(&b, "Superseded by %s\n", link(d.SupersededBy))
+		} else {
+			b.WriteString("Accepted\n")
+		}
+		if d.Supersedes != 0 {
+			fmt.Fprintf(&b, "\nSupersedes %s\n", link(d.Supersedes))
+		}
+
+		b.WriteString("\n## Context\n\n")
+		fmt.Fprintf(&b, "Recorded by %s in council session `%s` (event #%d).\n", d.Participant, sess.ID, d.EventNum)
+		if sess.Metadata.Goal != "" {
+			fmt.Fprintf(&b, "\nSession goal: %s\n", sess.Metadata.Goal)
+		}
+
+		fmt.Fprintf(&b, "\n## Decision\n\n%s\n", d.Title)
+		fmt.Fprintf(&b, "\n## Rationale\n\n%s\n", d.Rationale)
+
+		if len(d.Alternatives) > 0 {
+			b.WriteString("\n## Alternatives Considered\n\n")
+			for _, alt := range d.Alternatives {
+				fmt.Fprintf(&b, "- %s\n", alt)
+			}
+		}
+
+		_, exported := existing.exported[d.EventNum]
+		adrs = append(adrs, ADR{
+			Number:   numbers[d.EventNum],
+			FileName: files[d.EventNum],
+			Content:  b.String(),
+			Exported: exported,
+		})
+	}
+	return adrs, nil
+}
+
+// WriteADRs writes records from ADRs into dir, creating it if needed. Records
+// the directory already has are left alone and returned as skipped, unless
+// update is set, in which case they're rewritten to match the session (for
+// example when a later decision superseded them). New records never replace
+// an existing file.
+func WriteADRs(dir string, adrs []ADR, update bool) (written, skipped []string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create ADR directory: %w", err)
+	}
+
+	for _, adr := range adrs {
+		path := filepath.Join(dir, adr.FileName)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if adr.Exported {
+			if !update {
+				skipped = append(skipped, path)
+				continue
+			}
+			flags = os.O_WRONLY | os.O_TRUNC
+		}
+		f, err := os.OpenFile(path, flags, 0644)
+		if os.IsExist(err) {
+			return written, skipped, fmt.Errorf("ADR %s already exists", path)
+		}
+		if err != nil {
+			return written, skipped, err
+		}
+		_, err = f.WriteString(adr.Content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, skipped, fmt.Errorf("failed to write ADR: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
+
+// slugify turns a title into a lowercase, hyphenated file name fragment
+func slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "decision"
+	}
+	return b.String()
+}
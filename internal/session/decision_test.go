@@ -0,0 +1,260 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newDecisionSession creates a session where Alice and Bob have joined, Alice
+// decided on offsets as event #4 and Bob superseded it with cursors as #5
+func newDecisionSession(t *testing.T) {
+	t.Helper()
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Bob")
+	if _, err := Decide("sess", "Alice", DecisionSpec{Title: "Use offsets", Rationale: "Simple"}, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := DecisionSpec{
+		Title:        "Use cursors",
+		Rationale:    "Offsets skip rows under concurrent inserts",
+		Alternatives: []string{"offsets", " ", "keyset"},
+		Supersedes:   4,
+	}
+	if _, err := Decide("sess", "Bob", spec, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newDecisionSession(t)
+
+			sess, err := LoadSessionAfter("sess", 5)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decisions := sess.SortedDecisions()
+			if len(decisions) != 2 {
+				t.Fatalf("expected 2 decisions, got %d", len(decisions))
+			}
+			if decisions[0].SupersededBy != 5 {
+				t.Errorf("expected #4 to be superseded by #5, got %+v", decisions[0])
+			}
+			if decisions[1].SupersededBy != 0 || strings.Join(decisions[1].Alternatives, ",") != "offsets,keyset" {
+				t.Errorf("unexpected standing decision: %+v", decisions[1])
+			}
+		})
+	}
+}
+
+func TestDecideRules(t *testing.T) {
+	useFileStore(t)
+	newDecisionSession(t)
+
+	valid := DecisionSpec{Title: "T", Rationale: "R"}
+	if _, err := Decide("sess", "Alice", valid, 4); !isErr[*errors.StaleStateError](err) {
+		t.Errorf("expected StaleStateError, got %T: %v", err, err)
+	}
+	if _, err := Decide("sess", "Carol", valid, 5); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, err := Decide("sess", "Alice", DecisionSpec{Title: "T"}, 5); !isErr[*errors.InvalidDecisionError](err) {
+		t.Errorf("expected InvalidDecisionError, got %T: %v", err, err)
+	}
+
+	superseding := valid
+	superseding.Supersedes = 3
+	if _, err := Decide("sess", "Alice", superseding, 5); !isErr[*errors.DecisionNotFoundError](err) {
+		t.Errorf("expected DecisionNotFoundError, got %T: %v", err, err)
+	}
+	superseding.Supersedes = 4
+	_, err := Decide("sess", "Alice", superseding, 5)
+	if superseded, ok := err.(*errors.DecisionSupersededError); !ok || superseded.SupersededBy != 5 {
+		t.Errorf("expected DecisionSupersededError, got %T: %v", err, err)
+	}
+
+	if _, err := Decide("sess", "Moderator", valid, 5); err != nil {
+		t.Errorf("the Moderator should be able to record decisions: %v", err)
+	}
+}
+
+func TestFormatDecisions(t *testing.T) {
+	useFileStore(t)
+	newDecisionSession(t)
+
+	sess, _ := LoadSession("sess")
+	out := FormatDecisions(sess)
+	for _, want := range []string{
+		"~~#4 Use offsets~~ (Alice) superseded by #5\n",
+		"#5 Use cursors (Bob)\n  Rationale: Offsets skip rows under concurrent inserts\n  Alternatives considered: offsets; keyset\n  Supersedes: #4\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("decisions should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Rationale: Simple") {
+		t.Errorf("superseded decisions should only be listed by title:\n%s", out)
+	}
+
+	status := FormatStatus(sess, 3)
+	if !strings.Contains(status, "--- #5 | Decision by Bob: Use cursors ---\nRationale: Offsets") {
+		t.Errorf("status should show the decision:\n%s", status)
+	}
+}
+
+func TestADRs(t *testing.T) {
+	useFileStore(t)
+	newDecisionSession(t)
+
+	sess, _ := LoadSession("sess")
+	adrs, err := ADRs(sess, filepath.Join(t.TempDir(), "adr"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adrs) != 2 || adrs[0].FileName != "0001-use-offsets.md" || adrs[1].FileName != "0002-use-cursors.md" {
+		t.Fatalf("unexpected ADRs: %+v", adrs)
+	}
+	if !strings.HasPrefix(adrs[0].Content, "<!-- council-decision: sess#4 -->\n# 1. Use offsets\n") {
+		t.Errorf("ADR should start with its marker:\n%s", adrs[0].Content)
+	}
+	if !strings.Contains(adrs[0].Content, "## Status\n\nSuperseded by [ADR 0002](0002-use-cursors.md)\n") {
+		t.Errorf("superseded ADR should link its replacement:\n%s", adrs[0].Content)
+	}
+	for _, want := range []string{
+		"# 2. Use cursors\n",
+		"Accepted\n\nSupersedes [ADR 0001](0001-use-offsets.md)\n",
+		"## Alternatives Considered\n\n- offsets\n- keyset\n",
+	} {
+		if !strings.Contains(adrs[1].Content, want) {
+			t.Errorf("ADR should contain %q:\n%s", want, adrs[1].Content)
+		}
+	}
+}
+
+func TestWriteADRs(t *testing.T) {
+	useFileStore(t)
+	newDecisionSession(t)
+	sess, _ := LoadSession("sess")
+
+	// Records of other sessions and hand-written ones push the numbering on
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0007-hand-written.md"), []byte("# 7. Mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	other := adrMarker("other", 4) + "\n# 3. Theirs\n"
+	if err := os.WriteFile(filepath.Join(dir, "0003-theirs.md"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	adrs, _ := ADRs(sess, dir)
+	written, skipped, err := WriteADRs(dir, adrs, false)
+	if err != nil || len(written) != 2 || len(skipped) != 0 || filepath.Base(written[0]) != "0008-use-offsets.md" {
+		t.Fatalf("unexpected result: %v, %v, %v", written, skipped, err)
+	}
+	data, _ := os.ReadFile(written[1])
+	if !strings.Contains(string(data), "Supersedes [ADR 0008](0008-use-offsets.md)") {
+		t.Errorf("links should use the continued numbers:\n%s", data)
+	}
+}
+
+func TestWriteADRsAgain(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	if _, err := Decide("sess", "Alice", DecisionSpec{Title: "Use offsets", Rationale: "Simple"}, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := t.TempDir()
+	sess, _ := LoadSession("sess")
+	adrs, _ := ADRs(sess, dir)
+	if _, _, err := WriteADRs(dir, adrs, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := filepath.Join(dir, "0001-use-offsets.md")
+	if err := os.WriteFile(first, []byte(adrMarker("sess", 3)+"\n# 1. Use offsets\n\nEdited by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later export only adds the new decision, and links the existing record
+	spec := DecisionSpec{Title: "Use cursors", Rationale: "Offsets skip rows", Supersedes: 3}
+	if _, err := Decide("sess", "Alice", spec, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sess, _ = LoadSession("sess")
+	adrs, _ = ADRs(sess, dir)
+	written, skipped, err := WriteADRs(dir, adrs, false)
+	if err != nil || len(written) != 1 || len(skipped) != 1 || skipped[0] != first {
+		t.Fatalf("unexpected result: %v, %v, %v", written, skipped, err)
+	}
+	if data, _ := os.ReadFile(written[0]); !strings.Contains(string(data), "Supersedes [ADR 0001](0001-use-offsets.md)") {
+		t.Errorf("new record should link the existing one:\n%s", data)
+	}
+	if data, _ := os.ReadFile(first); !strings.Contains(string(data), "Edited by hand") {
+		t.Errorf("existing record was rewritten without update:\n%s", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected 2 records, got %d", len(entries))
+	}
+
+	// Updating rewrites it to show it was superseded, under the same name
+	adrs, _ = ADRs(sess, dir)
+	written, skipped, err = WriteADRs(dir, adrs, true)
+	if err != nil || len(written) != 2 || len(skipped) != 0 {
+		t.Fatalf("unexpected result with update: %v, %v, %v", written, skipped, err)
+	}
+	if data, _ := os.ReadFile(first); !strings.Contains(string(data), "Superseded by [ADR 0002](0002-use-cursors.md)") {
+		t.Errorf("update should rewrite the record:\n%s", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected 2 records after updating, got %d", len(entries))
+	}
+}
+
+func TestParseADRMarker(t *testing.T) {
+	id, eventNum, ok := parseADRMarker(adrMarker("api-design", 14))
+	if !ok || id != "api-design" || eventNum != 14 {
+		t.Errorf("unexpected marker: %q, %d, %v", id, eventNum, ok)
+	}
+	for _, line := range []string{"# 1. Title", "<!-- council-decision: sess -->", "<!-- council-decision: sess#x -->"} {
+		if _, _, ok := parseADRMarker(line); ok {
+			t.Errorf("%q shouldn't parse as a marker", line)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	for title, want := range map[string]string{
+		"Use cursor pagination":  "use-cursor-pagination",
+		"  REST, not GraphQL!  ": "rest-not-graphql",
+		"???":                    "decision",
+	} {
+		if got := slugify(title); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestCheckSessionDecisions(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"decision","timestamp_millis":3,"participant":"Alice","title":"A","rationale":"R"}
+{"type":"decision","timestamp_millis":4,"participant":"Alice","title":"B","rationale":"R","supersedes":2}
+{"type":"decision","timestamp_millis":5,"participant":"Alice","title":"C","rationale":"R","supersedes":3}
+{"type":"decision","timestamp_millis":6,"participant":"Alice","title":"D","rationale":"R","supersedes":3}
+`)
+
+	report, _ := CheckSession("sess")
+	if len(report.Problems) != 2 || report.Problems[0].Line != 4 || report.Problems[1].Line != 6 {
+		t.Fatalf("expected problems on lines 4 and 6, got %+v", report.Problems)
+	}
+	if !strings.Contains(report.Problems[1].Message, "already superseded") {
+		t.Errorf("expected a superseded decision problem, got %q", report.Problems[1].Message)
+	}
+}
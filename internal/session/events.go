@@ -10,22 +10,42 @@ import (
 type EventType string
 
 const (
-	EventTypeSessionCreated EventType = "session_created"
-	EventTypeJoined         EventType = "joined"
-	EventTypeLeft           EventType = "left"
-	EventTypeMessage        EventType = "message"
+	EventTypeSessionCreated   EventType = "session_created"
+	EventTypeJoined           EventType = "joined"
+	EventTypeLeft             EventType = "left"
+	EventTypeMessage          EventType = "message"
+	EventTypeSessionUpdated   EventType = "session_updated"
+	EventTypeForkedFrom       EventType = "forked_from"
+	EventTypeForked           EventType = "forked"
+	EventTypePoll             EventType = "poll"
+	EventTypeVote             EventType = "vote"
+	EventTypeDecision         EventType = "decision"
+	EventTypeMessageEdited    EventType = "message_edited"
+	EventTypeMessageRetracted EventType = "message_retracted"
+	EventTypeArtifactAdded    EventType = "artifact_added"
+	EventTypeFactSet          EventType = "fact_set"
+	EventTypeFactDeleted      EventType = "fact_deleted"
+	EventTypeTaskAdded        EventType = "task_added"
+	EventTypeTaskAssigned     EventType = "task_assigned"
+	EventTypeTaskDone         EventType = "task_done"
 )
 
 // Event is the interface for all event types
 type Event interface {
 	GetType() EventType
 	GetTimestamp() int64
+	getPrevHash() string
 }
 
 // BaseEvent contains common fields for all events
 type BaseEvent struct {
 	Type            EventType `json:"type"`
+	Version         int       `json:"v,omitempty"` // schema version; 0 for events written before versioning
 	TimestampMillis int64     `json:"timestamp_millis"`
+
+	// PrevHash is the hash of the previous stored line in hash-chained
+	// sessions. It is set by the store when appending.
+	PrevHash string `json:"prev_hash,omitempty"`
 }
 
 func (e *BaseEvent) GetType() EventType {
@@ -36,10 +56,24 @@ func (e *BaseEvent) GetTimestamp() int64 {
 	return e.TimestampMillis
 }
 
+func (e *BaseEvent) getPrevHash() string {
+	return e.PrevHash
+}
+
+func (e *BaseEvent) setPrevHash(hash string) {
+	e.PrevHash = hash
+}
+
+func (e *BaseEvent) setVersion(version int) {
+	e.Version = version
+}
+
 // SessionCreatedEvent represents session creation
 type SessionCreatedEvent struct {
 	BaseEvent
-	ID string `json:"id"`
+	ID        string `json:"id"`
+	HashChain bool   `json:"hash_chain,omitempty"` // events carry the hash of the line before them
+	Encrypted bool   `json:"encrypted,omitempty"`  // events are stored encrypted
 }
 
 // JoinedEvent represents a participant joining
@@ -56,10 +90,135 @@ type LeftEvent struct {
 
 // MessageEvent represents a message posted
 type MessageEvent struct {
+	BaseEvent
+	Participant string   `json:"participant"`
+	Content     string   `json:"content"`
+	Next        string   `json:"next"`               // next suggested speaker
+	ReplyTo     int      `json:"reply_to,omitempty"` // event number of the message this replies to
+	Mentions    []string `json:"mentions,omitempty"` // participants @mentioned in the content, as on the roster
+}
+
+// SessionUpdatedEvent replaces the session's metadata. It carries the full
+// metadata rather than a diff, so the latest one always wins.
+type SessionUpdatedEvent struct {
+	BaseEvent
+	Metadata
+}
+
+// ForkedFromEvent records that a session was forked from another session.
+// With history, it follows copies of the parent's events 2..ParentEvent.
+type ForkedFromEvent struct {
+	BaseEvent
+	ParentSession string `json:"parent_session"`
+	ParentEvent   int    `json:"parent_event"`
+	WithHistory   bool   `json:"with_history"`
+}
+
+// ForkedEvent records in a parent session that a fork was created from it
+type ForkedEvent struct {
+	BaseEvent
+	ChildSession string `json:"child_session"`
+	AtEvent      int    `json:"at_event"`
+}
+
+// PollEvent opens a poll. Votes refer to it by its event number.
+type PollEvent struct {
+	BaseEvent
+	Participant string   `json:"participant"`
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	Secret      bool     `json:"secret,omitempty"`       // tallies don't show who voted for what
+	ClosesAfter int      `json:"closes_after,omitempty"` // last event number votes count at, 0 if never closes
+}
+
+// VoteEvent casts a participant's vote in a poll, replacing any earlier vote
+type VoteEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Poll        int    `json:"poll"` // event number of the poll
+	Choice      string `json:"choice"`
+}
+
+// DecisionEvent records a decision the session reached
+type DecisionEvent struct {
+	BaseEvent
+	Participant  string   `json:"participant"`
+	Title        string   `json:"title"`
+	Rationale    string   `json:"rationale"`
+	Alternatives []string `json:"alternatives,omitempty"` // options considered and not chosen
+	Supersedes   int      `json:"supersedes,omitempty"`   // event number of the decision this replaces
+}
+
+// MessageEditedEvent replaces the content of an earlier message. The message
+// keeps its event number; the original content stays in the log.
+type MessageEditedEvent struct {
+	BaseEvent
+	Participant string   `json:"participant"`
+	Message     int      `json:"message"` // event number of the message edited
+	Content     string   `json:"content"`
+	Mentions    []string `json:"mentions,omitempty"` // participants @mentioned in the new content, as on the roster
+}
+
+// MessageRetractedEvent withdraws an earlier message, which is then shown as
+// a tombstone. The message keeps its event number.
+type MessageRetractedEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Message     int    `json:"message"` // event number of the message retracted
+}
+
+// ArtifactAddedEvent records a file shared in the session. The content is
+// kept in the session's artifact store under its hash, not in the log.
+type ArtifactAddedEvent struct {
 	BaseEvent
 	Participant string `json:"participant"`
-	Content     string `json:"content"`
-	Next        string `json:"next"` // next suggested speaker
+	Name        string `json:"name"`
+	Size        int64  `json:"size"` // in bytes
+	Hash        string `json:"hash"` // "sha256:" and the hex digest of the content
+}
+
+// FactSetEvent sets a fact of the session's shared facts. FactVersion is the
+// fact's version after the change, one more than the version it replaced;
+// it's separate from the event schema version in BaseEvent.
+type FactSetEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	FactVersion int    `json:"fact_version"`
+}
+
+// FactDeletedEvent removes a fact. The deletion counts as a version of the
+// fact, so versions keep counting up if the fact is set again.
+type FactDeletedEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Key         string `json:"key"`
+	FactVersion int    `json:"fact_version"`
+}
+
+// TaskAddedEvent adds an action item to the session. The task is identified
+// by the event's number.
+type TaskAddedEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Description string `json:"description"`
+	Owner       string `json:"owner,omitempty"` // empty while unassigned
+}
+
+// TaskAssignedEvent hands the task at event Task to a new owner
+type TaskAssignedEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Task        int    `json:"task"`
+	Owner       string `json:"owner"`
+}
+
+// TaskDoneEvent marks the task at event Task as done
+type TaskDoneEvent struct {
+	BaseEvent
+	Participant string `json:"participant"`
+	Task        int    `json:"task"`
 }
 
 // Now returns the current timestamp in milliseconds
@@ -72,6 +231,7 @@ func NewSessionCreatedEvent(id string) *SessionCreatedEvent {
 	return &SessionCreatedEvent{
 		BaseEvent: BaseEvent{
 			Type:            EventTypeSessionCreated,
+			Version:         SchemaVersion,
 			TimestampMillis: Now(),
 		},
 		ID: id,
@@ -83,6 +243,7 @@ func NewJoinedEvent(participant string) *JoinedEvent {
 	return &JoinedEvent{
 		BaseEvent: BaseEvent{
 			Type:            EventTypeJoined,
+			Version:         SchemaVersion,
 			TimestampMillis: Now(),
 		},
 		Participant: participant,
@@ -94,6 +255,7 @@ func NewLeftEvent(participant string) *LeftEvent {
 	return &LeftEvent{
 		BaseEvent: BaseEvent{
 			Type:            EventTypeLeft,
+			Version:         SchemaVersion,
 			TimestampMillis: Now(),
 		},
 		Participant: participant,
@@ -105,6 +267,7 @@ func NewMessageEvent(participant, content, next string) *MessageEvent {
 	return &MessageEvent{
 		BaseEvent: BaseEvent{
 			Type:            EventTypeMessage,
+			Version:         SchemaVersion,
 			TimestampMillis: Now(),
 		},
 		Participant: participant,
@@ -113,17 +276,271 @@ func NewMessageEvent(participant, content, next string) *MessageEvent {
 	}
 }
 
+// NewSessionUpdatedEvent creates a new session_updated event
+func NewSessionUpdatedEvent(meta Metadata) *SessionUpdatedEvent {
+	return &SessionUpdatedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeSessionUpdated,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Metadata: meta,
+	}
+}
+
+// NewForkedFromEvent creates a new forked_from event
+func NewForkedFromEvent(parentSession string, parentEvent int, withHistory bool) *ForkedFromEvent {
+	return &ForkedFromEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeForkedFrom,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		ParentSession: parentSession,
+		ParentEvent:   parentEvent,
+		WithHistory:   withHistory,
+	}
+}
+
+// NewForkedEvent creates a new forked event
+func NewForkedEvent(childSession string, atEvent int) *ForkedEvent {
+	return &ForkedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeForked,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		ChildSession: childSession,
+		AtEvent:      atEvent,
+	}
+}
+
+// NewPollEvent creates a new poll event
+func NewPollEvent(participant string, spec PollSpec) *PollEvent {
+	return &PollEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypePoll,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Question:    spec.Question,
+		Options:     spec.Options,
+		Secret:      spec.Secret,
+		ClosesAfter: spec.ClosesAfter,
+	}
+}
+
+// NewVoteEvent creates a new vote event
+func NewVoteEvent(participant string, poll int, choice string) *VoteEvent {
+	return &VoteEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeVote,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Poll:        poll,
+		Choice:      choice,
+	}
+}
+
+// NewDecisionEvent creates a new decision event
+func NewDecisionEvent(participant string, spec DecisionSpec) *DecisionEvent {
+	return &DecisionEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeDecision,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant:  participant,
+		Title:        spec.Title,
+		Rationale:    spec.Rationale,
+		Alternatives: spec.Alternatives,
+		Supersedes:   spec.Supersedes,
+	}
+}
+
+// NewMessageEditedEvent creates a new message edited event
+func NewMessageEditedEvent(participant string, message int, content string) *MessageEditedEvent {
+	return &MessageEditedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeMessageEdited,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Message:     message,
+		Content:     content,
+	}
+}
+
+// NewMessageRetractedEvent creates a new message retracted event
+func NewMessageRetractedEvent(participant string, message int) *MessageRetractedEvent {
+	return &MessageRetractedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeMessageRetracted,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Message:     message,
+	}
+}
+
+// NewArtifactAddedEvent creates a new artifact added event
+func NewArtifactAddedEvent(participant, name string, size int64, hash string) *ArtifactAddedEvent {
+	return &ArtifactAddedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeArtifactAdded,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Name:        name,
+		Size:        size,
+		Hash:        hash,
+	}
+}
+
+// NewFactSetEvent creates a new fact set event
+func NewFactSetEvent(participant, key, value string, version int) *FactSetEvent {
+	return &FactSetEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeFactSet,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Key:         key,
+		Value:       value,
+		FactVersion: version,
+	}
+}
+
+// NewFactDeletedEvent creates a new fact deleted event
+func NewFactDeletedEvent(participant, key string, version int) *FactDeletedEvent {
+	return &FactDeletedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeFactDeleted,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Key:         key,
+		FactVersion: version,
+	}
+}
+
+// NewTaskAddedEvent creates a new task added event
+func NewTaskAddedEvent(participant, description, owner string) *TaskAddedEvent {
+	return &TaskAddedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeTaskAdded,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Description: description,
+		Owner:       owner,
+	}
+}
+
+// NewTaskAssignedEvent creates a new task assigned event
+func NewTaskAssignedEvent(participant string, task int, owner string) *TaskAssignedEvent {
+	return &TaskAssignedEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeTaskAssigned,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Task:        task,
+		Owner:       owner,
+	}
+}
+
+// NewTaskDoneEvent creates a new task done event
+func NewTaskDoneEvent(participant string, task int) *TaskDoneEvent {
+	return &TaskDoneEvent{
+		BaseEvent: BaseEvent{
+			Type:            EventTypeTaskDone,
+			Version:         SchemaVersion,
+			TimestampMillis: Now(),
+		},
+		Participant: participant,
+		Task:        task,
+	}
+}
+
+// RawEvent is an event of a type this version of council doesn't know, such
+// as one written by a newer version. It keeps the event's JSON so the event
+// can be displayed, and copied without losing fields.
+type RawEvent struct {
+	BaseEvent
+	Data json.RawMessage `json:"-"`
+}
+
+// MarshalJSON writes the original JSON with the common fields updated
+func (e *RawEvent) MarshalJSON() ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(e.Data, &fields); err != nil {
+		return nil, err
+	}
+
+	common, err := json.Marshal(e.BaseEvent)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "prev_hash")
+	if err := json.Unmarshal(common, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// Fields returns the event's type-specific fields, i.e. all but the common ones
+func (e *RawEvent) Fields() map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	json.Unmarshal(e.Data, &fields)
+	for _, name := range []string{"type", "v", "timestamp_millis", "prev_hash"} {
+		delete(fields, name)
+	}
+	return fields
+}
+
 // rawEvent is used for initial JSON parsing to determine event type
 type rawEvent struct {
-	Type EventType `json:"type"`
+	Type    EventType `json:"type"`
+	Version int       `json:"v"`
 }
 
-// ParseEvent parses a JSON line into the appropriate event type
+// ParseEvent parses a JSON line into the appropriate event type, verifying
+// its checksum if it has one. Encrypted lines must be decrypted first. Events
+// from older schema versions are upgraded to the current one; events of
+// unknown types are returned as *RawEvent.
 func ParseEvent(line []byte) (Event, error) {
+	if isEncrypted(line) {
+		return nil, fmt.Errorf("event is encrypted")
+	}
+	line, err := verifyChecksum(line)
+	if err != nil {
+		return nil, err
+	}
+
 	var raw rawEvent
 	if err := json.Unmarshal(line, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse event type: %w", err)
 	}
+	if raw.Type == "" {
+		return nil, fmt.Errorf("event has no type")
+	}
+
+	if raw.Version < SchemaVersion {
+		if line, err = upgradeEvent(line, raw.Version); err != nil {
+			return nil, fmt.Errorf("failed to upgrade %s event from schema version %d: %w", raw.Type, raw.Version, err)
+		}
+	}
 
 	var event Event
 	switch raw.Type {
@@ -151,8 +568,96 @@ func ParseEvent(line []byte) (Event, error) {
 			return nil, fmt.Errorf("failed to parse message event: %w", err)
 		}
 		event = &e
+	case EventTypeSessionUpdated:
+		var e SessionUpdatedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse session_updated event: %w", err)
+		}
+		event = &e
+	case EventTypeForkedFrom:
+		var e ForkedFromEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse forked_from event: %w", err)
+		}
+		event = &e
+	case EventTypeForked:
+		var e ForkedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse forked event: %w", err)
+		}
+		event = &e
+	case EventTypePoll:
+		var e PollEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse poll event: %w", err)
+		}
+		event = &e
+	case EventTypeVote:
+		var e VoteEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse vote event: %w", err)
+		}
+		event = &e
+	case EventTypeDecision:
+		var e DecisionEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse decision event: %w", err)
+		}
+		event = &e
+	case EventTypeMessageEdited:
+		var e MessageEditedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse message_edited event: %w", err)
+		}
+		event = &e
+	case EventTypeMessageRetracted:
+		var e MessageRetractedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse message_retracted event: %w", err)
+		}
+		event = &e
+	case EventTypeArtifactAdded:
+		var e ArtifactAddedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse artifact_added event: %w", err)
+		}
+		event = &e
+	case EventTypeFactSet:
+		var e FactSetEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse fact_set event: %w", err)
+		}
+		event = &e
+	case EventTypeFactDeleted:
+		var e FactDeletedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse fact_deleted event: %w", err)
+		}
+		event = &e
+	case EventTypeTaskAdded:
+		var e TaskAddedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse task_added event: %w", err)
+		}
+		event = &e
+	case EventTypeTaskAssigned:
+		var e TaskAssignedEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse task_assigned event: %w", err)
+		}
+		event = &e
+	case EventTypeTaskDone:
+		var e TaskDoneEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse task_done event: %w", err)
+		}
+		event = &e
 	default:
-		return nil, fmt.Errorf("unknown event type: %s", raw.Type)
+		e := RawEvent{Data: append(json.RawMessage(nil), line...)}
+		if err := json.Unmarshal(line, &e.BaseEvent); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", raw.Type, err)
+		}
+		event = &e
 	}
 
 	return event, nil
@@ -2,6 +2,8 @@ package session
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -96,6 +98,167 @@ func TestParseEventMessage(t *testing.T) {
 	if msg.Next != "Bob" {
 		t.Errorf("expected next 'Bob', got %q", msg.Next)
 	}
+	if msg.ReplyTo != 0 {
+		t.Errorf("expected no reply_to, got %d", msg.ReplyTo)
+	}
+
+	if msg.Mentions != nil {
+		t.Errorf("expected no mentions, got %v", msg.Mentions)
+	}
+
+	input = `{"type":"message","timestamp_millis":1234567890,"participant":"Bob","content":"Agreed, @Carol?","next":"Alice","reply_to":4,"mentions":["Carol"]}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reply := event.(*MessageEvent)
+	if reply.ReplyTo != 4 {
+		t.Errorf("expected reply_to 4, got %d", reply.ReplyTo)
+	}
+	if len(reply.Mentions) != 1 || reply.Mentions[0] != "Carol" {
+		t.Errorf("expected mentions [Carol], got %v", reply.Mentions)
+	}
+}
+
+func TestParseEventSessionUpdated(t *testing.T) {
+	input := `{"type":"session_updated","timestamp_millis":1234567890,"title":"Auth","goal":"Pick one","tags":["design","api"]}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, ok := event.(*SessionUpdatedEvent)
+	if !ok {
+		t.Fatalf("expected *SessionUpdatedEvent, got %T", event)
+	}
+	if updated.Title != "Auth" || updated.Goal != "Pick one" || len(updated.Tags) != 2 {
+		t.Errorf("unexpected metadata: %+v", updated.Metadata)
+	}
+}
+
+func TestParseEventForkedFrom(t *testing.T) {
+	input := `{"type":"forked_from","timestamp_millis":1234567890,"parent_session":"parent","parent_event":7,"with_history":true}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	forked, ok := event.(*ForkedFromEvent)
+	if !ok {
+		t.Fatalf("expected *ForkedFromEvent, got %T", event)
+	}
+	if forked.ParentSession != "parent" || forked.ParentEvent != 7 || !forked.WithHistory {
+		t.Errorf("unexpected fields: %+v", forked)
+	}
+}
+
+func TestParseEventForked(t *testing.T) {
+	input := `{"type":"forked","timestamp_millis":1234567890,"child_session":"child","at_event":7}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	forked, ok := event.(*ForkedEvent)
+	if !ok {
+		t.Fatalf("expected *ForkedEvent, got %T", event)
+	}
+	if forked.ChildSession != "child" || forked.AtEvent != 7 {
+		t.Errorf("unexpected fields: %+v", forked)
+	}
+}
+
+func TestParseEventPollAndVote(t *testing.T) {
+	input := `{"type":"poll","timestamp_millis":1234567890,"participant":"Alice","question":"Ship?","options":["yes","no"],"secret":true,"closes_after":9}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	poll, ok := event.(*PollEvent)
+	if !ok {
+		t.Fatalf("expected *PollEvent, got %T", event)
+	}
+	if poll.Question != "Ship?" || len(poll.Options) != 2 || !poll.Secret || poll.ClosesAfter != 9 {
+		t.Errorf("unexpected fields: %+v", poll)
+	}
+
+	input = `{"type":"vote","timestamp_millis":1234567890,"participant":"Bob","poll":4,"choice":"yes"}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vote, ok := event.(*VoteEvent)
+	if !ok {
+		t.Fatalf("expected *VoteEvent, got %T", event)
+	}
+	if vote.Participant != "Bob" || vote.Poll != 4 || vote.Choice != "yes" {
+		t.Errorf("unexpected fields: %+v", vote)
+	}
+}
+
+func TestParseEventDecision(t *testing.T) {
+	input := `{"type":"decision","timestamp_millis":1234567890,"participant":"Alice","title":"Use cursors","rationale":"Stable","alternatives":["offsets"],"supersedes":4}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decision, ok := event.(*DecisionEvent)
+	if !ok {
+		t.Fatalf("expected *DecisionEvent, got %T", event)
+	}
+	if decision.Title != "Use cursors" || decision.Rationale != "Stable" || len(decision.Alternatives) != 1 || decision.Supersedes != 4 {
+		t.Errorf("unexpected fields: %+v", decision)
+	}
+}
+
+func TestParseEventMessageEditedAndRetracted(t *testing.T) {
+	input := `{"type":"message_edited","timestamp_millis":1234567890,"participant":"Alice","message":4,"content":"Fixed"}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	edited, ok := event.(*MessageEditedEvent)
+	if !ok {
+		t.Fatalf("expected *MessageEditedEvent, got %T", event)
+	}
+	if edited.Participant != "Alice" || edited.Message != 4 || edited.Content != "Fixed" {
+		t.Errorf("unexpected fields: %+v", edited)
+	}
+
+	input = `{"type":"message_retracted","timestamp_millis":1234567890,"participant":"Moderator","message":4}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retracted, ok := event.(*MessageRetractedEvent)
+	if !ok {
+		t.Fatalf("expected *MessageRetractedEvent, got %T", event)
+	}
+	if retracted.Participant != "Moderator" || retracted.Message != 4 {
+		t.Errorf("unexpected fields: %+v", retracted)
+	}
+}
+
+func TestParseEventArtifactAdded(t *testing.T) {
+	input := `{"type":"artifact_added","timestamp_millis":1234567890,"participant":"Alice","name":"plan.md","size":42,"hash":"sha256:abc"}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	artifact, ok := event.(*ArtifactAddedEvent)
+	if !ok {
+		t.Fatalf("expected *ArtifactAddedEvent, got %T", event)
+	}
+	if artifact.Participant != "Alice" || artifact.Name != "plan.md" || artifact.Size != 42 || artifact.Hash != "sha256:abc" {
+		t.Errorf("unexpected fields: %+v", artifact)
+	}
 }
 
 func TestParseEventInvalidJSON(t *testing.T) {
@@ -108,11 +271,27 @@ func TestParseEventInvalidJSON(t *testing.T) {
 }
 
 func TestParseEventUnknownType(t *testing.T) {
-	input := `{"type":"unknown","timestamp_millis":1234567890}`
+	input := `{"type":"unknown","timestamp_millis":1234567890,"question":"ship?"}`
 
-	_, err := ParseEvent([]byte(input))
-	if err == nil {
-		t.Error("expected error for unknown event type")
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, ok := event.(*RawEvent)
+	if !ok {
+		t.Fatalf("expected *RawEvent, got %T", event)
+	}
+	if raw.GetType() != "unknown" || raw.GetTimestamp() != 1234567890 {
+		t.Errorf("unexpected common fields: %+v", raw.BaseEvent)
+	}
+	if string(raw.Fields()["question"]) != `"ship?"` {
+		t.Errorf("expected question field to be kept, got %v", raw.Fields())
+	}
+}
+
+func TestParseEventMissingType(t *testing.T) {
+	if _, err := ParseEvent([]byte(`{"timestamp_millis":1}`)); err == nil {
+		t.Error("expected error for event without a type")
 	}
 }
 
@@ -249,3 +428,80 @@ func TestNewEventConstructors(t *testing.T) {
 		}
 	})
 }
+
+func TestParseEventFacts(t *testing.T) {
+	input := `{"type":"fact_set","timestamp_millis":1234567890,"participant":"Alice","key":"region","value":"us-east-1","fact_version":2}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	set, ok := event.(*FactSetEvent)
+	if !ok {
+		t.Fatalf("expected *FactSetEvent, got %T", event)
+	}
+	if set.Participant != "Alice" || set.Key != "region" || set.Value != "us-east-1" || set.FactVersion != 2 {
+		t.Errorf("unexpected fields: %+v", set)
+	}
+
+	input = `{"type":"fact_deleted","timestamp_millis":1234567890,"participant":"Bob","key":"region","fact_version":3}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deleted, ok := event.(*FactDeletedEvent)
+	if !ok {
+		t.Fatalf("expected *FactDeletedEvent, got %T", event)
+	}
+	if deleted.Participant != "Bob" || deleted.Key != "region" || deleted.FactVersion != 3 {
+		t.Errorf("unexpected fields: %+v", deleted)
+	}
+
+	// The fact's version is separate from the event's schema version
+	data, _ := json.Marshal(NewFactSetEvent("Alice", "region", "us", 4))
+	if !strings.Contains(string(data), fmt.Sprintf(`"v":%d,`, SchemaVersion)) || !strings.Contains(string(data), `"fact_version":4`) {
+		t.Errorf("expected both versions in %s", data)
+	}
+}
+
+func TestParseEventTasks(t *testing.T) {
+	input := `{"type":"task_added","timestamp_millis":1234567890,"participant":"Alice","description":"Write docs","owner":"Bob"}`
+
+	event, err := ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, ok := event.(*TaskAddedEvent)
+	if !ok {
+		t.Fatalf("expected *TaskAddedEvent, got %T", event)
+	}
+	if added.Participant != "Alice" || added.Description != "Write docs" || added.Owner != "Bob" {
+		t.Errorf("unexpected fields: %+v", added)
+	}
+
+	input = `{"type":"task_assigned","timestamp_millis":1234567890,"participant":"Alice","task":4,"owner":"Carol"}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assigned, ok := event.(*TaskAssignedEvent)
+	if !ok {
+		t.Fatalf("expected *TaskAssignedEvent, got %T", event)
+	}
+	if assigned.Task != 4 || assigned.Owner != "Carol" {
+		t.Errorf("unexpected fields: %+v", assigned)
+	}
+
+	input = `{"type":"task_done","timestamp_millis":1234567890,"participant":"Bob","task":4}`
+	event, err = ParseEvent([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done, ok := event.(*TaskDoneEvent)
+	if !ok {
+		t.Fatalf("expected *TaskDoneEvent, got %T", event)
+	}
+	if done.Participant != "Bob" || done.Task != 4 {
+		t.Errorf("unexpected fields: %+v", done)
+	}
+}
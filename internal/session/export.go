@@ -0,0 +1,395 @@
+package session
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	ExportMarkdown = "md"
+	ExportHTML     = "html"
+	ExportJSON     = "json"
+	ExportCSV      = "csv"
+)
+
+// ExportFormats lists the supported export formats, which double as file extensions
+var ExportFormats = []string{ExportMarkdown, ExportHTML, ExportJSON, ExportCSV}
+
+// Transcript is a session prepared for export: its metadata, everyone who
+// took part, the tasks it produced, and its events in order with notices
+// for non-message events
+type Transcript struct {
+	SessionID     string             `json:"session_id"`
+	Metadata      Metadata           `json:"metadata"`
+	CreatedMillis int64              `json:"created_millis"`
+	ForkedFrom    *ForkOrigin        `json:"forked_from,omitempty"`
+	Participants  []TranscriptMember `json:"participants"`
+	Tasks         []*Task            `json:"tasks"` // in the order they were added
+	Entries       []TranscriptEntry  `json:"events"`
+}
+
+// TranscriptMember is a participant who joined the session at some point
+type TranscriptMember struct {
+	Name         string `json:"name"`
+	JoinedMillis int64  `json:"joined_millis"`         // when they first joined
+	LeftMillis   int64  `json:"left_millis,omitempty"` // when they last left, 0 if still active
+}
+
+// TranscriptEntry is one event of a transcript. Messages have a participant
+// and content; other events have a notice describing them.
+type TranscriptEntry struct {
+	Number          int       `json:"number"`
+	Type            EventType `json:"type"`
+	TimestampMillis int64     `json:"timestamp_millis"`
+	Participant     string    `json:"participant,omitempty"`
+	Content         string    `json:"content,omitempty"`
+	Next            string    `json:"next,omitempty"`
+	ReplyTo         int       `json:"reply_to,omitempty"`
+	Mentions        []string  `json:"mentions,omitempty"`
+	Edited          bool      `json:"edited,omitempty"`    // content is the latest edit
+	Retracted       bool      `json:"retracted,omitempty"` // content is a tombstone
+	Notice          string    `json:"notice,omitempty"`
+}
+
+// IsMessage checks if the entry is a message rather than a notice
+func (e TranscriptEntry) IsMessage() bool {
+	return e.Type == EventTypeMessage
+}
+
+// NewTranscript builds the transcript of a fully loaded session. Messages
+// appear as revised by later edits and retractions.
+func NewTranscript(sess *Session) *Transcript {
+	t := &Transcript{
+		SessionID:    sess.ID,
+		Metadata:     sess.Metadata,
+		ForkedFrom:   sess.ForkedFrom,
+		Participants: []TranscriptMember{},
+		Tasks:        sess.SortedTasks(),
+		Entries:      []TranscriptEntry{},
+	}
+
+	members := map[string]*TranscriptMember{}
+	revisions := sess.Revisions()
+	for i, event := range sess.Events {
+		entry := TranscriptEntry{
+			Number:          sess.Offset + i + 1,
+			Type:            event.GetType(),
+			TimestampMillis: event.GetTimestamp(),
+		}
+
+		switch e := event.(type) {
+		case *SessionCreatedEvent:
+			t.CreatedMillis = e.GetTimestamp()
+			continue
+		case *JoinedEvent:
+			if e.Participant == "Moderator" {
+				continue
+			}
+			if m, ok := members[e.Participant]; ok {
+				m.LeftMillis = 0
+			} else {
+				members[e.Participant] = &TranscriptMember{Name: e.Participant, JoinedMillis: e.GetTimestamp()}
+			}
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s joined", e.Participant)
+		case *LeftEvent:
+			if m, ok := members[e.Participant]; ok {
+				m.LeftMillis = e.GetTimestamp()
+			}
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s left", e.Participant)
+		case *MessageEvent:
+			entry.Participant = e.Participant
+			entry.Content = e.Content
+			entry.Next = e.Next
+			entry.ReplyTo = e.ReplyTo
+			entry.Mentions = e.Mentions
+			if r := revisions[entry.Number]; r.Retracted() {
+				entry.Content = r.Tombstone()
+				entry.Mentions = nil
+				entry.Retracted = true
+			} else if r != nil {
+				entry.Content = r.Content
+				entry.Edited = true
+			}
+		case *MessageEditedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s edited #%d", e.Participant, e.Message)
+		case *MessageRetractedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s retracted #%d", e.Participant, e.Message)
+		case *ArtifactAddedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s added artifact %s (%s, %s)", e.Participant, e.Name, FormatSize(e.Size), shortHash(e.Hash))
+		case *FactSetEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s set fact %s = %s (v%d)", e.Participant, e.Key, e.Value, e.FactVersion)
+		case *FactDeletedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s deleted fact %s (v%d)", e.Participant, e.Key, e.FactVersion)
+		case *TaskAddedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s added task #%d: %s", e.Participant, entry.Number, e.Description)
+			if e.Owner != "" {
+				entry.Notice += fmt.Sprintf(" (owner: %s)", e.Owner)
+			}
+		case *TaskAssignedEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s assigned task #%d to %s", e.Participant, e.Task, e.Owner)
+		case *TaskDoneEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s completed task #%d", e.Participant, e.Task)
+		case *SessionUpdatedEvent:
+			entry.Notice = "Session details updated"
+		case *PollEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s opened a poll: %s (%s)", e.Participant, e.Question, strings.Join(e.Options, " / "))
+		case *VoteEvent:
+			entry.Participant = e.Participant
+			if poll := sess.Polls[e.Poll]; poll != nil && poll.Secret {
+				entry.Notice = fmt.Sprintf("%s voted in poll #%d", e.Participant, e.Poll)
+			} else {
+				entry.Notice = fmt.Sprintf("%s voted %s in poll #%d", e.Participant, e.Choice, e.Poll)
+			}
+		case *DecisionEvent:
+			entry.Participant = e.Participant
+			entry.Notice = fmt.Sprintf("%s decided: %s", e.Participant, e.Title)
+			if e.Supersedes != 0 {
+				entry.Notice += fmt.Sprintf(" (supersedes #%d)", e.Supersedes)
+			}
+		case *ForkedFromEvent:
+			entry.Notice = fmt.Sprintf("Forked from %s at #%d", e.ParentSession, e.ParentEvent)
+		case *ForkedEvent:
+			entry.Notice = fmt.Sprintf("Forked to %s at #%d", e.ChildSession, e.AtEvent)
+		case *RawEvent:
+			entry.Notice = fmt.Sprintf("%s (unknown event type)", e.GetType())
+			if fields := e.Fields(); len(fields) > 0 {
+				data, _ := json.Marshal(fields)
+				entry.Notice += ": " + string(data)
+			}
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+
+	for _, m := range members {
+		t.Participants = append(t.Participants, *m)
+	}
+	sort.Slice(t.Participants, func(i, j int) bool {
+		a, b := t.Participants[i], t.Participants[j]
+		if a.JoinedMillis != b.JoinedMillis {
+			return a.JoinedMillis < b.JoinedMillis
+		}
+		return a.Name < b.Name
+	})
+
+	return t
+}
+
+// Title returns the session's title, or a title made from its ID
+func (t *Transcript) Title() string {
+	if t.Metadata.Title != "" {
+		return t.Metadata.Title
+	}
+	return "Session " + t.SessionID
+}
+
+// Export writes a session's transcript in one of ExportFormats
+func Export(w io.Writer, sess *Session, format string) error {
+	t := NewTranscript(sess)
+	switch format {
+	case ExportMarkdown:
+		return t.writeMarkdown(w)
+	case ExportHTML:
+		return t.writeHTML(w)
+	case ExportJSON:
+		return t.writeJSON(w)
+	case ExportCSV:
+		return t.writeCSV(w)
+	default:
+		return fmt.Errorf("unknown export format '%s'. Use %s.", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// formatExportTime formats a timestamp for display in exports. Exports are
+// shared, so they use UTC rather than the exporter's time zone.
+func formatExportTime(millis int64) string {
+	return time.UnixMilli(millis).UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+func (t *Transcript) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", t.Title())
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", t.SessionID)
+	fmt.Fprintf(&b, "- **Created:** %s\n", formatExportTime(t.CreatedMillis))
+	if t.Metadata.Goal != "" {
+		fmt.Fprintf(&b, "- **Goal:** %s\n", t.Metadata.Goal)
+	}
+	if len(t.Metadata.Tags) > 0 {
+		fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(t.Metadata.Tags, ", "))
+	}
+	if t.ForkedFrom != nil {
+		fmt.Fprintf(&b, "- **Forked from:** `%s` at #%d\n", t.ForkedFrom.SessionID, t.ForkedFrom.EventNum)
+	}
+	if t.Metadata.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", t.Metadata.Description)
+	}
+
+	b.WriteString("\n## Participants\n\n")
+	if len(t.Participants) == 0 {
+		b.WriteString("No one has joined.\n")
+	} else {
+		b.WriteString("| Participant | Joined | Left |\n")
+		b.WriteString("|-------------|--------|------|\n")
+		for _, m := range t.Participants {
+			left := "—"
+			if m.LeftMillis > 0 {
+				left = formatExportTime(m.LeftMillis)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", strings.ReplaceAll(m.Name, "|", `\|`), formatExportTime(m.JoinedMillis), left)
+		}
+	}
+
+	if len(t.Tasks) > 0 {
+		b.WriteString("\n## Tasks\n\n")
+		for _, task := range t.Tasks {
+			marker := "[ ]"
+			if task.Done() {
+				marker = "[x]"
+			}
+			fmt.Fprintf(&b, "- %s %s\n", marker, task.Summary())
+		}
+	}
+
+	b.WriteString("\n## Transcript\n")
+	for _, e := range t.Entries {
+		if !e.IsMessage() {
+			fmt.Fprintf(&b, "\n*#%d · %s · %s*\n", e.Number, formatExportTime(e.TimestampMillis), e.Notice)
+			continue
+		}
+		edited := ""
+		if e.Edited {
+			edited = " (edited)"
+		}
+		fmt.Fprintf(&b, "\n### #%d · %s%s\n\n", e.Number, e.Participant, edited)
+		fmt.Fprintf(&b, "*%s*\n\n", formatExportTime(e.TimestampMillis))
+		b.WriteString(strings.TrimRight(e.Content, "\n"))
+		b.WriteString("\n")
+		if e.Next != "" {
+			fmt.Fprintf(&b, "\n→ Next: **%s**\n", e.Next)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (t *Transcript) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+func (t *Transcript) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"number", "timestamp", "type", "participant", "content", "next", "notice"})
+	for _, e := range t.Entries {
+		cw.Write([]string{
+			strconv.Itoa(e.Number),
+			time.UnixMilli(e.TimestampMillis).UTC().Format(time.RFC3339),
+			string(e.Type),
+			e.Participant,
+			e.Content,
+			e.Next,
+			e.Notice,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (t *Transcript) writeHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, t)
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": formatExportTime,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #111827; line-height: 1.5; }
+h1 { margin-bottom: 0.25rem; }
+.meta { color: #4b5563; margin: 0.125rem 0; }
+table { border-collapse: collapse; margin: 0.5rem 0 1.5rem; }
+th, td { border: 1px solid #e5e7eb; padding: 0.25rem 0.75rem; text-align: left; font-size: 0.875rem; }
+.notice { color: #6b7280; font-size: 0.875rem; text-align: center; margin: 0.75rem 0; }
+.message { border: 1px solid #e5e7eb; border-radius: 0.5rem; padding: 0.75rem 1rem; margin: 0.75rem 0; }
+.message header { display: flex; justify-content: space-between; font-size: 0.875rem; margin-bottom: 0.5rem; }
+.message header time, .next { color: #6b7280; }
+.content { white-space: pre-wrap; word-wrap: break-word; }
+.next { font-size: 0.875rem; margin-top: 0.5rem; }
+.tasks { list-style: none; padding-left: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Session <code>{{.SessionID}}</code>, created {{time .CreatedMillis}}</p>
+{{- with .Metadata.Goal}}
+<p class="meta"><strong>Goal:</strong> {{.}}</p>
+{{- end}}
+{{- with .Metadata.Tags}}
+<p class="meta"><strong>Tags:</strong> {{range $i, $tag := .}}{{if $i}}, {{end}}{{$tag}}{{end}}</p>
+{{- end}}
+{{- with .ForkedFrom}}
+<p class="meta">Forked from <code>{{.SessionID}}</code> at #{{.EventNum}}</p>
+{{- end}}
+{{- with .Metadata.Description}}
+<p>{{.}}</p>
+{{- end}}
+<h2>Participants</h2>
+{{- if .Participants}}
+<table>
+<tr><th>Participant</th><th>Joined</th><th>Left</th></tr>
+{{- range .Participants}}
+<tr><td>{{.Name}}</td><td>{{time .JoinedMillis}}</td><td>{{if .LeftMillis}}{{time .LeftMillis}}{{else}}—{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No one has joined.</p>
+{{- end}}
+{{- with .Tasks}}
+<h2>Tasks</h2>
+<ul class="tasks">
+{{- range .}}
+<li><input type="checkbox" disabled{{if .Done}} checked{{end}}> {{.Summary}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>Transcript</h2>
+{{- range .Entries}}
+{{- if .IsMessage}}
+<article class="message" id="event-{{.Number}}">
+<header><strong>#{{.Number}} · {{.Participant}}{{if .Edited}} (edited){{end}}</strong><time>{{time .TimestampMillis}}</time></header>
+<div class="content">{{.Content}}</div>
+{{- with .Next}}
+<div class="next">→ Next: <strong>{{.}}</strong></div>
+{{- end}}
+</article>
+{{- else}}
+<p class="notice" id="event-{{.Number}}">#{{.Number}} · {{time .TimestampMillis}} · {{.Notice}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
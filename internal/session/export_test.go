@@ -0,0 +1,171 @@
+package session
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newExportSession(t *testing.T) *Session {
+	t.Helper()
+	useFileStore(t)
+	opts := CreateOptions{Metadata: Metadata{Title: "API review", Goal: "Pick a pagination style"}}
+	if err := CreateSessionWithOptions("sess", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Bob")
+	PostMessage("sess", "Alice", "Cursors, <b>please</b>", "Bob", 4)
+	PostMessage("sess", "Bob", "Agreed,\n\"ship\" it", "", 5)
+	LeaveSession("sess", "Bob")
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return sess
+}
+
+func export(t *testing.T, sess *Session, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Export(&buf, sess, format); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestNewTranscript(t *testing.T) {
+	tr := NewTranscript(newExportSession(t))
+
+	if tr.Title() != "API review" || tr.CreatedMillis == 0 {
+		t.Errorf("unexpected header: %q, created %d", tr.Title(), tr.CreatedMillis)
+	}
+	if len(tr.Participants) != 2 || tr.Participants[0].Name != "Alice" || tr.Participants[1].Name != "Bob" {
+		t.Fatalf("expected Alice and Bob in join order, got %+v", tr.Participants)
+	}
+	if tr.Participants[0].LeftMillis != 0 || tr.Participants[1].LeftMillis == 0 {
+		t.Errorf("expected only Bob to have left, got %+v", tr.Participants)
+	}
+
+	// session_created is summarized in the header rather than listed
+	if len(tr.Entries) != 6 || tr.Entries[0].Number != 2 {
+		t.Fatalf("expected events #2 to #7, got %+v", tr.Entries)
+	}
+	if e := tr.Entries[3]; !e.IsMessage() || e.Participant != "Alice" || e.Next != "Bob" {
+		t.Errorf("unexpected message entry: %+v", e)
+	}
+	if e := tr.Entries[5]; e.IsMessage() || e.Notice != "Bob left" {
+		t.Errorf("unexpected notice entry: %+v", e)
+	}
+}
+
+func TestTranscriptRejoin(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	LeaveSession("sess", "Alice")
+	JoinSession("sess", "Alice")
+	sess, _ := LoadSession("sess")
+
+	tr := NewTranscript(sess)
+	if len(tr.Participants) != 1 || tr.Participants[0].LeftMillis != 0 {
+		t.Errorf("expected Alice listed once and still active, got %+v", tr.Participants)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	out := export(t, newExportSession(t), ExportMarkdown)
+
+	for _, want := range []string{
+		"# API review\n",
+		"- **Goal:** Pick a pagination style\n",
+		"| Alice | ",
+		"### #5 · Alice\n",
+		"Cursors, <b>please</b>\n",
+		"→ Next: **Bob**",
+		"· Bob left*",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportMarkdownEdited(t *testing.T) {
+	newExportSession(t)
+	if _, err := EditMessage("sess", "Alice", 5, "Cursors, on reflection"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sess, err := LoadHistory("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := export(t, sess, ExportMarkdown)
+	if !strings.Contains(out, "### #5 · Alice (edited)\n") || !strings.Contains(out, "Cursors, on reflection\n") {
+		t.Errorf("markdown should show the edit:\n%s", out)
+	}
+	if !strings.Contains(out, "### #6 · Bob\n") {
+		t.Errorf("only the edited message should be marked:\n%s", out)
+	}
+
+	// The marker isn't part of the speaker's name when imported back
+	if parsed := parse(t, out, ImportMarkdown); parsed.Messages[0].Speaker != "Alice" {
+		t.Errorf("expected speaker Alice, got %q", parsed.Messages[0].Speaker)
+	}
+}
+
+func TestExportHTMLEscapes(t *testing.T) {
+	out := export(t, newExportSession(t), ExportHTML)
+
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Errorf("expected a standalone HTML document")
+	}
+	if strings.Contains(out, "<b>please</b>") || !strings.Contains(out, "&lt;b&gt;please&lt;/b&gt;") {
+		t.Errorf("message content should be escaped:\n%s", out)
+	}
+	if !strings.Contains(out, `id="event-5"`) {
+		t.Errorf("events should be linkable by number")
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	out := export(t, newExportSession(t), ExportJSON)
+
+	var tr Transcript
+	if err := json.Unmarshal([]byte(out), &tr); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if tr.SessionID != "sess" || len(tr.Entries) != 6 || tr.Entries[4].Content != "Agreed,\n\"ship\" it" {
+		t.Errorf("unexpected transcript: %+v", tr)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	out := export(t, newExportSession(t), ExportCSV)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 7 || records[0][0] != "number" {
+		t.Fatalf("expected a header and 6 rows, got %d records", len(records))
+	}
+	if row := records[5]; row[0] != "6" || row[3] != "Bob" || row[4] != "Agreed,\n\"ship\" it" {
+		t.Errorf("unexpected row: %q", row)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	sess, _ := LoadSession("sess")
+
+	err := Export(&bytes.Buffer{}, sess, "pdf")
+	if err == nil || !strings.Contains(err.Error(), "md, html, json, csv") {
+		t.Errorf("expected an error listing the formats, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// factKeyPattern matches fact keys: a letter or digit, then letters, digits
+// and a few separators, e.g. "db.max_connections" or "launch-date"
+var factKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/-]*$`)
+
+const (
+	// maxFactKeyLength is the longest fact key allowed, in bytes
+	maxFactKeyLength = 64
+	// maxFactValueLength is the longest fact value allowed, in bytes. Facts
+	// are shown in every status header, so longer content belongs in an
+	// artifact.
+	maxFactValueLength = 500
+)
+
+// Fact is the latest version of a key in the session's shared facts
+type Fact struct {
+	Key             string `json:"key"`
+	Value           string `json:"value"`
+	Version         int    `json:"version"`
+	Participant     string `json:"participant"` // who made the latest change
+	EventNum        int    `json:"event_num"`   // where the latest change was made
+	TimestampMillis int64  `json:"timestamp_millis"`
+	Deleted         bool   `json:"deleted,omitempty"`
+}
+
+// setFact records a change to a fact made at event number eventNum. The
+// version counts every change, including deletions, whatever the event says.
+func (s *State) setFact(eventNum int, timestamp int64, participant, key, value string, deleted bool) {
+	if s.Facts == nil {
+		s.Facts = make(map[string]*Fact)
+	}
+	version := 1
+	if prev := s.Facts[key]; prev != nil {
+		version = prev.Version + 1
+	}
+	s.Facts[key] = &Fact{
+		Key:             key,
+		Value:           value,
+		Version:         version,
+		Participant:     participant,
+		EventNum:        eventNum,
+		TimestampMillis: timestamp,
+		Deleted:         deleted,
+	}
+}
+
+// SortedFacts returns the facts that are set, sorted by key
+func (s *State) SortedFacts() []*Fact {
+	facts := make([]*Fact, 0, len(s.Facts))
+	for _, f := range s.Facts {
+		if !f.Deleted {
+			facts = append(facts, f)
+		}
+	}
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Key < facts[j].Key })
+	return facts
+}
+
+// FactVersion returns the version a compare-and-set of key must expect: the
+// fact's latest version, or 0 if it isn't set
+func (s *State) FactVersion(key string) int {
+	if f := s.Facts[key]; f != nil && !f.Deleted {
+		return f.Version
+	}
+	return 0
+}
+
+// nextFactVersion returns the version the next change to key gets
+func (s *State) nextFactVersion(key string) int {
+	if f := s.Facts[key]; f != nil {
+		return f.Version + 1
+	}
+	return 1
+}
+
+// validFactKey checks that a fact key can be used
+func validFactKey(key string) error {
+	switch {
+	case key == "":
+		return &errors.InvalidFactError{Key: key, Reason: "the key is empty"}
+	case len(key) > maxFactKeyLength:
+		return &errors.InvalidFactError{Key: key, Reason: fmt.Sprintf("the key is longer than %d bytes", maxFactKeyLength)}
+	case !factKeyPattern.MatchString(key):
+		return &errors.InvalidFactError{Key: key, Reason: "keys are letters, digits and '.', '_', ':', '/' or '-', like 'db.max_connections'"}
+	}
+	return nil
+}
+
+// validFactValue checks that a fact value fits on one header line
+func validFactValue(key, value string) error {
+	switch {
+	case strings.TrimSpace(value) == "":
+		return &errors.InvalidFactError{Key: key, Reason: "the value is empty"}
+	case strings.ContainsAny(value, "\r\n"):
+		return &errors.InvalidFactError{Key: key, Reason: "the value must be one line; share longer content with 'council artifact put'"}
+	case len(value) > maxFactValueLength:
+		return &errors.InvalidFactError{Key: key, Reason: fmt.Sprintf("the value is longer than %d bytes; share longer content with 'council artifact put'", maxFactValueLength)}
+	}
+	return nil
+}
+
+// checkFactVersion is the compare-and-set check: the fact must still be at
+// the version the writer read, 0 if it wasn't set
+func checkFactVersion(session *Session, key string, expectedVersion int) error {
+	if actual := session.FactVersion(key); actual != expectedVersion {
+		return &errors.FactConflictError{
+			SessionID:       session.ID,
+			Key:             key,
+			ExpectedVersion: expectedVersion,
+			ActualVersion:   actual,
+		}
+	}
+	return nil
+}
+
+// SetFact sets a fact if it's still at expectedVersion, the version the
+// writer last read (0 to set a fact that isn't set). Unlike posting, only
+// changes to the same key conflict. Like posting, it's open to active
+// participants and the Moderator.
+// Returns the new event number (1-indexed for display) and the fact's new
+// version, which the next change must expect
+func SetFact(sessionID, participant, key, value string, expectedVersion int) (int, int, error) {
+	value = strings.TrimSpace(value)
+	if err := validFactKey(key); err != nil {
+		return 0, 0, err
+	}
+	if err := validFactValue(key, value); err != nil {
+		return 0, 0, err
+	}
+
+	var version int
+	eventNum, err := appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+			return nil, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		}
+		if err := checkFactVersion(session, key, expectedVersion); err != nil {
+			return nil, err
+		}
+		version = session.nextFactVersion(key)
+		return NewFactSetEvent(participant, key, value, version), nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return eventNum, version, nil
+}
+
+// DeleteFact removes a fact if it's still at expectedVersion.
+// Returns the new event number (1-indexed for display)
+func DeleteFact(sessionID, participant, key string, expectedVersion int) (int, error) {
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+			return nil, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		}
+		if session.FactVersion(key) == 0 {
+			return nil, &errors.FactNotFoundError{SessionID: sessionID, Key: key}
+		}
+		if err := checkFactVersion(session, key, expectedVersion); err != nil {
+			return nil, err
+		}
+		return NewFactDeletedEvent(participant, key, session.nextFactVersion(key)), nil
+	})
+}
+
+// GetFact returns a fact that's set
+func GetFact(sess *Session, key string) (*Fact, error) {
+	if sess.FactVersion(key) == 0 {
+		return nil, &errors.FactNotFoundError{SessionID: sess.ID, Key: key}
+	}
+	return sess.Facts[key], nil
+}
@@ -0,0 +1,165 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+func TestSetAndDeleteFacts(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newAliceAndBobSession(t)
+
+			if num, version, err := SetFact("sess", "Alice", "region", "us-east-1", 0); err != nil || num != 4 || version != 1 {
+				t.Fatalf("expected version 1 at #4, got v%d at #%d: %v", version, num, err)
+			}
+			if _, version, err := SetFact("sess", "Bob", "region", " eu-west-1 ", 1); err != nil || version != 2 {
+				t.Fatalf("expected version 2, got v%d: %v", version, err)
+			}
+			SetFact("sess", "Alice", "db.max_connections", "100", 0)
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			fact, err := GetFact(sess, "region")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fact.Value != "eu-west-1" || fact.Version != 2 || fact.Participant != "Bob" || fact.EventNum != 5 {
+				t.Errorf("unexpected fact: %+v", fact)
+			}
+			if facts := sess.SortedFacts(); len(facts) != 2 || facts[0].Key != "db.max_connections" {
+				t.Errorf("expected facts sorted by key, got %+v", facts)
+			}
+
+			if _, err := DeleteFact("sess", "Bob", "db.max_connections", 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sess, _ = LoadSession("sess")
+			if _, err := GetFact(sess, "db.max_connections"); !isErr[*errors.FactNotFoundError](err) {
+				t.Errorf("expected FactNotFoundError after deletion, got %v", err)
+			}
+			if len(sess.SortedFacts()) != 1 {
+				t.Errorf("deleted facts shouldn't be listed, got %+v", sess.SortedFacts())
+			}
+
+			// A deleted fact is set again from version 0, and versions keep counting
+			if _, version, err := SetFact("sess", "Alice", "db.max_connections", "200", 0); err != nil || version != 3 {
+				t.Errorf("expected version 3, got v%d: %v", version, err)
+			}
+		})
+	}
+}
+
+func TestSetFactConflict(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+	SetFact("sess", "Alice", "region", "us-east-1", 0)
+	SetFact("sess", "Alice", "region", "us-west-2", 1)
+
+	// Bob read version 1 and writes after Alice's second change
+	_, _, err := SetFact("sess", "Bob", "region", "eu-west-1", 1)
+	conflict, ok := err.(*errors.FactConflictError)
+	if !ok {
+		t.Fatalf("expected FactConflictError, got %T: %v", err, err)
+	}
+	if conflict.ExpectedVersion != 1 || conflict.ActualVersion != 2 || conflict.SessionID != "sess" {
+		t.Errorf("unexpected conflict: %+v", conflict)
+	}
+
+	if _, _, err := SetFact("sess", "Bob", "new.key", "x", 3); !isErr[*errors.FactConflictError](err) {
+		t.Errorf("expected FactConflictError for a fact that isn't set, got %v", err)
+	}
+	if _, err := DeleteFact("sess", "Bob", "region", 1); !isErr[*errors.FactConflictError](err) {
+		t.Errorf("expected FactConflictError, got %v", err)
+	}
+
+	// Changes to other keys and other events don't conflict
+	PostMessage("sess", "Alice", "hi", "Bob", 5)
+	if _, _, err := SetFact("sess", "Bob", "region", "eu-west-1", 2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFactRules(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+
+	for _, key := range []string{"", "has space", "-leading", "a=b", strings.Repeat("k", 65)} {
+		if _, _, err := SetFact("sess", "Alice", key, "x", 0); !isErr[*errors.InvalidFactError](err) {
+			t.Errorf("expected InvalidFactError for key %q, got %v", key, err)
+		}
+	}
+	for _, value := range []string{"", "  ", "two\nlines", strings.Repeat("v", 501)} {
+		if _, _, err := SetFact("sess", "Alice", "k", value, 0); !isErr[*errors.InvalidFactError](err) {
+			t.Errorf("expected InvalidFactError for value %q, got %v", value, err)
+		}
+	}
+	if _, _, err := SetFact("sess", "Carol", "k", "x", 0); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, _, err := SetFact("sess", "Moderator", "k", "x", 0); err != nil {
+		t.Errorf("the Moderator should set facts, got %v", err)
+	}
+	if _, err := DeleteFact("sess", "Alice", "missing", 0); !isErr[*errors.FactNotFoundError](err) {
+		t.Errorf("expected FactNotFoundError, got %T: %v", err, err)
+	}
+}
+
+func TestFormatStatusShowsFacts(t *testing.T) {
+	useFileStore(t)
+	newAliceAndBobSession(t)
+	SetFact("sess", "Alice", "region", "us-east-1", 0)
+	SetFact("sess", "Bob", "db.max_connections", "100", 0)
+	DeleteFact("sess", "Bob", "db.max_connections", 1)
+
+	// The header comes from state, so it's complete with --after too
+	sess, err := LoadSessionAfter("sess", 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := FormatStatus(sess, 6)
+	if !strings.Contains(out, "Facts:\n  region = us-east-1 (v1, Alice at #4)\n") {
+		t.Errorf("header should list the facts that are set:\n%s", out)
+	}
+	if strings.Contains(out, "max_connections = ") {
+		t.Errorf("deleted facts shouldn't be listed:\n%s", out)
+	}
+
+	sess, _ = LoadSession("sess")
+	out = FormatStatus(sess, 0)
+	for _, want := range []string{
+		"--- #4 | Alice set fact region = us-east-1 (v1) ---",
+		"--- #6 | Bob deleted fact db.max_connections (v2) ---",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCheckSessionFacts(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+
+		`{"type":"fact_set","timestamp_millis":4,"participant":"Alice","key":"region","value":"us","fact_version":2}
+{"type":"fact_deleted","timestamp_millis":5,"participant":"Alice","key":"missing","fact_version":1}
+`)
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %+v", report.Problems)
+	}
+	if report.Problems[0].Line != 4 || !strings.Contains(report.Problems[0].Message, "version 2, expected 1") {
+		t.Errorf("unexpected problem: %+v", report.Problems[0])
+	}
+	if report.Problems[1].Line != 5 || !strings.Contains(report.Problems[1].Message, "isn't set") {
+		t.Errorf("unexpected problem: %+v", report.Problems[1])
+	}
+}
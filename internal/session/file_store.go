@@ -0,0 +1,650 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// FileStore stores each session as a JSONL file at
+// ~/.council/sessions/<id>/events.jsonl, one event per line.
+// Archived sessions are moved to ~/.council/archive/<id>/.
+type FileStore struct {
+	// Checksums adds a crc32 field to each event written
+	Checksums bool
+
+	// LockTimeout is how long to wait for a session lock held by another
+	// process. DefaultLockTimeout is used if it's zero.
+	LockTimeout time.Duration
+
+	// Locker is the locking strategy. FlockLocker is used if it's nil.
+	Locker Locker
+}
+
+// NewFileStore creates a store backed by the council sessions directory
+func NewFileStore() *FileStore {
+	return &FileStore{}
+}
+
+// sessionDir returns the directory holding a session's files, preferring the
+// active location over the archive. For missing sessions it returns the
+// active location with archived=false.
+func (s *FileStore) sessionDir(sessionID string) (dir string, archived bool, err error) {
+	active, err := storage.SessionExists(sessionID)
+	if err != nil {
+		return "", false, err
+	}
+	if !active {
+		inArchive, err := storage.ArchivedSessionExists(sessionID)
+		if err != nil {
+			return "", false, err
+		}
+		if inArchive {
+			dir, err := storage.ArchivedSessionDirPath(sessionID)
+			return dir, true, err
+		}
+	}
+	dir, err = storage.SessionDirPath(sessionID)
+	return dir, false, err
+}
+
+// Append implements Store
+func (s *FileStore) Append(sessionID string, expectedCount int, events ...Event) (err error) {
+	archived, err := storage.ArchivedSessionExists(sessionID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	if expectedCount == 0 {
+		if err := storage.EnsureSessionDir(sessionID); err != nil {
+			return err
+		}
+	} else {
+		exists, err := storage.SessionExists(sessionID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &errors.SessionNotFoundError{SessionID: sessionID}
+		}
+	}
+
+	path, err := storage.SessionEventsPath(sessionID)
+	if err != nil {
+		return err
+	}
+
+	lock, err := s.lock(sessionID, path, eventParticipant(events))
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lock, &err)
+
+	// The session may have been archived or deleted while we waited for the
+	// lock, in which case we hold a lock on a file that's no longer at path
+	if err := s.checkLockedFile(sessionID, path, lock.File()); err != nil {
+		return err
+	}
+
+	lock.File().Seek(0, io.SeekStart)
+	count, first, last, err := scanEvents(lock.File())
+	if err != nil {
+		return err
+	}
+
+	if count != expectedCount {
+		return &errors.StaleStateError{
+			ExpectedEventNum: expectedCount,
+			ActualEventNum:   count,
+			SessionID:        sessionID,
+		}
+	}
+
+	// A write torn just before its newline leaves a complete last event;
+	// start on a fresh line rather than running into it
+	var buf []byte
+	end, err := lock.File().Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if end > 0 {
+		last := make([]byte, 1)
+		if _, err := lock.File().ReadAt(last, end-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			buf = append(buf, '\n')
+		}
+	}
+
+	lines, err := encodeAppend(sessionID, count, first, last, events, s.Checksums)
+	if err != nil {
+		return withSessionID(err, sessionID)
+	}
+	for _, line := range lines {
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if _, err := lock.File().Write(buf); err != nil {
+		return err
+	}
+
+	// Callers report the event number as soon as we return, so the events
+	// must be on disk by then
+	if err := lock.File().Sync(); err != nil {
+		return err
+	}
+	if expectedCount == 0 {
+		return syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// eventParticipant returns the participant acting in the events being
+// appended, for lock diagnostics
+func eventParticipant(events []Event) string {
+	for _, event := range events {
+		switch e := event.(type) {
+		case *JoinedEvent:
+			return e.Participant
+		case *LeftEvent:
+			return e.Participant
+		case *MessageEvent:
+			return e.Participant
+		}
+	}
+	return ""
+}
+
+// syncDir flushes a directory's entries, making newly created files in it durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// checkLockedFile verifies a locked file is still the session's events file
+func (s *FileStore) checkLockedFile(sessionID, path string, f *os.File) error {
+	lockedInfo, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	currentInfo, err := os.Stat(path)
+	if err == nil && os.SameFile(lockedInfo, currentInfo) {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	archived, err := storage.ArchivedSessionExists(sessionID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+	return &errors.SessionNotFoundError{SessionID: sessionID}
+}
+
+// ReadFrom implements Store. The sidecar index locates the first event after
+// offset, so the events before it aren't read.
+func (s *FileStore) ReadFrom(sessionID string, offset int) ([]Event, error) {
+	session, err := s.ReadTail(sessionID, offset)
+	if err != nil {
+		return nil, err
+	}
+	return session.Events, nil
+}
+
+// List implements Store
+func (s *FileStore) List() ([]string, error) {
+	sessionsDir, err := storage.SessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	return listSessionDirs(sessionsDir)
+}
+
+// ListArchived implements Store
+func (s *FileStore) ListArchived() ([]string, error) {
+	archiveDir, err := storage.ArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	return listSessionDirs(archiveDir)
+}
+
+// listSessionDirs returns the sorted names of subdirectories containing an events file
+func listSessionDirs(parent string) ([]string, error) {
+	entries, err := os.ReadDir(parent)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(parent, entry.Name(), storage.EventsFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, entry.Name())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Exists implements Store
+func (s *FileStore) Exists(sessionID string) (bool, error) {
+	exists, err := storage.SessionExists(sessionID)
+	if err != nil || exists {
+		return exists, err
+	}
+	return storage.ArchivedSessionExists(sessionID)
+}
+
+// Archive implements Store by moving the session directory into the archive.
+// The session lock is held during the move so no write is in progress.
+func (s *FileStore) Archive(sessionID string) (err error) {
+	exists, err := storage.SessionExists(sessionID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		archived, err := storage.ArchivedSessionExists(sessionID)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return &errors.SessionArchivedError{SessionID: sessionID}
+		}
+		return &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+
+	path, err := storage.SessionEventsPath(sessionID)
+	if err != nil {
+		return err
+	}
+	srcDir, err := storage.SessionDirPath(sessionID)
+	if err != nil {
+		return err
+	}
+	dstDir, err := storage.ArchivedSessionDirPath(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstDir), 0755); err != nil {
+		return err
+	}
+
+	lock, err := s.lock(sessionID, path, "")
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lock, &err)
+
+	if err := s.checkLockedFile(sessionID, path, lock.File()); err != nil {
+		return err
+	}
+
+	if err := os.Rename(srcDir, dstDir); err != nil {
+		return err
+	}
+	// The lock is released at the old path, so its files would stay behind
+	for _, suffix := range []string{storage.LockInfoSuffix, storage.LockFileSuffix} {
+		err := os.Remove(filepath.Join(dstDir, storage.EventsFile+suffix))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete implements Store. The session lock is held while removing the
+// directory so a session is never deleted mid-write.
+func (s *FileStore) Delete(sessionID string) (err error) {
+	dir, archived, err := s.sessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, storage.EventsFile)
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+
+	lock, err := s.lock(sessionID, path, "")
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lock, &err)
+
+	if !archived {
+		if err := s.checkLockedFile(sessionID, path, lock.File()); err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// PutArtifact implements ArtifactStore. Artifacts live in the session
+// directory, so they move with it when it's archived and go when it's deleted.
+func (s *FileStore) PutArtifact(sessionID, hash string, data []byte) error {
+	dir, archived, err := s.sessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, storage.EventsFile)); os.IsNotExist(err) {
+		return &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	path := filepath.Join(dir, storage.ArtifactsDir, hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// GetArtifact implements ArtifactStore
+func (s *FileStore) GetArtifact(sessionID, hash string) ([]byte, error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(dir, storage.ArtifactsDir, hash))
+}
+
+// scanEvents counts the non-empty lines in a session file, also returning
+// the first and last of them
+func scanEvents(r io.Reader) (count int, first, last []byte, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		count++
+		if first == nil {
+			first = bytes.Clone(line)
+		}
+		// The scanner reuses its buffer, so keep a copy
+		last = append(last[:0], line...)
+	}
+	return count, first, last, scanner.Err()
+}
+
+// ReadRaw implements Store
+func (s *FileStore) ReadRaw(sessionID string) ([]RawLine, error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, storage.EventsFile))
+	if os.IsNotExist(err) {
+		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return splitRawLines(data, 1, 0), nil
+}
+
+// ReadRawFrom implements RawTailReader
+func (s *FileStore) ReadRawFrom(sessionID string, num int, offset int64) ([]RawLine, error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(dir, storage.EventsFile))
+	if os.IsNotExist(err) {
+		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if offset >= info.Size() {
+		return []RawLine{}, nil
+	}
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+	return splitRawLines(data, num, offset), nil
+}
+
+// splitRawLines splits log data into lines, the first of which has line
+// number num and starts at byte offset offset
+func splitRawLines(data []byte, num int, offset int64) []RawLine {
+	lines := []RawLine{}
+	for ; len(data) > 0; num++ {
+		line, rest, terminated := bytes.Cut(data, []byte("\n"))
+		lines = append(lines, RawLine{Num: num, Offset: offset, Data: line, Terminated: terminated})
+		offset += int64(len(data) - len(rest))
+		data = rest
+	}
+	return lines
+}
+
+// RepairTail implements TailRepairer
+func (s *FileStore) RepairTail(sessionID string, size, quarantineFrom int64) (_ string, err error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, storage.EventsFile)
+
+	lock, err := s.lock(sessionID, path, "")
+	if err != nil {
+		return "", err
+	}
+	defer releaseLock(lock, &err)
+	f := lock.File()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() != size {
+		return "", fmt.Errorf("session '%s' changed while it was being checked. Run 'council fsck %s' again.", sessionID, sessionID)
+	}
+
+	quarantinePath := ""
+	if quarantineFrom < size {
+		tail := make([]byte, size-quarantineFrom)
+		if _, err := f.ReadAt(tail, quarantineFrom); err != nil {
+			return "", err
+		}
+		if err := lock.Check(); err != nil {
+			return "", err
+		}
+		quarantinePath, err = writeSideFile(dir, "torn", tail)
+		if err != nil {
+			return "", err
+		}
+		if err := f.Truncate(quarantineFrom); err != nil {
+			return "", err
+		}
+	}
+
+	// The last kept line may still be missing its newline, in which case the
+	// next append would run into it
+	if quarantineFrom > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, quarantineFrom-1); err != nil {
+			return "", err
+		}
+		if last[0] != '\n' {
+			if _, err := f.WriteAt([]byte("\n"), quarantineFrom); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return quarantinePath, f.Sync()
+}
+
+// Rewrite implements Rewriter. The old log is copied to a backup file next to
+// it first, so an interrupted rewrite loses nothing.
+func (s *FileStore) Rewrite(sessionID string, expectedCount int, events []Event) (_ string, err error) {
+	dir, archived, err := s.sessionDir(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if archived {
+		return "", &errors.SessionArchivedError{SessionID: sessionID}
+	}
+	path := filepath.Join(dir, storage.EventsFile)
+
+	lock, err := s.lock(sessionID, path, "")
+	if err != nil {
+		return "", err
+	}
+	defer releaseLock(lock, &err)
+	f := lock.File()
+
+	if err := s.checkLockedFile(sessionID, path, f); err != nil {
+		return "", err
+	}
+
+	old, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	count, _, _, err := scanEvents(bytes.NewReader(old))
+	if err != nil {
+		return "", err
+	}
+	if count != expectedCount {
+		return "", &errors.StaleStateError{
+			ExpectedEventNum: expectedCount,
+			ActualEventNum:   count,
+			SessionID:        sessionID,
+		}
+	}
+
+	lines, err := encodeAppend(sessionID, 0, nil, nil, events, s.Checksums)
+	if err != nil {
+		return "", withSessionID(err, sessionID)
+	}
+	var buf []byte
+	for _, line := range lines {
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	if err := lock.Check(); err != nil {
+		return "", err
+	}
+	backup, err := writeSideFile(dir, "pre-migrate", old)
+	if err != nil {
+		return "", err
+	}
+
+	// Offsets in the index and snapshots no longer match; readers rebuild them
+	if err := os.Remove(filepath.Join(dir, storage.IndexFile)); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := removeSnapshots(dir); err != nil {
+		return "", err
+	}
+	if err := f.Truncate(0); err != nil {
+		return "", err
+	}
+	if _, err := f.WriteAt(buf, 0); err != nil {
+		return "", err
+	}
+	return backup, f.Sync()
+}
+
+// writeFileAtomic replaces a file via a temporary file in the same
+// directory, so readers never see it partially written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// writeSideFile saves data next to a session's log as
+// events.jsonl.<kind>-<millis>, adding a counter if that name is taken
+func writeSideFile(dir, kind string, data []byte) (string, error) {
+	base := filepath.Join(dir, fmt.Sprintf("%s.%s-%d", storage.EventsFile, kind, Now()))
+	path := base
+	for n := 2; ; n++ {
+		err := writeFileSync(path, data)
+		if !os.IsExist(err) {
+			return path, err
+		}
+		path = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// writeFileSync writes a new file and flushes it to disk
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
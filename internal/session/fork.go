@@ -0,0 +1,102 @@
+package session
+
+import (
+	"os"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// ForkSession creates childID as a branch of parentID at event number at.
+// With history, the child's log copies the parent's events up to and
+// including at, so event numbers line up with the parent's; otherwise it
+// starts fresh. Either way the child records a forked_from event, and the
+// parent records a forked event pointing at the child.
+func ForkSession(parentID, childID string, at int, withHistory bool) error {
+	parent, err := LoadHistory(parentID)
+	if err != nil {
+		return err
+	}
+
+	if at < 1 || at > parent.EventCount() {
+		return &errors.InvalidForkPointError{
+			SessionID:  parentID,
+			EventNum:   at,
+			EventCount: parent.EventCount(),
+		}
+	}
+
+	// A fork of a hash-chained session starts its own chain, and a fork of
+	// an encrypted session is encrypted too
+	created := NewSessionCreatedEvent(childID)
+	if parentCreated, ok := parent.Events[0].(*SessionCreatedEvent); ok {
+		created.HashChain = parentCreated.HashChain
+		created.Encrypted = parentCreated.Encrypted
+	}
+
+	events := []Event{created}
+	if withHistory {
+		// Event 1 is the parent's session_created, replaced by the child's own
+		events = append(events, parent.Events[1:at]...)
+	}
+	events = append(events, NewForkedFromEvent(parentID, at, withHistory))
+
+	if err := createWithEvents(childID, events); err != nil {
+		return err
+	}
+	if err := copyArtifacts(parentID, childID, events, created.Encrypted); err != nil {
+		return err
+	}
+
+	// An archived parent is read-only; the fork itself still stands
+	_, err = appendWithRetry(parentID, func(*Session) (Event, error) {
+		return NewForkedEvent(childID, at), nil
+	})
+	if _, ok := err.(*errors.SessionArchivedError); ok {
+		return nil
+	}
+	return err
+}
+
+// copyArtifacts copies the content of the artifacts added by events from
+// one session's store to another's, so the copied history's artifacts can
+// be fetched from the fork. Sealed content is bound to its session, so for
+// encrypted sessions it's opened and sealed again for the fork.
+func copyArtifacts(fromID, toID string, events []Event, encrypted bool) error {
+	store, ok := currentStore.(ArtifactStore)
+	if !ok {
+		return nil
+	}
+	for _, event := range events {
+		e, ok := event.(*ArtifactAddedEvent)
+		if !ok || !artifactHashPattern.MatchString(e.Hash) {
+			continue
+		}
+		fromName, err := artifactStoreName(fromID, e.Hash, encrypted)
+		if err != nil {
+			return err
+		}
+		stored, err := store.GetArtifact(fromID, fromName)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		data, err := openArtifact(fromID, e.Hash, stored, encrypted)
+		if err != nil {
+			return err
+		}
+
+		toName, err := artifactStoreName(toID, e.Hash, encrypted)
+		if err != nil {
+			return err
+		}
+		if stored, err = sealArtifact(toID, e.Hash, data, encrypted); err != nil {
+			return err
+		}
+		if err := store.PutArtifact(toID, toName, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
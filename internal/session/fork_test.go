@@ -0,0 +1,139 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newForkParent creates a parent session with 5 events: created, Alice and
+// Bob joined, then one message from each
+func newForkParent(t *testing.T, s Store) {
+	t.Helper()
+	useStore(t, s)
+	s.Append("parent", 0,
+		NewSessionCreatedEvent("parent"),
+		NewJoinedEvent("Alice"),
+		NewJoinedEvent("Bob"),
+		NewMessageEvent("Alice", "option A?", "Bob"),
+		NewMessageEvent("Bob", "option B!", "Alice"),
+	)
+}
+
+func TestForkWithHistory(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			newForkParent(t, newStore(t))
+
+			if err := ForkSession("parent", "child", 4, true); err != nil {
+				t.Fatalf("fork failed: %v", err)
+			}
+
+			child, err := LoadSession("child")
+			if err != nil {
+				t.Fatalf("failed to load child: %v", err)
+			}
+
+			// created + copies of #2..#4 + forked_from
+			if child.EventCount() != 5 {
+				t.Fatalf("expected 5 events in child, got %d", child.EventCount())
+			}
+			if created := child.Events[0].(*SessionCreatedEvent); created.ID != "child" {
+				t.Errorf("expected child session_created, got ID %q", created.ID)
+			}
+			if msg, ok := child.Events[3].(*MessageEvent); !ok || msg.Content != "option A?" {
+				t.Errorf("expected #4 to be the copied message, got %+v", child.Events[3])
+			}
+			if child.ForkedFrom == nil || child.ForkedFrom.SessionID != "parent" || child.ForkedFrom.EventNum != 4 {
+				t.Errorf("unexpected fork origin: %+v", child.ForkedFrom)
+			}
+			if len(child.ActiveParticipants()) != 0 {
+				t.Errorf("participants should rejoin the fork, got %v", child.ActiveParticipants())
+			}
+
+			parent, _ := LoadSession("parent")
+			last, ok := parent.Events[len(parent.Events)-1].(*ForkedEvent)
+			if !ok || last.ChildSession != "child" || last.AtEvent != 4 {
+				t.Errorf("expected forked event in parent, got %+v", parent.Events[len(parent.Events)-1])
+			}
+		})
+	}
+}
+
+func TestForkReferenceOnly(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			newForkParent(t, newStore(t))
+
+			if err := ForkSession("parent", "child", 2, false); err != nil {
+				t.Fatalf("fork failed: %v", err)
+			}
+
+			child, _ := LoadSession("child")
+			if child.EventCount() != 2 {
+				t.Fatalf("expected created + forked_from, got %d events", child.EventCount())
+			}
+			if child.ForkedFrom == nil || child.ForkedFrom.WithHistory {
+				t.Errorf("unexpected fork origin: %+v", child.ForkedFrom)
+			}
+		})
+	}
+}
+
+func TestForkInvalidEvent(t *testing.T) {
+	newForkParent(t, storeFactories()["file"](t))
+
+	for _, at := range []int{0, 6} {
+		err := ForkSession("parent", "child", at, true)
+		if _, ok := err.(*errors.InvalidForkPointError); !ok {
+			t.Errorf("at=%d: expected InvalidForkPointError, got %v", at, err)
+		}
+	}
+	if exists, _ := CurrentStore().Exists("child"); exists {
+		t.Error("child should not be created for an invalid fork point")
+	}
+}
+
+func TestForkExistingChild(t *testing.T) {
+	newForkParent(t, storeFactories()["file"](t))
+	CreateSession("child")
+
+	err := ForkSession("parent", "child", 3, true)
+	if _, ok := err.(*errors.SessionExistsError); !ok {
+		t.Errorf("expected SessionExistsError for existing child, got %v", err)
+	}
+}
+
+func TestForkArchivedParent(t *testing.T) {
+	newForkParent(t, storeFactories()["file"](t))
+	ArchiveSession("parent")
+
+	if err := ForkSession("parent", "child", 5, true); err != nil {
+		t.Fatalf("forking an archived session should succeed, got %v", err)
+	}
+
+	parent, _ := LoadSession("parent")
+	if parent.EventCount() != 5 {
+		t.Errorf("archived parent should be unchanged, got %d events", parent.EventCount())
+	}
+}
+
+func TestFormatStatusShowsForks(t *testing.T) {
+	newForkParent(t, storeFactories()["file"](t))
+	ForkSession("parent", "child", 4, true)
+
+	child, _ := LoadSession("child")
+	output := FormatStatus(child, 0)
+	for _, want := range []string{"Forked from: parent at #4", "--- #5 | Forked from parent at #4 ---"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in child output, got:\n%s", want, output)
+		}
+	}
+
+	parent, _ := LoadSession("parent")
+	output = FormatStatus(parent, 5)
+	if !strings.Contains(output, "--- #6 | Forked to child at #4 ---") {
+		t.Errorf("expected fork notice in parent output, got:\n%s", output)
+	}
+}
@@ -1,59 +1,268 @@
 package session
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/amterp/council/internal/errors"
 )
 
 // FormatStatus generates the human-readable status output
 func FormatStatus(sess *Session, afterN int) string {
 	var b strings.Builder
+	writeHeader(&b, sess)
+	revisions := sess.Revisions()
+
+	// Events (starting from afterN, 1-indexed for display)
+	for i, event := range sess.Events {
+		eventNum := sess.Offset + i + 1 // 1-indexed for display
+		if eventNum <= afterN {
+			continue
+		}
+		writeEvent(&b, sess, revisions, eventNum, event)
+	}
+
+	return b.String()
+}
+
+// FormatThread generates status output showing only the message at event
+// number root and the replies to it, direct or nested, in event order.
+// The session must be fully loaded.
+func FormatThread(sess *Session, root int) (string, error) {
+	if _, ok := sess.Event(root).(*MessageEvent); !ok {
+		return "", &errors.MessageNotFoundError{SessionID: sess.ID, EventNum: root}
+	}
+
+	var b strings.Builder
+	writeHeader(&b, sess)
+	fmt.Fprintf(&b, "Thread: #%d\n\n", root)
+	revisions := sess.Revisions()
+
+	// Replies always come after what they reply to, so one pass finds them all
+	thread := map[int]bool{root: true}
+	for i, event := range sess.Events {
+		eventNum := sess.Offset + i + 1
+		msg, ok := event.(*MessageEvent)
+		if !ok || (eventNum != root && !thread[msg.ReplyTo]) {
+			continue
+		}
+		thread[eventNum] = true
+		writeEvent(&b, sess, revisions, eventNum, event)
+	}
 
-	// Header
-	fmt.Fprintf(&b, "=== Session: %s ===\n", sess.ID)
+	return b.String(), nil
+}
+
+// writeHeader writes the session header: metadata, participants and polls
+func writeHeader(b *strings.Builder, sess *Session) {
+	fmt.Fprintf(b, "=== Session: %s ===\n", sess.ID)
+	writeMetadata(b, sess.Metadata)
+	if sess.ForkedFrom != nil {
+		fmt.Fprintf(b, "Forked from: %s at #%d\n", sess.ForkedFrom.SessionID, sess.ForkedFrom.EventNum)
+	}
 
 	// Participants (excluding Moderator, sorted for consistency)
 	participants := sess.ActiveParticipants()
 	sort.Strings(participants)
 	if len(participants) > 0 {
-		fmt.Fprintf(&b, "Participants: %s\n", strings.Join(participants, ", "))
+		fmt.Fprintf(b, "Participants: %s\n", strings.Join(participants, ", "))
 	} else {
-		fmt.Fprintf(&b, "Participants: (none)\n")
+		fmt.Fprintf(b, "Participants: (none)\n")
 	}
+	writeFacts(b, sess)
+	writeOpenTasks(b, sess)
+	writePolls(b, sess)
 	b.WriteString("\n")
+}
 
-	// Events (starting from afterN, 1-indexed for display)
-	for i, event := range sess.Events {
-		eventNum := i + 1 // 1-indexed for display
-		if eventNum <= afterN {
-			continue
+// writeEvent writes one event of the event list. Messages are shown as
+// revised by later edits and retractions.
+func writeEvent(b *strings.Builder, sess *Session, revisions map[int]*MessageRevision, eventNum int, event Event) {
+	switch e := event.(type) {
+	case *SessionCreatedEvent:
+		// Don't show session_created in output
+	case *JoinedEvent:
+		// Don't show Moderator join events
+		if e.Participant != "Moderator" {
+			fmt.Fprintf(b, "--- #%d | %s Joined ---\n\n", eventNum, e.Participant)
 		}
-
-		switch e := event.(type) {
-		case *SessionCreatedEvent:
-			// Don't show session_created in output
-			continue
-		case *JoinedEvent:
-			// Don't show Moderator join events
-			if e.Participant != "Moderator" {
-				fmt.Fprintf(&b, "--- #%d | %s Joined ---\n\n", eventNum, e.Participant)
-			}
-		case *LeftEvent:
-			fmt.Fprintf(&b, "--- #%d | %s Left ---\n\n", eventNum, e.Participant)
-		case *MessageEvent:
-			fmt.Fprintf(&b, "--- #%d | %s ---\n", eventNum, e.Participant)
-			b.WriteString(e.Content)
-			if !strings.HasSuffix(e.Content, "\n") {
-				b.WriteString("\n")
-			}
-			if e.Next != "" {
-				fmt.Fprintf(&b, "--- End #%d | %s | Next: %s ---\n\n", eventNum, e.Participant, e.Next)
+	case *LeftEvent:
+		fmt.Fprintf(b, "--- #%d | %s Left ---\n\n", eventNum, e.Participant)
+	case *SessionUpdatedEvent:
+		fmt.Fprintf(b, "--- #%d | Session details updated ---\n\n", eventNum)
+	case *ForkedFromEvent:
+		fmt.Fprintf(b, "--- #%d | Forked from %s at #%d ---\n\n", eventNum, e.ParentSession, e.ParentEvent)
+	case *ForkedEvent:
+		fmt.Fprintf(b, "--- #%d | Forked to %s at #%d ---\n\n", eventNum, e.ChildSession, e.AtEvent)
+	case *PollEvent:
+		fmt.Fprintf(b, "--- #%d | %s opened a poll: %s (%s) ---\n\n", eventNum, e.Participant, e.Question, strings.Join(e.Options, " / "))
+	case *VoteEvent:
+		if poll := sess.Polls[e.Poll]; poll != nil && poll.Secret {
+			fmt.Fprintf(b, "--- #%d | %s voted in poll #%d ---\n\n", eventNum, e.Participant, e.Poll)
+		} else {
+			fmt.Fprintf(b, "--- #%d | %s voted %s in poll #%d ---\n\n", eventNum, e.Participant, e.Choice, e.Poll)
+		}
+	case *DecisionEvent:
+		writeDecisionEvent(b, eventNum, e)
+	case *MessageEditedEvent:
+		// The new content is shown here too, for readers who saw the
+		// original, unless the message has since been retracted
+		if revisions[e.Message].Retracted() {
+			fmt.Fprintf(b, "--- #%d | %s edited #%d ---\n\n", eventNum, e.Participant, e.Message)
+		} else {
+			fmt.Fprintf(b, "--- #%d | %s edited #%d ---\n", eventNum, e.Participant, e.Message)
+			writeContent(b, e.Content)
+			fmt.Fprintf(b, "--- End #%d | %s edited #%d ---\n\n", eventNum, e.Participant, e.Message)
+		}
+	case *MessageRetractedEvent:
+		fmt.Fprintf(b, "--- #%d | %s retracted #%d ---\n\n", eventNum, e.Participant, e.Message)
+	case *ArtifactAddedEvent:
+		fmt.Fprintf(b, "--- #%d | %s added artifact %s (%s, %s) ---\n\n", eventNum, e.Participant, e.Name, FormatSize(e.Size), shortHash(e.Hash))
+	case *FactSetEvent:
+		fmt.Fprintf(b, "--- #%d | %s set fact %s = %s (v%d) ---\n\n", eventNum, e.Participant, e.Key, e.Value, e.FactVersion)
+	case *FactDeletedEvent:
+		fmt.Fprintf(b, "--- #%d | %s deleted fact %s (v%d) ---\n\n", eventNum, e.Participant, e.Key, e.FactVersion)
+	case *TaskAddedEvent:
+		owner := "unassigned"
+		if e.Owner != "" {
+			owner = "owner: " + e.Owner
+		}
+		fmt.Fprintf(b, "--- #%d | %s added task #%d: %s (%s) ---\n\n", eventNum, e.Participant, eventNum, e.Description, owner)
+	case *TaskAssignedEvent:
+		fmt.Fprintf(b, "--- #%d | %s assigned task #%d to %s ---\n\n", eventNum, e.Participant, e.Task, e.Owner)
+	case *TaskDoneEvent:
+		fmt.Fprintf(b, "--- #%d | %s completed task #%d ---\n\n", eventNum, e.Participant, e.Task)
+	case *RawEvent:
+		writeRawEvent(b, eventNum, e)
+	case *MessageEvent:
+		marker := ""
+		if e.ReplyTo != 0 {
+			marker += fmt.Sprintf(" (re #%d)", e.ReplyTo)
+		}
+		content, mentions := e.Content, e.Mentions
+		if r := revisions[eventNum]; r != nil {
+			if r.Retracted() {
+				content, mentions = r.Tombstone(), nil
 			} else {
-				fmt.Fprintf(&b, "--- End #%d | %s ---\n\n", eventNum, e.Participant)
+				content, mentions = r.Content, r.Mentions
+				marker += " (edited)"
 			}
 		}
+		if len(mentions) > 0 {
+			marker += " (mentions @" + strings.Join(mentions, ", @") + ")"
+		}
+		fmt.Fprintf(b, "--- #%d | %s%s ---\n", eventNum, e.Participant, marker)
+		writeContent(b, content)
+		if e.Next != "" {
+			fmt.Fprintf(b, "--- End #%d | %s | Next: %s ---\n\n", eventNum, e.Participant, e.Next)
+		} else {
+			fmt.Fprintf(b, "--- End #%d | %s ---\n\n", eventNum, e.Participant)
+		}
 	}
+}
 
-	return b.String()
+// writeContent writes message content, ending it with a newline
+func writeContent(b *strings.Builder, content string) {
+	b.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+}
+
+// writeRawEvent writes an event of an unknown type generically, as its type
+// and the JSON of its fields
+func writeRawEvent(b *strings.Builder, eventNum int, e *RawEvent) {
+	fmt.Fprintf(b, "--- #%d | %s (unknown event type) ---\n", eventNum, e.GetType())
+	if fields := e.Fields(); len(fields) > 0 {
+		data, _ := json.Marshal(fields)
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeDecisionEvent writes a decision as a block like a message, with its
+// rationale and the alternatives considered
+func writeDecisionEvent(b *strings.Builder, eventNum int, e *DecisionEvent) {
+	fmt.Fprintf(b, "--- #%d | Decision by %s: %s ---\n", eventNum, e.Participant, e.Title)
+	fmt.Fprintf(b, "Rationale: %s\n", strings.TrimSuffix(e.Rationale, "\n"))
+	if len(e.Alternatives) > 0 {
+		fmt.Fprintf(b, "Alternatives considered: %s\n", strings.Join(e.Alternatives, "; "))
+	}
+	if e.Supersedes != 0 {
+		fmt.Fprintf(b, "Supersedes: #%d\n", e.Supersedes)
+	}
+	fmt.Fprintf(b, "--- End #%d | Decision ---\n\n", eventNum)
+}
+
+// writePolls writes the live tally of each poll as header lines
+func writePolls(b *strings.Builder, sess *Session) {
+	polls := sess.SortedPolls()
+	if len(polls) == 0 {
+		return
+	}
+	b.WriteString("Polls:\n")
+	for _, poll := range polls {
+		state := "open"
+		if poll.ClosedAt(sess.EventCount()) {
+			state = fmt.Sprintf("closed after #%d", poll.ClosesAfter)
+		} else if poll.ClosesAfter > 0 {
+			state = fmt.Sprintf("open until #%d", poll.ClosesAfter)
+		}
+		if poll.Secret {
+			state += ", secret"
+		}
+		fmt.Fprintf(b, "  #%d %s [%s]\n", poll.EventNum, poll.Question, state)
+		for _, result := range poll.Tally() {
+			fmt.Fprintf(b, "    %s: %d", result.Choice, result.Count)
+			if len(result.Voters) > 0 {
+				fmt.Fprintf(b, " (%s)", strings.Join(result.Voters, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// writeFacts writes the facts that are set, with their versions for
+// compare-and-set
+func writeFacts(b *strings.Builder, sess *Session) {
+	facts := sess.SortedFacts()
+	if len(facts) == 0 {
+		return
+	}
+	b.WriteString("Facts:\n")
+	for _, f := range facts {
+		fmt.Fprintf(b, "  %s = %s (v%d, %s at #%d)\n", f.Key, f.Value, f.Version, f.Participant, f.EventNum)
+	}
+}
+
+// writeOpenTasks writes the tasks not yet done, so they aren't lost in the
+// event list
+func writeOpenTasks(b *strings.Builder, sess *Session) {
+	tasks := sess.OpenTasks()
+	if len(tasks) == 0 {
+		return
+	}
+	b.WriteString("Open tasks:\n")
+	for _, t := range tasks {
+		fmt.Fprintf(b, "  %s\n", t.Summary())
+	}
+}
+
+// writeMetadata writes the non-empty metadata fields as header lines
+func writeMetadata(b *strings.Builder, meta Metadata) {
+	if meta.Title != "" {
+		fmt.Fprintf(b, "Title: %s\n", meta.Title)
+	}
+	if meta.Goal != "" {
+		fmt.Fprintf(b, "Goal: %s\n", meta.Goal)
+	}
+	if len(meta.Tags) > 0 {
+		fmt.Fprintf(b, "Tags: %s\n", strings.Join(meta.Tags, ", "))
+	}
+	if meta.Description != "" {
+		fmt.Fprintf(b, "Description: %s\n", meta.Description)
+	}
 }
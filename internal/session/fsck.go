@@ -0,0 +1,265 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// FsckProblem is an integrity problem found on a line of a session log
+type FsckProblem struct {
+	Line    int
+	Message string
+}
+
+// FsckReport is the result of checking a session log
+type FsckReport struct {
+	SessionID string
+	Events    int // lines that parsed as events
+	Problems  []FsckProblem
+
+	// TornLine is the first line of an unparseable tail with no valid events
+	// after it, as left by a process killed mid-write. 0 if there is none.
+	TornLine int
+
+	// Unterminated is set when the last event parses but is missing its
+	// trailing newline
+	Unterminated bool
+
+	size           int64 // log size in bytes when checked
+	quarantineFrom int64 // byte offset of TornLine, or size if none
+}
+
+// OK checks if no problems were found
+func (r *FsckReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Repairable checks if RepairSession has anything to fix
+func (r *FsckReport) Repairable() bool {
+	return r.TornLine > 0 || r.Unterminated
+}
+
+// CheckSession validates every line of a session log: that it parses, and
+// that the events follow the rules enforced when they were appended
+func CheckSession(sessionID string) (*FsckReport, error) {
+	lines, err := currentStore.ReadRaw(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireKey(sessionID, lines); err != nil {
+		return nil, err
+	}
+	return checkLines(sessionID, lines), nil
+}
+
+// checkLines builds a report for a session's raw log lines
+func checkLines(sessionID string, lines []RawLine) *FsckReport {
+	report := &FsckReport{SessionID: sessionID}
+	sess := NewSession(sessionID)
+
+	tornStart := -1 // index of the first unparseable line in a trailing run
+	num := 0        // event number of the line, counting unreadable ones
+	encrypted := false
+	for i, line := range lines {
+		report.size = line.Offset + int64(len(line.Data))
+		if line.Terminated {
+			report.size++
+		}
+
+		data := bytes.TrimRight(line.Data, "\r")
+		if len(data) == 0 {
+			continue
+		}
+
+		num++
+		if num == 1 {
+			encrypted = isEncrypted(data)
+		}
+		event, err := parseStoredEvent(sessionID, num, data, encrypted)
+		if err != nil {
+			message := fmt.Sprintf("unreadable event: %v", err)
+			if !line.Terminated {
+				message = fmt.Sprintf("torn write: %v", err)
+			}
+			report.addProblem(line.Num, message)
+			if tornStart < 0 {
+				tornStart = i
+			}
+			continue
+		}
+
+		tornStart = -1
+		report.Events++
+		for _, message := range checkEvent(sess, report.Events, event) {
+			report.addProblem(line.Num, message)
+		}
+		sess.addEvent(event)
+	}
+
+	report.quarantineFrom = report.size
+	if tornStart >= 0 {
+		report.TornLine = lines[tornStart].Num
+		report.quarantineFrom = lines[tornStart].Offset
+	} else if len(lines) > 0 && !lines[len(lines)-1].Terminated {
+		last := lines[len(lines)-1]
+		report.Unterminated = true
+		report.addProblem(last.Num, "missing trailing newline; the write may have been interrupted")
+	}
+
+	return report
+}
+
+func (r *FsckReport) addProblem(line int, message string) {
+	r.Problems = append(r.Problems, FsckProblem{Line: line, Message: message})
+}
+
+// checkEvent validates an event against the session state before it.
+// eventNum is the event's 1-indexed position among parsed events.
+func checkEvent(sess *Session, eventNum int, event Event) []string {
+	var problems []string
+
+	if eventNum == 1 {
+		if _, ok := event.(*SessionCreatedEvent); !ok {
+			problems = append(problems, fmt.Sprintf("first event is '%s', expected 'session_created'", event.GetType()))
+		}
+	}
+
+	switch e := event.(type) {
+	case *SessionCreatedEvent:
+		if eventNum > 1 {
+			problems = append(problems, "duplicate 'session_created' event")
+		}
+		if e.ID != sess.ID {
+			problems = append(problems, fmt.Sprintf("'session_created' has id '%s', expected '%s'", e.ID, sess.ID))
+		}
+	case *JoinedEvent:
+		if sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("'%s' joined while already an active participant", e.Participant))
+		}
+	case *LeftEvent:
+		if !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("'%s' left without being an active participant", e.Participant))
+		}
+	case *MessageEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("message from '%s', who is not an active participant", e.Participant))
+		}
+		if e.Next != "" && e.Next != "Moderator" && !sess.IsActiveParticipant(e.Next) {
+			problems = append(problems, fmt.Sprintf("next speaker '%s' is not an active participant", e.Next))
+		}
+		if err := validReplyTo(sess, e.ReplyTo); err != nil {
+			problems = append(problems, err.Error())
+		}
+		for _, name := range e.Mentions {
+			if name != "Moderator" && !sess.IsActiveParticipant(name) {
+				problems = append(problems, fmt.Sprintf("mentioned '%s', who is not an active participant", name))
+			}
+		}
+	case *PollEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("poll from '%s', who is not an active participant", e.Participant))
+		}
+		spec := PollSpec{Question: e.Question, Options: e.Options, ClosesAfter: e.ClosesAfter}
+		if _, err := spec.normalized(eventNum); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case *VoteEvent:
+		if _, err := validVote(sess, e.Participant, e.Poll, e.Choice); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case *MessageEditedEvent:
+		if err := validRevision(sess, e.Participant, e.Message); err != nil {
+			problems = append(problems, err.Error())
+		}
+		for _, name := range e.Mentions {
+			if name != "Moderator" && !sess.IsActiveParticipant(name) {
+				problems = append(problems, fmt.Sprintf("mentioned '%s', who is not an active participant", name))
+			}
+		}
+	case *MessageRetractedEvent:
+		if err := validRevision(sess, e.Participant, e.Message); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case *ArtifactAddedEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("artifact from '%s', who is not an active participant", e.Participant))
+		}
+		if err := validArtifactName(e.Name); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if !artifactHashPattern.MatchString(e.Hash) {
+			problems = append(problems, fmt.Sprintf("artifact '%s' has a malformed hash '%s'", e.Name, e.Hash))
+		}
+	case *TaskAddedEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("task added by '%s', who is not an active participant", e.Participant))
+		}
+		if strings.TrimSpace(e.Description) == "" {
+			problems = append(problems, "task has an empty description")
+		}
+	case *TaskAssignedEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("task assigned by '%s', who is not an active participant", e.Participant))
+		}
+		if err := validOpenTask(sess, e.Task); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if strings.TrimSpace(e.Owner) == "" {
+			problems = append(problems, fmt.Sprintf("task #%d assigned to no one", e.Task))
+		}
+	case *TaskDoneEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("task completed by '%s', who is not an active participant", e.Participant))
+		}
+		if err := validOpenTask(sess, e.Task); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case *DecisionEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("decision from '%s', who is not an active participant", e.Participant))
+		}
+		spec := DecisionSpec{Title: e.Title, Rationale: e.Rationale}
+		if _, err := spec.normalized(); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if err := validSupersedes(sess, e.Supersedes); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case *FactSetEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("fact set by '%s', who is not an active participant", e.Participant))
+		}
+		if err := validFactKey(e.Key); err != nil {
+			problems = append(problems, err.Error())
+		} else if err := validFactValue(e.Key, e.Value); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if want := sess.nextFactVersion(e.Key); e.FactVersion != want {
+			problems = append(problems, fmt.Sprintf("fact '%s' set at version %d, expected %d", e.Key, e.FactVersion, want))
+		}
+	case *FactDeletedEvent:
+		if e.Participant != "Moderator" && !sess.IsActiveParticipant(e.Participant) {
+			problems = append(problems, fmt.Sprintf("fact deleted by '%s', who is not an active participant", e.Participant))
+		}
+		if sess.FactVersion(e.Key) == 0 {
+			problems = append(problems, fmt.Sprintf("deletes fact '%s', which isn't set", e.Key))
+		}
+		if want := sess.nextFactVersion(e.Key); e.FactVersion != want {
+			problems = append(problems, fmt.Sprintf("fact '%s' deleted at version %d, expected %d", e.Key, e.FactVersion, want))
+		}
+	}
+
+	return problems
+}
+
+// RepairSession quarantines the torn tail found by a check into a separate
+// file next to the log, keeping every event before it. Returns the
+// quarantine file's path, or "" if only a missing newline was fixed.
+func RepairSession(report *FsckReport) (string, error) {
+	repairer, ok := currentStore.(TailRepairer)
+	if !ok {
+		return "", fmt.Errorf("the configured store doesn't support repairs")
+	}
+	return repairer.RepairTail(report.SessionID, report.size, report.quarantineFrom)
+}
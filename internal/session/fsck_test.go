@@ -0,0 +1,188 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// writeLog replaces a file-backed session's log with raw content
+func writeLog(t *testing.T, sessionID, content string) {
+	t.Helper()
+	if err := storage.EnsureSessionDir(sessionID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path, _ := storage.SessionEventsPath(sessionID)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func useFileStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+}
+
+const validLog = `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"message","timestamp_millis":3,"participant":"Alice","content":"hi","next":"Moderator"}
+`
+
+func TestCheckSessionOK(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog)
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("expected no problems, got %+v", report.Problems)
+	}
+	if report.Events != 3 {
+		t.Errorf("expected 3 events, got %d", report.Events)
+	}
+}
+
+func TestCheckSessionInvariants(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", `{"type":"joined","timestamp_millis":1,"participant":"Alice"}
+{"type":"left","timestamp_millis":2,"participant":"Bob"}
+{"type":"message","timestamp_millis":3,"participant":"Carol","content":"hi","next":"Dave"}
+{"type":"joined","timestamp_millis":4,"participant":"Alice"}
+`)
+
+	report, _ := CheckSession("sess")
+
+	want := map[int]string{
+		1: "expected 'session_created'",
+		2: "'Bob' left without being an active participant",
+		3: "next speaker 'Dave'",
+		4: "'Alice' joined while already",
+	}
+	for line, substr := range want {
+		found := false
+		for _, p := range report.Problems {
+			if p.Line == line && strings.Contains(p.Message, substr) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected problem on line %d containing %q, got %+v", line, substr, report.Problems)
+		}
+	}
+	if report.Repairable() {
+		t.Error("invariant problems should not be repairable")
+	}
+}
+
+func TestCheckSessionTornTail(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+`{"type":"message","timestamp_millis":4,"partic`)
+
+	if _, err := LoadSession("sess"); err == nil {
+		t.Fatal("expected load to fail on a torn tail")
+	} else if corrupt, ok := err.(*errors.CorruptSessionError); !ok || corrupt.Line != 4 || corrupt.SessionID != "sess" {
+		t.Errorf("expected CorruptSessionError at line 4, got %v", err)
+	}
+
+	report, _ := CheckSession("sess")
+	if report.TornLine != 4 {
+		t.Fatalf("expected torn tail at line 4, got %d (%+v)", report.TornLine, report.Problems)
+	}
+
+	quarantined, err := RepairSession(report)
+	if err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	data, _ := os.ReadFile(quarantined)
+	if !strings.HasPrefix(string(data), `{"type":"message","timestamp_millis":4`) {
+		t.Errorf("expected torn line in quarantine file, got %q", data)
+	}
+	if filepath.Dir(quarantined) != sessionDirPath(t, "sess") {
+		t.Errorf("expected quarantine file in session dir, got %s", quarantined)
+	}
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("expected session to load after repair: %v", err)
+	}
+	if sess.EventCount() != 3 {
+		t.Errorf("expected 3 events kept, got %d", sess.EventCount())
+	}
+	if report, _ := CheckSession("sess"); !report.OK() {
+		t.Errorf("expected clean check after repair, got %+v", report.Problems)
+	}
+}
+
+func TestCheckSessionCorruptionBeforeValidEvents(t *testing.T) {
+	useFileStore(t)
+	lines := strings.SplitAfter(validLog, "\n")
+	writeLog(t, "sess", lines[0]+"garbage\n"+lines[1]+lines[2])
+
+	report, _ := CheckSession("sess")
+	if report.OK() {
+		t.Fatal("expected a problem for the garbage line")
+	}
+	if report.Problems[0].Line != 2 {
+		t.Errorf("expected problem on line 2, got %+v", report.Problems[0])
+	}
+	if report.TornLine != 0 {
+		t.Error("corruption followed by valid events is not a torn tail")
+	}
+}
+
+func TestRepairUnterminatedLastEvent(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", strings.TrimSuffix(validLog, "\n"))
+
+	report, _ := CheckSession("sess")
+	if !report.Unterminated || !report.Repairable() {
+		t.Fatalf("expected unterminated last event, got %+v", report)
+	}
+
+	quarantined, err := RepairSession(report)
+	if err != nil {
+		t.Fatalf("repair failed: %v", err)
+	}
+	if quarantined != "" {
+		t.Errorf("nothing should be quarantined, got %s", quarantined)
+	}
+
+	JoinSession("sess", "Bob")
+	if report, _ := CheckSession("sess"); !report.OK() {
+		t.Errorf("expected clean log after repair and append, got %+v", report.Problems)
+	}
+}
+
+func TestRepairRejectsChangedLog(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+`{"type":"jo`)
+
+	report, _ := CheckSession("sess")
+	writeLog(t, "sess", validLog+`{"type":"joined","timestamp_millis":4,"participant":"Bob"}`+"\n")
+
+	if _, err := RepairSession(report); err == nil {
+		t.Error("expected repair to refuse a log that changed since the check")
+	}
+}
+
+func TestCheckSessionSQLite(t *testing.T) {
+	s := storeFactories()["sqlite"](t)
+	useStore(t, s)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.OK() || report.Events != 2 {
+		t.Errorf("expected 2 clean events, got %+v", report)
+	}
+}
@@ -0,0 +1,383 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// Import formats
+const (
+	ImportMarkdown = "md"
+	ImportJSON     = "json"
+)
+
+// ImportFormats lists the transcript formats ParseTranscript understands
+var ImportFormats = []string{ImportMarkdown, ImportJSON}
+
+// ImportedMessage is a message read from another tool's transcript
+type ImportedMessage struct {
+	Speaker         string
+	Content         string
+	TimestampMillis int64 // 0 if the transcript doesn't say
+}
+
+// ParsedTranscript is the result of parsing a transcript for import
+type ParsedTranscript struct {
+	Title    string // from a Markdown heading above the speaker headings, if any
+	Messages []ImportedMessage
+}
+
+// ParseTranscript reads a transcript in one of ImportFormats
+func ParseTranscript(data []byte, format string) (*ParsedTranscript, error) {
+	switch format {
+	case ImportMarkdown:
+		return parseMarkdownTranscript(data)
+	case ImportJSON:
+		return parseJSONTranscript(data)
+	default:
+		return nil, fmt.Errorf("unknown import format '%s'. Use %s.", format, strings.Join(ImportFormats, ", "))
+	}
+}
+
+// markdownHeading matches an ATX heading, capturing its markers and text
+var markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Council's own Markdown exports number speaker headings ("### #4 · Alice",
+// or "### #4 · Alice (edited)"), put notices for other events between
+// messages and end messages with the next speaker. None of these are message
+// content.
+var (
+	eventNumPrefix = regexp.MustCompile(`^#\d+\s*·\s*`)
+	exportNotice   = regexp.MustCompile(`^\*#\d+ · .* · .*\*$`)
+	exportNext     = regexp.MustCompile(`^→ Next: \*\*.*\*\*$`)
+)
+
+type markdownLine struct {
+	text    string
+	level   int // heading level, 0 if not a heading
+	heading string
+	code    bool // in a fenced code block
+}
+
+// parseMarkdownTranscript reads messages introduced by speaker headings,
+// e.g. "## Alice". Speaker headings are those at the most common heading
+// level, so a document title or section headings above them are skipped,
+// and deeper headings stay part of the message. Council's own exports import
+// back as the messages they contain.
+func parseMarkdownTranscript(data []byte) (*ParsedTranscript, error) {
+	var lines []markdownLine
+	levelCounts := map[int]int{}
+	fence := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := markdownLine{text: strings.TrimRight(scanner.Text(), "\r")}
+		trimmed := strings.TrimSpace(line.text)
+
+		// Headings in fenced code blocks are content
+		if fence != "" {
+			line.code = true
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		} else if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+		} else if m := markdownHeading.FindStringSubmatch(line.text); m != nil {
+			line.level = len(m[1])
+			line.heading = m[2]
+			levelCounts[line.level]++
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	speakerLevel := 0
+	for level, count := range levelCounts {
+		if count > levelCounts[speakerLevel] || (count == levelCounts[speakerLevel] && level > speakerLevel) {
+			speakerLevel = level
+		}
+	}
+	// In an export, the numbered headings are the speakers however few
+	for _, line := range lines {
+		if line.level > 0 && eventNumPrefix.MatchString(line.heading) {
+			speakerLevel = line.level
+			break
+		}
+	}
+	if speakerLevel == 0 {
+		return nil, fmt.Errorf("no speaker headings found. Start each message with a heading naming its speaker, e.g. '## Alice'.")
+	}
+
+	result := &ParsedTranscript{}
+	var current *ImportedMessage
+	var body []string
+	finish := func() {
+		if current != nil {
+			current.TimestampMillis, body = leadingTimestamp(body)
+			body = withoutNextLine(body)
+			current.Content = strings.Trim(strings.Join(body, "\n"), "\n")
+			result.Messages = append(result.Messages, *current)
+		}
+		current, body = nil, nil
+	}
+
+	for _, line := range lines {
+		switch {
+		case line.level == speakerLevel:
+			finish()
+			speaker := line.heading
+			if number := eventNumPrefix.FindString(speaker); number != "" {
+				speaker = strings.TrimSuffix(strings.TrimPrefix(speaker, number), " (edited)")
+			}
+			speaker = strings.TrimSuffix(speaker, ":")
+			current = &ImportedMessage{Speaker: strings.TrimSpace(speaker)}
+		case line.level > 0 && line.level < speakerLevel:
+			finish()
+			if result.Title == "" && len(result.Messages) == 0 {
+				result.Title = line.heading
+			}
+		case current != nil && (line.code || !exportNotice.MatchString(line.text)):
+			body = append(body, line.text)
+		}
+	}
+	finish()
+
+	return result, nil
+}
+
+// withoutNextLine drops an exported "→ Next" line from the end of a body
+func withoutNextLine(body []string) []string {
+	for i := len(body) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(body[i])
+		if line == "" {
+			continue
+		}
+		if exportNext.MatchString(line) {
+			return body[:i]
+		}
+		break
+	}
+	return body
+}
+
+// leadingTimestamp extracts a timestamp from the first non-blank line of a
+// message body if that line is only an emphasized time, as in "*2024-01-15
+// 10:30:00 UTC*", returning it in milliseconds and the rest of the body
+func leadingTimestamp(body []string) (int64, []string) {
+	for i, line := range body {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, mark := range []string{"*", "_"} {
+			inner, ok := strings.CutPrefix(line, mark)
+			if !ok {
+				continue
+			}
+			if inner, ok = strings.CutSuffix(inner, mark); !ok {
+				continue
+			}
+			if t, ok := parseTranscriptTime(inner); ok {
+				return t.UnixMilli(), body[i+1:]
+			}
+		}
+		return 0, body
+	}
+	return 0, body
+}
+
+// transcriptTimeLayouts are the time formats accepted in imported
+// transcripts. Times without a zone are taken as UTC.
+var transcriptTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+func parseTranscriptTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range transcriptTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// jsonTranscriptMessage is one element of a JSON transcript. The speaker is
+// name if given, else role; content is the message text.
+type jsonTranscriptMessage struct {
+	Role            string          `json:"role"`
+	Name            string          `json:"name"`
+	Content         *string         `json:"content"`
+	Timestamp       json.RawMessage `json:"timestamp"`
+	TimestampMillis int64           `json:"timestamp_millis"`
+}
+
+// parseJSONTranscript reads an array of role/content objects, as used by
+// most chat APIs. Messages with the "system" role and no name are posted by
+// the Moderator.
+func parseJSONTranscript(data []byte) (*ParsedTranscript, error) {
+	var raw []jsonTranscriptMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of messages with role and content: %w", err)
+	}
+
+	result := &ParsedTranscript{}
+	for i, m := range raw {
+		speaker := strings.TrimSpace(m.Name)
+		if speaker == "" {
+			speaker = strings.TrimSpace(m.Role)
+			if speaker == "system" {
+				speaker = "Moderator"
+			}
+		}
+		if speaker == "" {
+			return nil, fmt.Errorf("message %d has no role or name", i+1)
+		}
+		if m.Content == nil {
+			return nil, fmt.Errorf("message %d has no content", i+1)
+		}
+
+		millis := m.TimestampMillis
+		if millis == 0 && len(m.Timestamp) > 0 && string(m.Timestamp) != "null" {
+			var err error
+			if millis, err = parseJSONTimestamp(m.Timestamp); err != nil {
+				return nil, fmt.Errorf("message %d: %w", i+1, err)
+			}
+		}
+
+		result.Messages = append(result.Messages, ImportedMessage{
+			Speaker:         speaker,
+			Content:         *m.Content,
+			TimestampMillis: millis,
+		})
+	}
+	return result, nil
+}
+
+// parseJSONTimestamp reads a timestamp given as a time string or as epoch
+// seconds or milliseconds
+func parseJSONTimestamp(raw json.RawMessage) (int64, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		t, ok := parseTranscriptTime(s)
+		if !ok {
+			return 0, fmt.Errorf("unrecognized timestamp '%s'. Use RFC 3339, e.g. 2024-01-15T10:30:00Z.", s)
+		}
+		return t.UnixMilli(), nil
+	}
+
+	var n float64
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0, fmt.Errorf("timestamp must be a string or a number")
+	}
+	// Epoch seconds are below 1e11 until the year 5138
+	if n < 1e11 {
+		return int64(n * 1000), nil
+	}
+	return int64(n), nil
+}
+
+// ImportSession creates a session holding imported messages. Every speaker
+// joins at the start, and each message hands off to the speaker of the one
+// after it; messages are checked with the same rules as PostMessage. Missing
+// timestamps are taken from the message before (or after) them, or the
+// current time if the transcript has none. Returns the number of events
+// written.
+func ImportSession(sessionID string, messages []ImportedMessage, opts CreateOptions) (int, error) {
+	if len(messages) == 0 {
+		return 0, fmt.Errorf("the transcript has no messages")
+	}
+	messages = withTimestamps(messages)
+
+	events, err := initialEvents(sessionID, opts)
+	if err != nil {
+		return 0, err
+	}
+	sess := NewSession(sessionID)
+	for _, event := range events {
+		setTimestamp(event, messages[0].TimestampMillis)
+		sess.addEvent(event)
+	}
+
+	// Speakers join with the same rules as JoinSession; the Moderator's
+	// messages are posted as the Moderator
+	for i, m := range messages {
+		if strings.TrimSpace(m.Speaker) == "" {
+			return 0, fmt.Errorf("message %d has no speaker", i+1)
+		}
+		if m.Speaker == "Moderator" || sess.IsActiveParticipant(m.Speaker) {
+			continue
+		}
+		if IsReservedName(m.Speaker) {
+			return 0, fmt.Errorf("message %d: %w", i+1, &errors.ReservedNameError{Name: m.Speaker})
+		}
+		joined := NewJoinedEvent(m.Speaker)
+		joined.TimestampMillis = messages[0].TimestampMillis
+		sess.addEvent(joined)
+	}
+
+	for i, m := range messages {
+		if strings.TrimSpace(m.Content) == "" {
+			return 0, fmt.Errorf("message %d from '%s' is empty", i+1, m.Speaker)
+		}
+		next := ""
+		if i+1 < len(messages) {
+			next = messages[i+1].Speaker
+		}
+		msg, err := newValidMessage(sess, m.Speaker, m.Content, next, 0)
+		if err != nil {
+			return 0, fmt.Errorf("message %d from '%s': %w", i+1, m.Speaker, err)
+		}
+		msg.TimestampMillis = m.TimestampMillis
+		sess.addEvent(msg)
+	}
+
+	return len(sess.Events), createWithEvents(sessionID, sess.Events)
+}
+
+// withTimestamps returns a copy of messages with missing timestamps filled in
+func withTimestamps(messages []ImportedMessage) []ImportedMessage {
+	result := append([]ImportedMessage(nil), messages...)
+
+	first := Now()
+	for _, m := range result {
+		if m.TimestampMillis != 0 {
+			first = m.TimestampMillis
+			break
+		}
+	}
+
+	prev := first
+	for i := range result {
+		if result[i].TimestampMillis == 0 {
+			result[i].TimestampMillis = prev
+		}
+		prev = result[i].TimestampMillis
+	}
+	return result
+}
+
+// setTimestamp sets an event's timestamp
+func setTimestamp(event Event, millis int64) {
+	switch e := event.(type) {
+	case *SessionCreatedEvent:
+		e.TimestampMillis = millis
+	case *SessionUpdatedEvent:
+		e.TimestampMillis = millis
+	}
+}
@@ -0,0 +1,194 @@
+package session
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+func parse(t *testing.T, data, format string) *ParsedTranscript {
+	t.Helper()
+	parsed, err := ParseTranscript([]byte(data), format)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return parsed
+}
+
+func TestParseMarkdownTranscript(t *testing.T) {
+	parsed := parse(t, `# Design review
+
+Notes from Tuesday.
+
+## Alice:
+*2024-01-15 10:30:00 UTC*
+
+Let's use cursors.
+
+### Why
+They're stable.
+
+`+"```"+`
+## not a speaker
+`+"```"+`
+
+## Bob
+Agreed.
+`, ImportMarkdown)
+
+	if parsed.Title != "Design review" {
+		t.Errorf("expected the top heading as title, got %q", parsed.Title)
+	}
+	if len(parsed.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %+v", parsed.Messages)
+	}
+	alice, bob := parsed.Messages[0], parsed.Messages[1]
+	if alice.Speaker != "Alice" || alice.TimestampMillis != time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC).UnixMilli() {
+		t.Errorf("unexpected first message: %+v", alice)
+	}
+	if !strings.HasPrefix(alice.Content, "Let's use cursors.") || !strings.Contains(alice.Content, "### Why") || !strings.Contains(alice.Content, "## not a speaker") {
+		t.Errorf("deeper headings and code should stay in the message, got %q", alice.Content)
+	}
+	if bob.Speaker != "Bob" || bob.Content != "Agreed." || bob.TimestampMillis != 0 {
+		t.Errorf("unexpected second message: %+v", bob)
+	}
+}
+
+func TestParseMarkdownWithoutHeadings(t *testing.T) {
+	_, err := ParseTranscript([]byte("just some text"), ImportMarkdown)
+	if err == nil || !strings.Contains(err.Error(), "## Alice") {
+		t.Errorf("expected an error suggesting speaker headings, got %v", err)
+	}
+}
+
+func TestParseJSONTranscript(t *testing.T) {
+	parsed := parse(t, `[
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "name": "Alice", "content": "Hi", "timestamp": "2024-01-15T10:30:00Z"},
+		{"role": "assistant", "content": "Hello", "timestamp": 1705314660}
+	]`, ImportJSON)
+
+	if len(parsed.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %+v", parsed.Messages)
+	}
+	speakers := []string{parsed.Messages[0].Speaker, parsed.Messages[1].Speaker, parsed.Messages[2].Speaker}
+	if strings.Join(speakers, ",") != "Moderator,Alice,assistant" {
+		t.Errorf("unexpected speakers: %v", speakers)
+	}
+	if parsed.Messages[2].TimestampMillis != 1705314660000 {
+		t.Errorf("expected epoch seconds to be converted, got %d", parsed.Messages[2].TimestampMillis)
+	}
+}
+
+func TestParseJSONTranscriptErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not an array":  `{"role": "user"}`,
+		"no speaker":    `[{"content": "hi"}]`,
+		"no content":    `[{"role": "user"}]`,
+		"bad timestamp": `[{"role": "user", "content": "hi", "timestamp": "yesterday"}]`,
+	} {
+		if _, err := ParseTranscript([]byte(data), ImportJSON); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestImportSession(t *testing.T) {
+	useFileStore(t)
+	messages := []ImportedMessage{
+		{Speaker: "Alice", Content: "Cursors?", TimestampMillis: 1000},
+		{Speaker: "Bob", Content: "Cursors."},
+		{Speaker: "Moderator", Content: "Decided.", TimestampMillis: 5000},
+	}
+	opts := CreateOptions{Metadata: Metadata{Title: "Pagination"}}
+	events, err := ImportSession("sess", messages, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events != 7 || sess.EventCount() != 7 || sess.Metadata.Title != "Pagination" {
+		t.Fatalf("unexpected session: %d events, %+v", sess.EventCount(), sess.State)
+	}
+	if !sess.IsActiveParticipant("Alice") || !sess.IsActiveParticipant("Bob") || sess.IsActiveParticipant("Moderator") {
+		t.Errorf("expected Alice and Bob to have joined, got %v", sess.Participants)
+	}
+
+	alice := sess.Events[4].(*MessageEvent)
+	bob := sess.Events[5].(*MessageEvent)
+	if alice.Participant != "Alice" || alice.Next != "Bob" || alice.TimestampMillis != 1000 {
+		t.Errorf("unexpected message: %+v", alice)
+	}
+	if bob.Next != "Moderator" || bob.TimestampMillis != 1000 {
+		t.Errorf("expected Bob to hand off to the Moderator at the previous time, got %+v", bob)
+	}
+	if created := sess.Events[0]; created.GetTimestamp() != 1000 {
+		t.Errorf("expected the session to start at the first message, got %d", created.GetTimestamp())
+	}
+
+	// Imported sessions continue like any other
+	if _, err := PostMessage("sess", "Alice", "Thanks", "", 7); err != nil {
+		t.Errorf("unexpected error posting after import: %v", err)
+	}
+}
+
+func TestImportSessionValidates(t *testing.T) {
+	useFileStore(t)
+
+	_, err := ImportSession("sess", []ImportedMessage{{Speaker: "Alice", Content: "hi"}, {Speaker: "Bob", Content: "  "}}, CreateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "message 2 from 'Bob'") {
+		t.Errorf("expected an error naming the empty message, got %v", err)
+	}
+
+	if _, err := ImportSession("sess", nil, CreateOptions{}); err == nil {
+		t.Error("expected an error for an empty transcript")
+	}
+
+	// A bare "## " heading has no speaker
+	parsed := parse(t, "## Alice\nhi\n\n## \nanonymous\n", ImportMarkdown)
+	if _, err := ImportSession("sess", parsed.Messages, CreateOptions{}); err == nil || !strings.Contains(err.Error(), "message 2 has no speaker") {
+		t.Errorf("expected an error naming the message without a speaker, got %v", err)
+	}
+
+	ReservedNames["System"] = true
+	t.Cleanup(func() { delete(ReservedNames, "System") })
+	_, err = ImportSession("sess", []ImportedMessage{{Speaker: "Alice", Content: "hi"}, {Speaker: "System", Content: "hello"}}, CreateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "message 2: 'System' is a reserved name") {
+		t.Errorf("expected a reserved name error for message 2, got %v", err)
+	}
+
+	if exists, _ := currentStore.Exists("sess"); exists {
+		t.Error("a rejected import should not create a session")
+	}
+}
+
+func TestImportSessionExists(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+
+	_, err := ImportSession("sess", []ImportedMessage{{Speaker: "Alice", Content: "hi"}}, CreateOptions{})
+	if _, ok := err.(*errors.SessionExistsError); !ok {
+		t.Errorf("expected SessionExistsError, got %T: %v", err, err)
+	}
+}
+
+func TestImportExportedMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, newExportSession(t), ExportMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed := parse(t, buf.String(), ImportMarkdown)
+	if parsed.Title != "API review" || len(parsed.Messages) != 2 {
+		t.Fatalf("expected the exported title and 2 messages, got %+v", parsed)
+	}
+	if m := parsed.Messages[1]; m.Speaker != "Bob" || m.Content != "Agreed,\n\"ship\" it" || m.TimestampMillis == 0 {
+		t.Errorf("unexpected message: %+v", m)
+	}
+}
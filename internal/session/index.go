@@ -0,0 +1,246 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// indexVersion is bumped whenever the index format or the derived State
+// changes shape, so indexes written by older versions are rebuilt. Bump
+// snapshotVersion along with it for State changes.
+const indexVersion = 9
+
+// eventIndex is the sidecar index stored next to events.jsonl.
+// It maps event numbers to byte offsets and caches the derived state as of
+// the last indexed event, so readers only decode events they haven't seen.
+type eventIndex struct {
+	Version  int     `json:"version"`
+	Size     int64   `json:"size"`                // bytes of events.jsonl covered by the index
+	Offsets  []int64 `json:"offsets"`             // byte offset of event N at Offsets[N-1]
+	LineHash string  `json:"line_hash,omitempty"` // hash of the last indexed line, as in hash chains
+	State    State   `json:"state"`               // derived state after the last indexed event
+}
+
+func newEventIndex() *eventIndex {
+	return &eventIndex{
+		Version: indexVersion,
+		Offsets: []int64{},
+		State:   newState(),
+	}
+}
+
+// loadIndex reads the index in a session directory, returning nil if it's
+// missing or unreadable, including when it's encrypted and the key isn't
+// available. The index of an encrypted session must be encrypted too.
+func loadIndex(dir string) *eventIndex {
+	data, err := os.ReadFile(filepath.Join(dir, storage.IndexFile))
+	if err != nil {
+		return nil
+	}
+	encrypted := isEncrypted(data)
+	if encrypted {
+		if data, err = openLine(data, lineAD(adIndex, filepath.Base(dir), 0)); err != nil {
+			return nil
+		}
+	}
+
+	var idx eventIndex
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion || idx.State.Encrypted != encrypted {
+		return nil
+	}
+	if idx.State.Participants == nil {
+		idx.State.Participants = make(map[string]bool)
+	}
+	return &idx
+}
+
+// save atomically replaces the index file in a session directory. The index
+// of an encrypted session is encrypted too, as its state holds participant
+// names and metadata.
+func (idx *eventIndex) save(dir string) error {
+	path := filepath.Join(dir, storage.IndexFile)
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if idx.State.Encrypted {
+		if data, err = sealLine(data, lineAD(adIndex, filepath.Base(dir), 0)); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, data)
+}
+
+// validFor checks that the index still describes a prefix of the events file.
+// A log that shrank or was rewritten no longer holds the last indexed line
+// where the index says it does, which marks the index as stale.
+func (idx *eventIndex) validFor(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Size() < idx.Size {
+		return false
+	}
+
+	for _, offset := range idx.Offsets {
+		if offset < 0 || offset >= idx.Size {
+			return false
+		}
+	}
+
+	if idx.Size == 0 {
+		return len(idx.Offsets) == 0
+	}
+
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, idx.Size-1); err != nil || b[0] != '\n' {
+		return false
+	}
+	if len(idx.Offsets) > 0 {
+		line, err := readLineAt(f, idx.Offsets[len(idx.Offsets)-1])
+		if err != nil || hashLine(line) != idx.LineHash {
+			return false
+		}
+		// The cached state must agree with the log on whether the session
+		// is encrypted, which its first line shows
+		prefix := make([]byte, len(encryptedPrefix))
+		if _, err := f.ReadAt(prefix, idx.Offsets[0]); err != nil || isEncrypted(prefix) != idx.State.Encrypted {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadTail implements TailReader using the sidecar index. Events appended
+// since the index was written are decoded and added to it; a missing or
+// stale index is rebuilt from the full log.
+func (s *FileStore) ReadTail(sessionID string, offset int) (*Session, error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(dir, storage.EventsFile))
+	if os.IsNotExist(err) {
+		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	idx := loadIndex(dir)
+	if idx == nil || !idx.validFor(file) {
+		idx = newEventIndex()
+	}
+
+	// Start from the cached state; catching up below extends it
+	session := NewSession(sessionID)
+	session.State = idx.State
+
+	indexedCount := len(idx.Offsets)
+	indexedSize := idx.Size
+
+	// Catch up with events appended since the index was written. Only
+	// newline-terminated lines are indexed; a trailing partial line may be
+	// a write in progress, so it's decoded for this read but not recorded.
+	newEvents, pending, err := idx.catchUp(file, session)
+	if err != nil {
+		return nil, withSessionID(err, sessionID)
+	}
+
+	if len(newEvents) > 0 || idx.Size != indexedSize {
+		idx.State = session.State
+		// Best effort: a read-only session dir shouldn't break reads
+		_ = idx.save(dir)
+	}
+
+	if pending != nil {
+		newEvents = append(newEvents, pending)
+		session.applyEvent(pending)
+	}
+
+	totalCount := indexedCount + len(newEvents)
+	if offset > totalCount {
+		offset = totalCount
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	session.Offset = offset
+
+	// Decode previously indexed events after offset by seeking straight to them
+	if offset < indexedCount {
+		start := idx.Offsets[offset]
+		section := io.NewSectionReader(file, start, indexedSize-start)
+		events, err := readEventsFromReader(section, sessionID, offset+1, session.Encrypted)
+		if corrupt, ok := err.(*errors.CorruptSessionError); ok {
+			// Line numbers are relative to the start of the section
+			corrupt.Line += offset
+		}
+		if err != nil {
+			return nil, withSessionID(err, sessionID)
+		}
+		session.Events = append(session.Events, events...)
+		session.Events = append(session.Events, newEvents...)
+	} else {
+		session.Events = append(session.Events, newEvents[offset-indexedCount:]...)
+	}
+
+	return session, nil
+}
+
+// catchUp extends the index with complete lines past idx.Size, applying each
+// new event to the session's derived state. A trailing unterminated event is
+// returned separately as pending and left unapplied.
+func (idx *eventIndex) catchUp(file *os.File, session *Session) ([]Event, Event, error) {
+	if _, err := file.Seek(idx.Size, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(file)
+	pos := idx.Size
+	newEvents := []Event{}
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineStart := pos
+			pos += int64(len(line))
+			terminated := line[len(line)-1] == '\n'
+			trimmed := bytes.TrimRight(line, "\r\n")
+
+			if len(trimmed) > 0 {
+				event, err := parseStoredEvent(session.ID, len(idx.Offsets)+1, trimmed, session.Encrypted)
+				if err != nil {
+					// Blank lines aren't indexed, so this is the line number
+					// for logs written by council
+					return nil, nil, eventError(err, "", len(idx.Offsets)+1)
+				}
+				if !terminated {
+					return newEvents, event, nil
+				}
+				newEvents = append(newEvents, event)
+				session.applyEvent(event)
+				idx.Offsets = append(idx.Offsets, lineStart)
+				idx.LineHash = hashLine(trimmed)
+			}
+			if terminated {
+				idx.Size = pos
+			}
+		}
+
+		if readErr == io.EOF {
+			return newEvents, nil, nil
+		}
+		if readErr != nil {
+			return nil, nil, readErr
+		}
+	}
+}
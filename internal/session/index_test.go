@@ -0,0 +1,215 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/storage"
+)
+
+// newIndexedSession creates a file-backed session with Alice and Bob joined
+// and one message posted (4 events)
+func newIndexedSession(t *testing.T) *FileStore {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	s := NewFileStore()
+	s.Append("sess", 0, NewSessionCreatedEvent("sess"))
+	s.Append("sess", 1, NewJoinedEvent("Alice"), NewJoinedEvent("Bob"))
+	s.Append("sess", 3, NewMessageEvent("Alice", "hello", "Bob"))
+	return s
+}
+
+func sessionDirPath(t *testing.T, sessionID string) string {
+	t.Helper()
+	dir, err := storage.SessionDirPath(sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return dir
+}
+
+func TestReadTailReturnsOnlyNewEvents(t *testing.T) {
+	s := newIndexedSession(t)
+
+	sess, err := s.ReadTail("sess", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sess.Offset != 2 {
+		t.Errorf("expected offset 2, got %d", sess.Offset)
+	}
+	if len(sess.Events) != 2 {
+		t.Fatalf("expected 2 tail events, got %d", len(sess.Events))
+	}
+	if sess.EventCount() != 4 {
+		t.Errorf("expected event count 4, got %d", sess.EventCount())
+	}
+	if !sess.IsActiveParticipant("Alice") || !sess.IsActiveParticipant("Bob") {
+		t.Error("derived state should include participants joined before the offset")
+	}
+	if sess.LatestMessageNext() != "Bob" {
+		t.Errorf("expected latest next Bob, got %q", sess.LatestMessageNext())
+	}
+}
+
+func TestReadTailWritesIndex(t *testing.T) {
+	s := newIndexedSession(t)
+
+	if _, err := s.ReadTail("sess", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	idx := loadIndex(sessionDirPath(t, "sess"))
+	if idx == nil {
+		t.Fatal("expected index to be written")
+	}
+	if len(idx.Offsets) != 4 {
+		t.Errorf("expected 4 indexed offsets, got %d", len(idx.Offsets))
+	}
+	if idx.Offsets[0] != 0 {
+		t.Errorf("first event should start at offset 0, got %d", idx.Offsets[0])
+	}
+}
+
+func TestReadTailCatchesUpAfterAppend(t *testing.T) {
+	s := newIndexedSession(t)
+	s.ReadTail("sess", 0)
+
+	s.Append("sess", 4, NewLeftEvent("Bob"))
+
+	sess, err := s.ReadTail("sess", 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Events) != 1 {
+		t.Fatalf("expected 1 new event, got %d", len(sess.Events))
+	}
+	if _, ok := sess.Events[0].(*LeftEvent); !ok {
+		t.Errorf("expected left event, got %T", sess.Events[0])
+	}
+	if sess.IsActiveParticipant("Bob") {
+		t.Error("Bob should have left")
+	}
+
+	idx := loadIndex(sessionDirPath(t, "sess"))
+	if idx == nil || len(idx.Offsets) != 5 {
+		t.Errorf("expected index to cover 5 events, got %+v", idx)
+	}
+}
+
+func TestReadTailRebuildsStaleIndex(t *testing.T) {
+	s := newIndexedSession(t)
+	s.ReadTail("sess", 0)
+
+	// Rewrite the log with fewer events than the index covers
+	path, _ := storage.SessionEventsPath("sess")
+	os.WriteFile(path, []byte(`{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Carol"}
+`), 0644)
+
+	sess, err := s.ReadTail("sess", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.EventCount() != 2 {
+		t.Errorf("expected 2 events after rebuild, got %d", sess.EventCount())
+	}
+	if sess.IsActiveParticipant("Alice") || !sess.IsActiveParticipant("Carol") {
+		t.Errorf("expected state rebuilt from new log, got %v", sess.Participants)
+	}
+}
+
+func TestReadTailRebuildsIndexOfEditedLog(t *testing.T) {
+	s := newIndexedSession(t)
+	s.ReadTail("sess", 0)
+
+	// Same size, and every line still starts where the index says it does
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.ReplaceAll(string(data), "Bob", "Eve")), 0644)
+
+	sess, err := s.ReadTail("sess", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.IsActiveParticipant("Bob") || !sess.IsActiveParticipant("Eve") {
+		t.Errorf("expected state rebuilt from the edited log, got %v", sess.Participants)
+	}
+}
+
+func TestReadTailRebuildsCorruptIndex(t *testing.T) {
+	s := newIndexedSession(t)
+
+	os.WriteFile(filepath.Join(sessionDirPath(t, "sess"), storage.IndexFile), []byte("not json"), 0644)
+
+	sess, err := s.ReadTail("sess", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Events) != 1 || sess.EventCount() != 4 {
+		t.Errorf("expected 1 of 4 events, got %d of %d", len(sess.Events), sess.EventCount())
+	}
+}
+
+func TestReadTailIncludesUnterminatedLastEvent(t *testing.T) {
+	s := newIndexedSession(t)
+
+	path, _ := storage.SessionEventsPath("sess")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"type":"left","timestamp_millis":5,"participant":"Alice"}`)
+	f.Close()
+
+	sess, err := s.ReadTail("sess", 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Events) != 1 {
+		t.Fatalf("expected unterminated event to be read, got %d events", len(sess.Events))
+	}
+	if sess.IsActiveParticipant("Alice") {
+		t.Error("Alice should have left")
+	}
+
+	// The unterminated line must not be recorded in the index
+	idx := loadIndex(sessionDirPath(t, "sess"))
+	if idx == nil || len(idx.Offsets) != 4 {
+		t.Fatalf("expected index to cover 4 events, got %+v", idx)
+	}
+	if !idx.State.Participants["Alice"] {
+		t.Error("indexed state should not include the unterminated event")
+	}
+}
+
+func TestReadTailOffsetBeyondCount(t *testing.T) {
+	s := newIndexedSession(t)
+
+	sess, err := s.ReadTail("sess", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Events) != 0 {
+		t.Errorf("expected no events, got %d", len(sess.Events))
+	}
+	if sess.EventCount() != 4 {
+		t.Errorf("expected event count 4, got %d", sess.EventCount())
+	}
+}
+
+func TestFormatStatusWithOffset(t *testing.T) {
+	s := newIndexedSession(t)
+
+	sess, err := s.ReadTail("sess", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := FormatStatus(sess, 3)
+	for _, want := range []string{"Participants: Alice, Bob", "--- #4 | Alice ---", "--- End #4 | Alice | Next: Bob ---"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
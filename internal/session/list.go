@@ -0,0 +1,155 @@
+package session
+
+import (
+	"sort"
+	"strings"
+)
+
+// Summary describes a session for listings
+type Summary struct {
+	ID                 string
+	CreatedMillis      int64 // timestamp of the session_created event
+	LastActivityMillis int64 // timestamp of the latest event
+	EventCount         int
+	Participants       []string // active participants, sorted
+	EverJoined         []string // everyone who has ever joined, sorted
+	Closed             bool     // participants have joined and all have since left
+	Archived           bool     // read-only and excluded from the default listing
+	Metadata           Metadata
+
+	// Err is set for sessions that can't be read, such as a damaged log.
+	// Only ID, Archived and NoKey are filled in then.
+	Err error
+
+	// NoKey is set, along with Err, for encrypted sessions that can't be
+	// read with the configured key
+	NoKey bool
+}
+
+// Summarize derives a listing summary from a loaded session's state, so
+// the session's events don't need to be loaded
+func Summarize(sess *Session) Summary {
+	summary := Summary{
+		ID:                 sess.ID,
+		CreatedMillis:      sess.CreatedMillis,
+		LastActivityMillis: sess.LastActivityMillis,
+		EventCount:         sess.EventCount(),
+		Participants:       sess.ActiveParticipants(),
+		EverJoined:         []string{},
+		Metadata:           sess.Metadata,
+	}
+	sort.Strings(summary.Participants)
+
+	for name := range sess.Participants {
+		if name != "Moderator" {
+			summary.EverJoined = append(summary.EverJoined, name)
+		}
+	}
+	sort.Strings(summary.EverJoined)
+
+	summary.Closed = len(summary.EverJoined) > 0 && len(summary.Participants) == 0
+	return summary
+}
+
+// ListSessions loads a summary of every non-archived session in the current store
+func ListSessions() ([]Summary, error) {
+	ids, err := currentStore.List()
+	if err != nil {
+		return nil, err
+	}
+	return summarizeAll(ids, false)
+}
+
+// ListArchivedSessions loads a summary of every archived session in the current store
+func ListArchivedSessions() ([]Summary, error) {
+	ids, err := currentStore.ListArchived()
+	if err != nil {
+		return nil, err
+	}
+	return summarizeAll(ids, true)
+}
+
+func summarizeAll(ids []string, archived bool) ([]Summary, error) {
+	summaries := make([]Summary, 0, len(ids))
+	for _, id := range ids {
+		// One unreadable session mustn't hide the others. Loading starts
+		// from the latest snapshot, so only recent events are decoded.
+		sess, err := LoadSession(id)
+		if err != nil {
+			summaries = append(summaries, Summary{ID: id, Archived: archived, Err: err, NoKey: isKeyError(err)})
+			continue
+		}
+		summary := Summarize(sess)
+		summary.Archived = archived
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// ListFilter selects sessions in a listing. Zero values match everything.
+type ListFilter struct {
+	ActiveOnly  bool   // exclude closed sessions
+	SinceMillis int64  // only sessions with activity at or after this time
+	Participant string // only sessions this participant has ever joined
+	Tag         string // only sessions with this tag (case-insensitive)
+	Search      string // only sessions whose title, goal or description contains this (case-insensitive)
+}
+
+// Matches checks if a summary passes the filter
+func (f ListFilter) Matches(s Summary) bool {
+	if f.ActiveOnly && s.Closed {
+		return false
+	}
+	if f.SinceMillis > 0 && s.LastActivityMillis < f.SinceMillis {
+		return false
+	}
+	if f.Participant != "" {
+		found := false
+		for _, name := range s.EverJoined {
+			if name == f.Participant {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Tag != "" && !s.Metadata.HasTag(f.Tag) {
+		return false
+	}
+	if f.Search != "" {
+		query := strings.ToLower(f.Search)
+		text := strings.ToLower(s.Metadata.Title + "\n" + s.Metadata.Goal + "\n" + s.Metadata.Description)
+		if !strings.Contains(text, query) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sort orders for listings
+const (
+	SortByActivity = "activity"
+	SortByCreated  = "created"
+	SortByID       = "id"
+	SortByEvents   = "events"
+)
+
+// SortSummaries orders summaries in place. Time and event count orders put
+// the most recent/largest first; ID order is alphabetical.
+func SortSummaries(summaries []Summary, by string) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		switch by {
+		case SortByCreated:
+			return a.CreatedMillis > b.CreatedMillis
+		case SortByID:
+			return a.ID < b.ID
+		case SortByEvents:
+			return a.EventCount > b.EventCount
+		default:
+			return a.LastActivityMillis > b.LastActivityMillis
+		}
+	})
+}
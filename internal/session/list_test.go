@@ -0,0 +1,204 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+func TestSummarize(t *testing.T) {
+	s := NewSession("test")
+	s.addEvent(&SessionCreatedEvent{BaseEvent: BaseEvent{Type: EventTypeSessionCreated, TimestampMillis: 100}, ID: "test"})
+	s.addEvent(&JoinedEvent{BaseEvent: BaseEvent{Type: EventTypeJoined, TimestampMillis: 200}, Participant: "Bob"})
+	s.addEvent(&JoinedEvent{BaseEvent: BaseEvent{Type: EventTypeJoined, TimestampMillis: 300}, Participant: "Alice"})
+	s.addEvent(&LeftEvent{BaseEvent: BaseEvent{Type: EventTypeLeft, TimestampMillis: 400}, Participant: "Bob"})
+
+	summary := Summarize(s)
+
+	if summary.CreatedMillis != 100 {
+		t.Errorf("expected created 100, got %d", summary.CreatedMillis)
+	}
+	if summary.LastActivityMillis != 400 {
+		t.Errorf("expected last activity 400, got %d", summary.LastActivityMillis)
+	}
+	if summary.EventCount != 4 {
+		t.Errorf("expected 4 events, got %d", summary.EventCount)
+	}
+	if len(summary.Participants) != 1 || summary.Participants[0] != "Alice" {
+		t.Errorf("expected active [Alice], got %v", summary.Participants)
+	}
+	if len(summary.EverJoined) != 2 || summary.EverJoined[0] != "Alice" || summary.EverJoined[1] != "Bob" {
+		t.Errorf("expected ever joined [Alice Bob], got %v", summary.EverJoined)
+	}
+	if summary.Closed {
+		t.Error("session with an active participant should not be closed")
+	}
+}
+
+func TestSummarizeClosed(t *testing.T) {
+	s := NewSession("test")
+	s.addEvent(NewSessionCreatedEvent("test"))
+	if Summarize(s).Closed {
+		t.Error("session nobody has joined should not be closed")
+	}
+
+	s.addEvent(NewJoinedEvent("Alice"))
+	s.addEvent(NewLeftEvent("Alice"))
+	if !Summarize(s).Closed {
+		t.Error("session everyone has left should be closed")
+	}
+}
+
+func TestListFilterMatches(t *testing.T) {
+	summary := Summary{
+		ID:                 "test",
+		LastActivityMillis: 1000,
+		EverJoined:         []string{"Alice", "Bob"},
+		Closed:             true,
+		Metadata:           Metadata{Title: "Auth redesign", Goal: "Pick a token format", Tags: []string{"design", "API"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   bool
+	}{
+		{"empty filter", ListFilter{}, true},
+		{"active only excludes closed", ListFilter{ActiveOnly: true}, false},
+		{"since before activity", ListFilter{SinceMillis: 500}, true},
+		{"since after activity", ListFilter{SinceMillis: 2000}, false},
+		{"participant joined", ListFilter{Participant: "Bob"}, true},
+		{"participant never joined", ListFilter{Participant: "Carol"}, false},
+		{"tag present", ListFilter{Tag: "api"}, true},
+		{"tag missing", ListFilter{Tag: "ops"}, false},
+		{"search matches title", ListFilter{Search: "auth"}, true},
+		{"search matches goal", ListFilter{Search: "TOKEN"}, true},
+		{"search no match", ListFilter{Search: "billing"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(summary); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSortSummaries(t *testing.T) {
+	summaries := []Summary{
+		{ID: "b", CreatedMillis: 1, LastActivityMillis: 30, EventCount: 5},
+		{ID: "a", CreatedMillis: 3, LastActivityMillis: 10, EventCount: 9},
+		{ID: "c", CreatedMillis: 2, LastActivityMillis: 20, EventCount: 1},
+	}
+
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{SortByActivity, "bca"},
+		{SortByCreated, "acb"},
+		{SortByID, "abc"},
+		{SortByEvents, "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			SortSummaries(summaries, tt.by)
+			got := ""
+			for _, s := range summaries {
+				got += s.ID
+			}
+			if got != tt.want {
+				t.Errorf("expected order %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+
+	CreateSession("first")
+	CreateSession("second")
+	JoinSession("second", "Alice")
+
+	summaries, err := ListSessions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(summaries))
+	}
+	if summaries[1].ID != "second" || summaries[1].EventCount != 2 {
+		t.Errorf("unexpected summary: %+v", summaries[1])
+	}
+}
+
+func TestListSessionsStartsFromSnapshot(t *testing.T) {
+	dir := newLongSession(t)
+	history, _ := LoadHistory("sess")
+	created := history.Events[0].GetTimestamp()
+	last := history.Events[len(history.Events)-1].GetTimestamp()
+
+	// Garble an early line without moving any other; decoding it would fail
+	path := filepath.Join(dir, storage.EventsFile)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[4] = "{" + strings.Repeat("x", len(lines[4])-2) + "\n"
+	os.WriteFile(path, []byte(strings.Join(lines, "")), 0644)
+
+	summaries, err := ListSessions()
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("unexpected result: %+v, %v", summaries, err)
+	}
+	s := summaries[0]
+	if s.Err != nil {
+		t.Fatalf("events before the snapshot shouldn't be decoded, got %v", s.Err)
+	}
+	if s.EventCount != snapshotInterval+2 || s.CreatedMillis != created || s.LastActivityMillis != last {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+}
+
+func TestListSessionsWithCorruptSession(t *testing.T) {
+	useFileStore(t)
+	CreateSession("healthy")
+	writeLog(t, "broken", validLog+"not json\n")
+	CreateSession("other")
+
+	summaries, err := ListSessions()
+	if err != nil {
+		t.Fatalf("one corrupt session shouldn't fail the listing, got %v", err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("expected 3 sessions, got %+v", summaries)
+	}
+	for _, s := range summaries {
+		broken := s.ID == "broken"
+		if broken != (s.Err != nil) {
+			t.Errorf("%s: unexpected error %v", s.ID, s.Err)
+		}
+		if broken && (s.NoKey || !isErr[*errors.CorruptSessionError](s.Err)) {
+			t.Errorf("expected a CorruptSessionError, got %+v", s)
+		}
+		if !broken && s.EventCount != 1 {
+			t.Errorf("%s: expected 1 event, got %d", s.ID, s.EventCount)
+		}
+	}
+
+	// Garbage collection leaves it alone
+	for i := range summaries {
+		summaries[i].LastActivityMillis = 1
+	}
+	plan := PlanGC(summaries, nil, RetentionPolicy{DeleteAfter: time.Hour}, time.Now())
+	if len(plan.Delete) != 2 {
+		t.Errorf("expected only the readable sessions to be deleted, got %+v", plan.Delete)
+	}
+}
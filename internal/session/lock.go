@@ -0,0 +1,504 @@
+package session
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/amterp/council/internal/config"
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// DefaultLockTimeout is how long to wait for a session lock before giving up
+const DefaultLockTimeout = config.DefaultLockTimeout
+
+// DefaultLockLease is how long a lockfile stays valid without a heartbeat
+// before other processes may break it
+const DefaultLockLease = config.DefaultLockLease
+
+// lockPollInterval is how often a waiting process retries a held lock
+const lockPollInterval = 25 * time.Millisecond
+
+// LockInfo describes the process holding a lock, so processes that time out
+// waiting, and 'council locks', can say who holds it
+type LockInfo struct {
+	PID            int    `json:"pid"`
+	Host           string `json:"host,omitempty"`
+	Command        string `json:"command"`
+	Participant    string `json:"participant,omitempty"`
+	AcquiredMillis int64  `json:"acquired_millis"`
+
+	// Token identifies one acquisition of a lockfile, so a process breaking
+	// a stale lock can tell it apart from a fresh one
+	Token string `json:"token,omitempty"`
+}
+
+// HeldFor returns how long the lock has been held, to the second once it's
+// been held that long
+func (i *LockInfo) HeldFor() time.Duration {
+	held := time.Since(time.UnixMilli(i.AcquiredMillis))
+	if held >= time.Second {
+		return held.Truncate(time.Second)
+	}
+	return held.Truncate(time.Millisecond)
+}
+
+// lockCommand names the running command in lock info, e.g. "council fact
+// set". It's set by the CLI; the full command line isn't recorded since its
+// arguments can hold message content.
+var lockCommand string
+
+// SetLockCommand sets the command name recorded in lock info, or restores
+// the default (the program's name) if name is empty
+func SetLockCommand(name string) {
+	lockCommand = name
+}
+
+// newLockInfo describes the current process as a lock holder
+func newLockInfo(participant string) LockInfo {
+	host, _ := os.Hostname()
+	command := lockCommand
+	if command == "" {
+		command = filepath.Base(os.Args[0])
+	}
+	return LockInfo{
+		PID:            os.Getpid(),
+		Host:           host,
+		Command:        command,
+		Participant:    participant,
+		AcquiredMillis: Now(),
+	}
+}
+
+// Locker is a strategy for taking exclusive locks on session files
+type Locker interface {
+	// Acquire opens the file at path and locks it, waiting up to timeout
+	// for another holder to release it. participant is recorded in the lock
+	// info, if known. Returns *errors.LockTimeoutError, without a session
+	// ID, if the lock is still held after timeout.
+	Acquire(path string, timeout time.Duration, participant string) (*FileLocker, error)
+
+	// Holder checks if another process holds the lock on path, returning
+	// its info if it recorded any
+	Holder(path string) (held bool, info *LockInfo, err error)
+}
+
+// FileLocker is an exclusive lock on an open file
+type FileLocker struct {
+	file    *os.File
+	release func() error // releases the strategy's lock; the file is closed after
+	check   func() error // reports a lock lost while held; nil if it can't be
+}
+
+// Release unlocks and closes the file
+func (l *FileLocker) Release() error {
+	err := l.release()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Check reports an error if the lock was lost while held, as a lockfile is
+// when its lease lapses (say while the process was paused) and another
+// process breaks it. Writers call it right before writing, so a lost lock
+// fails the write instead of only being noticed on release.
+func (l *FileLocker) Check() error {
+	if l.check == nil {
+		return nil
+	}
+	return l.check()
+}
+
+// releaseLock releases a lock from a deferred call, reporting its error, such
+// as a lockfile broken while held, through err unless err is already set
+func releaseLock(lock *FileLocker, err *error) {
+	if releaseErr := lock.Release(); *err == nil {
+		*err = releaseErr
+	}
+}
+
+// File returns the underlying file for reading/writing
+func (l *FileLocker) File() *os.File {
+	return l.file
+}
+
+// AcquireLock locks the file at path with flock(2). See Locker.Acquire.
+func AcquireLock(path string, timeout time.Duration, participant string) (*FileLocker, error) {
+	return FlockLocker{}.Acquire(path, timeout, participant)
+}
+
+// lockTimeoutError describes a lock that's still held after waiting
+func lockTimeoutError(waited time.Duration, info *LockInfo) error {
+	err := &errors.LockTimeoutError{Waited: waited}
+	if info != nil {
+		err.HolderPID = info.PID
+		err.HolderHost = info.Host
+		err.HolderCommand = info.Command
+		err.HolderParticipant = info.Participant
+		err.HeldFor = info.HeldFor()
+	}
+	return err
+}
+
+// readLockInfo reads lock info from a file, returning nil if it's missing
+// or unreadable
+func readLockInfo(path string) *LockInfo {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.PID == 0 {
+		return nil
+	}
+	return &info
+}
+
+// FlockLocker locks session files with flock(2), which the kernel releases
+// when the holder exits. The holder's info is written to a sidecar file next
+// to the locked file while the lock is held.
+type FlockLocker struct{}
+
+// Acquire implements Locker
+func (FlockLocker) Acquire(path string, timeout time.Duration, participant string) (*FileLocker, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK {
+			f.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, lockTimeoutError(timeout, readLockInfo(path+storage.LockInfoSuffix))
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	// Best effort: the info is only used for diagnostics
+	infoPath := path + storage.LockInfoSuffix
+	if data, err := json.Marshal(newLockInfo(participant)); err == nil {
+		_ = writeFileAtomic(infoPath, data)
+	}
+
+	return &FileLocker{file: f, release: func() error {
+		os.Remove(infoPath)
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}}, nil
+}
+
+// Holder implements Locker by trying to take the lock without waiting. A
+// sidecar left behind by a killed process is ignored, as its lock is gone.
+func (FlockLocker) Holder(path string) (bool, *LockInfo, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	defer f.Close()
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return true, readLockInfo(path + storage.LockInfoSuffix), nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false, nil, nil
+}
+
+// LockfileLocker locks a session file by exclusively creating a lockfile
+// next to it, which works on network filesystems where flock(2) is
+// unreliable. The holder refreshes the lockfile's modification time as a
+// heartbeat; a lockfile not refreshed within the lease, or left by a dead
+// process on this host, is stale and broken by the next process to wait
+// for it. Hosts sharing a directory need roughly synchronized clocks.
+type LockfileLocker struct {
+	// Lease is how long a lockfile stays valid without a heartbeat.
+	// DefaultLockLease is used if it's zero.
+	Lease time.Duration
+}
+
+func (l LockfileLocker) lease() time.Duration {
+	if l.Lease <= 0 {
+		return DefaultLockLease
+	}
+	return l.Lease
+}
+
+// Acquire implements Locker
+func (l LockfileLocker) Acquire(path string, timeout time.Duration, participant string) (*FileLocker, error) {
+	lockPath := path + storage.LockFileSuffix
+	info := newLockInfo(participant)
+	info.Token = newLockToken()
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := createLockfile(lockPath, data)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if broken, err := l.breakStale(lockPath); err != nil {
+			return nil, err
+		} else if broken {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, lockTimeoutError(timeout, readLockInfo(lockPath))
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		os.Remove(lockPath)
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(l.lease() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(lockPath, now, now)
+			}
+		}
+	}()
+
+	// If the lease lapsed and another process broke the lock, the lockfile
+	// is now theirs, or gone if they've released it too
+	lost := fmt.Errorf("lost the lock on %s: it was broken as stale while held", path)
+	check := func() error {
+		if current := readLockInfo(lockPath); current == nil || current.Token != info.Token {
+			return lost
+		}
+		return nil
+	}
+	return &FileLocker{file: f, check: check, release: func() error {
+		close(stop)
+		<-done
+		// Archiving or deleting the session also takes the lockfile with
+		// it, but then the log is gone as well
+		if current := readLockInfo(lockPath); current != nil && current.Token != info.Token {
+			return lost
+		}
+		err := os.Remove(lockPath)
+		if os.IsNotExist(err) {
+			if _, statErr := os.Stat(path); statErr == nil {
+				return lost
+			}
+			return nil
+		}
+		return err
+	}}, nil
+}
+
+// Holder implements Locker. Stale lockfiles don't count as held.
+func (l LockfileLocker) Holder(path string) (bool, *LockInfo, error) {
+	lockPath := path + storage.LockFileSuffix
+	stale, err := l.isStale(lockPath)
+	if os.IsNotExist(err) {
+		return false, nil, nil
+	}
+	if err != nil || stale {
+		return false, nil, err
+	}
+	return true, readLockInfo(lockPath), nil
+}
+
+// createLockfile creates a lockfile holding data, failing if it exists
+func createLockfile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// isStale checks if a lockfile's lease has lapsed or its holder has died
+func (l LockfileLocker) isStale(lockPath string) (bool, error) {
+	stat, err := os.Stat(lockPath)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(stat.ModTime()) > l.lease() {
+		return true, nil
+	}
+
+	info := readLockInfo(lockPath)
+	if info == nil {
+		// Possibly still being written
+		return false, nil
+	}
+	host, _ := os.Hostname()
+	return info.Host == host && !processAlive(info.PID), nil
+}
+
+// breakStale removes a stale lockfile. The lockfile is moved aside first
+// and put back if it turns out to have been replaced by a live holder in
+// the meantime, so two processes breaking the same lock can't remove a
+// fresh one. Returns true if a lock was broken.
+func (l LockfileLocker) breakStale(lockPath string) (bool, error) {
+	stale, err := l.isStale(lockPath)
+	if os.IsNotExist(err) {
+		// Released while we looked; retry right away
+		return true, nil
+	}
+	if err != nil || !stale {
+		return false, err
+	}
+	before, err := os.ReadFile(lockPath)
+	if err != nil {
+		return os.IsNotExist(err), nil
+	}
+
+	aside := fmt.Sprintf("%s.broken-%s", lockPath, newLockToken())
+	if err := os.Rename(lockPath, aside); err != nil {
+		return os.IsNotExist(err), nil
+	}
+	defer os.Remove(aside)
+
+	after, err := os.ReadFile(aside)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(before, after) {
+		// Someone else broke the stale lock and took a fresh one. Put it
+		// back unless yet another process has locked since.
+		os.Link(aside, lockPath)
+		return false, nil
+	}
+	return true, nil
+}
+
+// processAlive checks if a process with the given pid exists on this host
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// newLockToken returns a random token identifying a lock acquisition
+func newLockToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// HeldLock is a session lock currently held by a process
+type HeldLock struct {
+	SessionID string
+	Archived  bool
+	Info      *LockInfo // nil if the holder didn't record itself
+}
+
+// LockLister is implemented by stores with per-session locks that can be inspected
+type LockLister interface {
+	// HeldLocks returns the session locks currently held: active sessions
+	// first, then archived ones, each sorted by session ID
+	HeldLocks() ([]HeldLock, error)
+}
+
+// ListLocks returns the session locks currently held in the current store.
+// ok is false if the store has no per-session locks.
+func ListLocks() (locks []HeldLock, ok bool, err error) {
+	lister, ok := currentStore.(LockLister)
+	if !ok {
+		return nil, false, nil
+	}
+	locks, err = lister.HeldLocks()
+	return locks, true, err
+}
+
+// locker returns the store's locking strategy
+func (s *FileStore) locker() Locker {
+	if s.Locker == nil {
+		return FlockLocker{}
+	}
+	return s.Locker
+}
+
+// lock acquires a session's lock on the file at path, with the store's timeout
+func (s *FileStore) lock(sessionID, path, participant string) (*FileLocker, error) {
+	timeout := s.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	lock, err := s.locker().Acquire(path, timeout, participant)
+	if timeoutErr, ok := err.(*errors.LockTimeoutError); ok {
+		timeoutErr.SessionID = sessionID
+	}
+	return lock, err
+}
+
+// HeldLocks implements LockLister
+func (s *FileStore) HeldLocks() ([]HeldLock, error) {
+	locks := []HeldLock{}
+	for _, archived := range []bool{false, true} {
+		ids, err := s.List()
+		if archived {
+			ids, err = s.ListArchived()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			dir, err := storage.SessionDirPath(id)
+			if archived {
+				dir, err = storage.ArchivedSessionDirPath(id)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			held, info, err := s.locker().Holder(filepath.Join(dir, storage.EventsFile))
+			if err != nil {
+				return nil, err
+			}
+			if held {
+				locks = append(locks, HeldLock{SessionID: id, Archived: archived, Info: info})
+			}
+		}
+	}
+	return locks, nil
+}
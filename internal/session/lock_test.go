@@ -0,0 +1,151 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+func TestAcquireLockWritesInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	SetLockCommand("council post")
+	t.Cleanup(func() { SetLockCommand("") })
+
+	lock, err := AcquireLock(path, time.Second, "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info := readLockInfo(path + storage.LockInfoSuffix)
+	if info == nil {
+		t.Fatal("expected lock info while the lock is held")
+	}
+	if info.PID != os.Getpid() || info.Participant != "Alice" || info.Command != "council post" {
+		t.Errorf("unexpected lock info: %+v", info)
+	}
+
+	lock.Release()
+	if readLockInfo(path+storage.LockInfoSuffix) != nil {
+		t.Error("expected lock info to be removed on release")
+	}
+}
+
+func TestAcquireLockTimesOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Locks are per open file, so a second acquire in this process waits
+	held, err := AcquireLock(path, time.Second, "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer held.Release()
+
+	start := time.Now()
+	_, err = AcquireLock(path, 100*time.Millisecond, "Bob")
+	timeout, ok := err.(*errors.LockTimeoutError)
+	if !ok {
+		t.Fatalf("expected LockTimeoutError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected to give up after about 100ms, took %s", elapsed)
+	}
+	if timeout.HolderPID != os.Getpid() || timeout.HolderParticipant != "Alice" {
+		t.Errorf("expected the holder to be named, got %+v", timeout)
+	}
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	held, _ := AcquireLock(path, time.Second, "Alice")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		held.Release()
+	}()
+
+	lock, err := AcquireLock(path, 5*time.Second, "Bob")
+	if err != nil {
+		t.Fatalf("expected lock once released, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestFileStoreAppendLockTimeout(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	CurrentStore().(*FileStore).LockTimeout = 50 * time.Millisecond
+
+	path, _ := storage.SessionEventsPath("sess")
+	held, _ := AcquireLock(path, time.Second, "Alice")
+	defer held.Release()
+
+	_, err := JoinSession("sess", "Bob")
+	timeout, ok := err.(*errors.LockTimeoutError)
+	if !ok {
+		t.Fatalf("expected LockTimeoutError, got %v", err)
+	}
+	if timeout.SessionID != "sess" {
+		t.Errorf("expected session ID in error, got %q", timeout.SessionID)
+	}
+	if !strings.Contains(err.Error(), "(Alice)") {
+		t.Errorf("expected holder in message, got %q", err.Error())
+	}
+}
+
+func TestHeldLocks(t *testing.T) {
+	useFileStore(t)
+	CreateSession("idle")
+	CreateSession("busy")
+
+	locks, ok, err := ListLocks()
+	if err != nil || !ok {
+		t.Fatalf("unexpected result: %v, %v", ok, err)
+	}
+	if len(locks) != 0 {
+		t.Errorf("expected no held locks, got %+v", locks)
+	}
+
+	path, _ := storage.SessionEventsPath("busy")
+	held, _ := AcquireLock(path, time.Second, "Alice")
+	defer held.Release()
+
+	locks, _, _ = ListLocks()
+	if len(locks) != 1 || locks[0].SessionID != "busy" {
+		t.Fatalf("expected busy to be locked, got %+v", locks)
+	}
+	if locks[0].Info == nil || locks[0].Info.Participant != "Alice" {
+		t.Errorf("expected lock info for busy, got %+v", locks[0].Info)
+	}
+}
+
+func TestHeldLocksIgnoresStaleInfo(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+
+	// Info left behind by a process that died holding the lock
+	path, _ := storage.SessionEventsPath("sess")
+	os.WriteFile(path+storage.LockInfoSuffix, []byte(`{"pid":99999,"command":"council post"}`), 0644)
+
+	locks, _, _ := ListLocks()
+	if len(locks) != 0 {
+		t.Errorf("expected stale info to be ignored, got %+v", locks)
+	}
+}
+
+func TestArchiveRemovesLockInfo(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+
+	if err := ArchiveSession("sess"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir, _ := storage.ArchivedSessionDirPath("sess")
+	if _, err := os.Stat(filepath.Join(dir, storage.EventsFile+storage.LockInfoSuffix)); !os.IsNotExist(err) {
+		t.Errorf("expected no lock info in the archive, got %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+func TestLockfileExcludesOtherHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	locker := LockfileLocker{Lease: time.Minute}
+
+	held, err := locker.Acquire(path, time.Second, "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = locker.Acquire(path, 100*time.Millisecond, "Bob")
+	timeout, ok := err.(*errors.LockTimeoutError)
+	if !ok {
+		t.Fatalf("expected LockTimeoutError, got %v", err)
+	}
+	if timeout.HolderPID != os.Getpid() || timeout.HolderParticipant != "Alice" {
+		t.Errorf("expected the holder to be named, got %+v", timeout)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + storage.LockFileSuffix); !os.IsNotExist(err) {
+		t.Error("expected lockfile to be removed on release")
+	}
+
+	lock, err := locker.Acquire(path, time.Second, "Bob")
+	if err != nil {
+		t.Fatalf("expected lock once released, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestLockfileBreaksExpiredLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	lockPath := path + storage.LockFileSuffix
+
+	// A holder on another host that stopped heartbeating a minute ago
+	os.WriteFile(lockPath, []byte(`{"pid":1,"host":"elsewhere","command":"council post","acquired_millis":1}`), 0644)
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(lockPath, old, old)
+
+	lock, err := LockfileLocker{Lease: time.Second}.Acquire(path, time.Second, "Bob")
+	if err != nil {
+		t.Fatalf("expected stale lock to be broken, got %v", err)
+	}
+	defer lock.Release()
+
+	if info := readLockInfo(lockPath); info == nil || info.Participant != "Bob" {
+		t.Errorf("expected lockfile to be Bob's, got %+v", info)
+	}
+}
+
+func TestLockfileBreaksDeadLocalHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	lockPath := path + storage.LockFileSuffix
+
+	// A process that exited without releasing, with a fresh lease
+	cmd := exec.Command("true")
+	cmd.Run()
+	host, _ := os.Hostname()
+	os.WriteFile(lockPath, []byte(fmt.Sprintf(`{"pid":%d,"host":%q,"command":"council post","acquired_millis":1}`,
+		cmd.Process.Pid, host)), 0644)
+
+	lock, err := LockfileLocker{Lease: time.Hour}.Acquire(path, time.Second, "Bob")
+	if err != nil {
+		t.Fatalf("expected dead holder's lock to be broken, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestLockfileHeartbeatKeepsLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	locker := LockfileLocker{Lease: 150 * time.Millisecond}
+
+	held, err := locker.Acquire(path, time.Second, "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer held.Release()
+
+	// Waiting well past the lease, the holder's heartbeat must keep it valid
+	if _, err := locker.Acquire(path, 500*time.Millisecond, "Bob"); err == nil {
+		t.Fatal("expected a live holder's lock not to be broken")
+	}
+	if held, _, _ := locker.Holder(path); !held {
+		t.Error("expected the lock to still be held")
+	}
+}
+
+func TestLockfileReleaseAfterLostLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	lockPath := path + storage.LockFileSuffix
+
+	held, _ := LockfileLocker{}.Acquire(path, time.Second, "Alice")
+	os.WriteFile(lockPath, []byte(`{"pid":1,"host":"elsewhere","command":"council post","acquired_millis":1,"token":"other"}`), 0644)
+
+	if err := held.Release(); err == nil {
+		t.Error("expected release to report the lost lock")
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Error("release must not remove another holder's lockfile")
+	}
+}
+
+func TestLockfileReleaseAfterLostLockWasReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	os.WriteFile(path, nil, 0644)
+
+	// Another process broke the lock and has since released it
+	held, _ := LockfileLocker{}.Acquire(path, time.Second, "Alice")
+	os.Remove(path + storage.LockFileSuffix)
+
+	if err := held.Release(); err == nil {
+		t.Error("expected release to report the lost lock")
+	}
+}
+
+// breakingLocker takes a lockfile, then lets another process break it
+// before the holder releases it
+type breakingLocker struct {
+	LockfileLocker
+}
+
+func (l breakingLocker) Acquire(path string, timeout time.Duration, participant string) (*FileLocker, error) {
+	lock, err := l.LockfileLocker.Acquire(path, timeout, participant)
+	if err == nil {
+		os.WriteFile(path+storage.LockFileSuffix, []byte(`{"pid":1,"host":"elsewhere","command":"council post","acquired_millis":1,"token":"other"}`), 0644)
+	}
+	return lock, err
+}
+
+func TestAppendReportsLostLock(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	CurrentStore().(*FileStore).Locker = breakingLocker{}
+
+	if _, err := JoinSession("sess", "Alice"); err == nil || !strings.Contains(err.Error(), "lost the lock") {
+		t.Errorf("expected the lost lock to be reported, got %v", err)
+	}
+
+	// Noticed before writing, so the new holder's view of the log holds
+	CurrentStore().(*FileStore).Locker = LockfileLocker{}
+	sess, err := LoadHistory("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.EventCount() != 1 {
+		t.Errorf("nothing should be appended under a lost lock, got %d events", sess.EventCount())
+	}
+}
+
+func TestLockfileArchiveAndDelete(t *testing.T) {
+	useFileStore(t)
+	CurrentStore().(*FileStore).Locker = LockfileLocker{}
+	CreateSession("old")
+	CreateSession("gone")
+
+	// The lockfile goes with the session, which isn't a lost lock
+	if err := ArchiveSession("old"); err != nil {
+		t.Errorf("unexpected error archiving: %v", err)
+	}
+	if err := DeleteSession("gone"); err != nil {
+		t.Errorf("unexpected error deleting: %v", err)
+	}
+}
+
+func TestLockfileHeldLocks(t *testing.T) {
+	useFileStore(t)
+	CurrentStore().(*FileStore).Locker = LockfileLocker{}
+	CreateSession("busy")
+
+	path, _ := storage.SessionEventsPath("busy")
+	held, _ := LockfileLocker{}.Acquire(path, time.Second, "Alice")
+	defer held.Release()
+
+	locks, _, err := ListLocks()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(locks) != 1 || locks[0].Info == nil || locks[0].Info.Participant != "Alice" {
+		t.Errorf("expected busy to be locked by Alice, got %+v", locks)
+	}
+}
+
+// Environment for TestConcurrentWriterProcess, which is run as a child process
+const (
+	writerEnvSession  = "COUNCIL_TEST_WRITER_SESSION"
+	writerEnvStrategy = "COUNCIL_TEST_WRITER_STRATEGY"
+	writerEnvName     = "COUNCIL_TEST_WRITER_NAME"
+	writerEnvCount    = "COUNCIL_TEST_WRITER_COUNT"
+)
+
+// TestConcurrentWriterProcess posts messages to a session when run as a
+// child of TestConcurrentWritersAcrossProcesses; otherwise it does nothing
+func TestConcurrentWriterProcess(t *testing.T) {
+	sessionID := os.Getenv(writerEnvSession)
+	if sessionID == "" {
+		t.Skip("only runs as a child process")
+	}
+
+	store := NewFileStore()
+	store.LockTimeout = 30 * time.Second
+	if os.Getenv(writerEnvStrategy) == "lockfile" {
+		store.Locker = LockfileLocker{Lease: 5 * time.Second}
+	}
+	SetStore(store)
+
+	name := os.Getenv(writerEnvName)
+	count, _ := strconv.Atoi(os.Getenv(writerEnvCount))
+	for i := 0; i < count; i++ {
+		content := fmt.Sprintf("%s-%d", name, i)
+		_, err := appendWithRetry(sessionID, func(*Session) (Event, error) {
+			return NewMessageEvent(name, content, "Moderator"), nil
+		})
+		if err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+}
+
+func TestConcurrentWritersAcrossProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+
+	const writers, perWriter = 4, 25
+	for _, strategy := range []string{"flock", "lockfile"} {
+		t.Run(strategy, func(t *testing.T) {
+			useFileStore(t)
+			CreateSession("sess")
+
+			cmds := make([]*exec.Cmd, writers)
+			for i := range cmds {
+				cmd := exec.Command(os.Args[0], "-test.run=^TestConcurrentWriterProcess$")
+				cmd.Env = append(os.Environ(),
+					writerEnvSession+"=sess",
+					writerEnvStrategy+"="+strategy,
+					fmt.Sprintf("%s=writer%d", writerEnvName, i),
+					fmt.Sprintf("%s=%d", writerEnvCount, perWriter))
+				if err := cmd.Start(); err != nil {
+					t.Fatalf("failed to start writer: %v", err)
+				}
+				cmds[i] = cmd
+			}
+			for i, cmd := range cmds {
+				if err := cmd.Wait(); err != nil {
+					t.Errorf("writer %d failed: %v", i, err)
+				}
+			}
+
+			sess, err := LoadHistory("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sess.EventCount() != 1+writers*perWriter {
+				t.Errorf("expected %d events, got %d", 1+writers*perWriter, sess.EventCount())
+			}
+
+			seen := map[string]bool{}
+			for _, event := range sess.Events {
+				if msg, ok := event.(*MessageEvent); ok {
+					if seen[msg.Content] {
+						t.Errorf("message %q was written twice", msg.Content)
+					}
+					seen[msg.Content] = true
+				}
+			}
+			if len(seen) != writers*perWriter {
+				t.Errorf("expected %d distinct messages, got %d", writers*perWriter, len(seen))
+			}
+
+			report, _ := CheckSession("sess")
+			for _, p := range report.Problems {
+				// Writers post without joining; only log damage matters here
+				if !strings.HasPrefix(p.Message, "message from") {
+					t.Errorf("line %d: %s", p.Line, p.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseMentions finds the @Name mentions of active participants and the
+// Moderator in content, in the order they first appear. Names are matched
+// case-insensitively and recorded as on the roster; the longest name wins, so
+// names with spaces work. Anything else after an @, like an unknown name or
+// an email address, is left as plain text, and so are mentions of the author.
+func parseMentions(session *Session, author, content string) []string {
+	roster := append(session.ActiveParticipants(), "Moderator")
+	sort.Slice(roster, func(i, j int) bool { return len(roster[i]) > len(roster[j]) })
+
+	var mentions []string
+	seen := map[string]bool{author: true}
+	for i := 0; i < len(content); i++ {
+		if content[i] != '@' {
+			continue
+		}
+		if prev, _ := utf8.DecodeLastRuneInString(content[:i]); i > 0 && isNameRune(prev) {
+			continue
+		}
+		rest := content[i+1:]
+		for _, name := range roster {
+			if len(rest) < len(name) || !strings.EqualFold(rest[:len(name)], name) {
+				continue
+			}
+			if next, _ := utf8.DecodeRuneInString(rest[len(name):]); len(rest) > len(name) && isNameRune(next) {
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				mentions = append(mentions, name)
+			}
+			i += len(name)
+			break
+		}
+	}
+	return mentions
+}
+
+// isNameRune checks if r can continue a name, so that a mention of "Al"
+// doesn't match "@Alice" and "bob@example.com" isn't a mention of "example"
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Mentions returns the event numbers of the loaded messages that mention a
+// participant and were posted, or edited to their current content, after
+// event number after, in the order that happened. Retracted messages are
+// skipped, and so are messages whose latest edit no longer mentions them.
+func (s *Session) Mentions(participant string, after int) []int {
+	revisions := s.Revisions()
+	var nums []int
+	seen := map[int]bool{}
+	for i, event := range s.Events {
+		eventNum := s.Offset + i + 1
+		if eventNum <= after {
+			continue
+		}
+		var message int
+		var mentions []string
+		switch e := event.(type) {
+		case *MessageEvent:
+			message, mentions = eventNum, e.Mentions
+		case *MessageEditedEvent:
+			message = e.Message
+		default:
+			continue
+		}
+		r := revisions[message]
+		if r.Retracted() || seen[message] {
+			continue
+		}
+		if r != nil && r.Edited {
+			mentions = r.Mentions
+		}
+		if slices.Contains(mentions, participant) {
+			seen[message] = true
+			nums = append(nums, message)
+		}
+	}
+	return nums
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Al")
+	JoinSession("sess", "Security Reviewer")
+	JoinSession("sess", "Bob")
+	LeaveSession("sess", "Bob")
+	sess, _ := LoadSession("sess")
+
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"@Alice, what about @al?", []string{"Alice", "Al"}},
+		{"Over to @Security Reviewer. @alice @Alice", []string{"Security Reviewer", "Alice"}},
+		{"Ask the @Moderator", []string{"Moderator"}},
+		{"Mail al@example.com or @Alicia, @Bob left", nil},
+		{"Note to self, @Carol", nil},
+		{"(@Al)", []string{"Al"}},
+	}
+	for _, tt := range tests {
+		if got := parseMentions(sess, "Carol", tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMentions(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+	if got := parseMentions(sess, "Alice", "I'm @Alice"); got != nil {
+		t.Errorf("authors shouldn't mention themselves, got %v", got)
+	}
+}
+
+func TestPostRecordsMentions(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			CreateSession("sess")
+			JoinSession("sess", "Alice")
+			JoinSession("sess", "Bob")
+			JoinSession("sess", "Carol")
+
+			PostMessage("sess", "Alice", "Bob, your call. @carol, does this break the SDK?", "Bob", 4)
+			PostMessage("sess", "Bob", "Going with REST", "Alice", 5)
+			PostMessage("sess", "Bob", "@Carol ping", "Alice", 6)
+			RetractMessage("sess", "Bob", 7)
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if msg := sess.Event(5).(*MessageEvent); !reflect.DeepEqual(msg.Mentions, []string{"Carol"}) {
+				t.Errorf("expected Carol to be mentioned, got %v", msg.Mentions)
+			}
+			if got := sess.Mentions("Carol", 0); !reflect.DeepEqual(got, []int{5}) {
+				t.Errorf("expected a mention at #5 only, retracted ones left out, got %v", got)
+			}
+			if got := sess.Mentions("Carol", 5); got != nil {
+				t.Errorf("expected no mentions after #5, got %v", got)
+			}
+
+			out := FormatStatus(sess, 0)
+			if !strings.Contains(out, "--- #5 | Alice (mentions @Carol) ---") {
+				t.Errorf("status should highlight mentions:\n%s", out)
+			}
+			if strings.Contains(out, "--- #7 | Bob (mentions") {
+				t.Errorf("retracted messages shouldn't list mentions:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestEditUpdatesMentions(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			CreateSession("sess")
+			JoinSession("sess", "Alice")
+			JoinSession("sess", "Bob")
+			JoinSession("sess", "Carol")
+
+			PostMessage("sess", "Alice", "@Bob, your call", "Bob", 4)
+			PostMessage("sess", "Bob", "Going with REST", "Alice", 5)
+			// The Moderator edits for Alice: mentions are still Alice's
+			if _, err := EditMessage("sess", "Moderator", 5, "@Carol, your call. @Alice?"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sess, _ := LoadSession("sess")
+			if got := sess.Mentions("Carol", 6); !reflect.DeepEqual(got, []int{5}) {
+				t.Errorf("an edit adding a mention should wake Carol for #5, got %v", got)
+			}
+			if got := sess.Mentions("Bob", 0); got != nil {
+				t.Errorf("an edit removing a mention shouldn't wake Bob, got %v", got)
+			}
+			if got := sess.Mentions("Alice", 0); got != nil {
+				t.Errorf("authors shouldn't mention themselves, got %v", got)
+			}
+
+			out := FormatStatus(sess, 0)
+			if !strings.Contains(out, "--- #5 | Alice (edited) (mentions @Carol) ---") {
+				t.Errorf("status should show the edited mentions:\n%s", out)
+			}
+
+			// Fixing a typo in a message that still mentions Carol wakes her
+			// again, since its content changed
+			EditMessage("sess", "Alice", 5, "@Carol, your call!")
+			sess, _ = LoadSession("sess")
+			if got := sess.Mentions("Carol", 7); !reflect.DeepEqual(got, []int{5}) {
+				t.Errorf("expected the second edit to mention Carol, got %v", got)
+			}
+		})
+	}
+}
+
+func TestCheckSessionMentions(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+
+		`{"type":"message","timestamp_millis":4,"participant":"Alice","content":"@Bob?","next":"Moderator","mentions":["Bob"]}
+{"type":"message","timestamp_millis":5,"participant":"Alice","content":"@Moderator?","next":"Moderator","mentions":["Moderator"]}
+`)
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 1 {
+		t.Fatalf("expected 1 problem, got %+v", report.Problems)
+	}
+	if report.Problems[0].Line != 4 || !strings.Contains(report.Problems[0].Message, "mentioned 'Bob'") {
+		t.Errorf("unexpected problem: %+v", report.Problems[0])
+	}
+}
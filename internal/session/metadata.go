@@ -0,0 +1,114 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// Metadata describes what a session is about, so participants joining
+// later can orient themselves without asking
+type Metadata struct {
+	Title       string   `json:"title,omitempty"`
+	Goal        string   `json:"goal,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// IsEmpty checks if no metadata has been set
+func (m Metadata) IsEmpty() bool {
+	return m.Title == "" && m.Goal == "" && m.Description == "" && len(m.Tags) == 0
+}
+
+// HasTag checks if the metadata includes a tag, ignoring case
+func (m Metadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalized trims text fields and validates and de-duplicates tags
+func (m Metadata) normalized() (Metadata, error) {
+	result := Metadata{
+		Title:       strings.TrimSpace(m.Title),
+		Goal:        strings.TrimSpace(m.Goal),
+		Description: strings.TrimSpace(m.Description),
+	}
+	for _, tag := range m.Tags {
+		if err := validateTag(tag); err != nil {
+			return Metadata{}, err
+		}
+		if !result.HasTag(tag) {
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+	return result, nil
+}
+
+func validateTag(tag string) error {
+	if tag == "" || strings.ContainsAny(tag, " \t\r\n,") {
+		return &errors.InvalidTagError{Tag: tag}
+	}
+	return nil
+}
+
+// MetadataUpdate describes changes to a session's metadata.
+// Nil fields are left unchanged.
+type MetadataUpdate struct {
+	Title       *string
+	Goal        *string
+	Description *string
+	Tags        []string // replaces all tags when non-nil
+	AddTags     []string
+	RemoveTags  []string
+}
+
+// apply returns the metadata with the update applied
+func (u MetadataUpdate) apply(m Metadata) Metadata {
+	if u.Title != nil {
+		m.Title = *u.Title
+	}
+	if u.Goal != nil {
+		m.Goal = *u.Goal
+	}
+	if u.Description != nil {
+		m.Description = *u.Description
+	}
+
+	tags := m.Tags
+	if u.Tags != nil {
+		tags = u.Tags
+	}
+	tags = append(append([]string{}, tags...), u.AddTags...)
+
+	m.Tags = nil
+	for _, tag := range tags {
+		removed := false
+		for _, r := range u.RemoveTags {
+			if strings.EqualFold(tag, r) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	return m
+}
+
+// UpdateMetadata applies an update to a session's metadata and records the
+// result in a session_updated event.
+// Returns the new event number (1-indexed for display)
+func UpdateMetadata(sessionID string, update MetadataUpdate) (int, error) {
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		meta, err := update.apply(session.Metadata).normalized()
+		if err != nil {
+			return nil, err
+		}
+		return NewSessionUpdatedEvent(meta), nil
+	})
+}
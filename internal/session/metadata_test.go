@@ -0,0 +1,114 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestCreateSessionWithMetadata(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+
+	meta := Metadata{Title: " Auth redesign ", Goal: "Pick a token format", Tags: []string{"design", "api", "Design"}}
+	if err := CreateSessionWithOptions("sess", CreateOptions{Metadata: meta}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sess, _ := LoadSession("sess")
+	if sess.EventCount() != 2 {
+		t.Fatalf("expected session_created + session_updated, got %d events", sess.EventCount())
+	}
+	if sess.Metadata.Title != "Auth redesign" {
+		t.Errorf("expected trimmed title, got %q", sess.Metadata.Title)
+	}
+	if len(sess.Metadata.Tags) != 2 {
+		t.Errorf("expected duplicate tag to be dropped, got %v", sess.Metadata.Tags)
+	}
+}
+
+func TestCreateSessionWithoutMetadata(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+
+	CreateSessionWithOptions("sess", CreateOptions{})
+
+	sess, _ := LoadSession("sess")
+	if sess.EventCount() != 1 {
+		t.Errorf("empty metadata should not be recorded, got %d events", sess.EventCount())
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+	CreateSessionWithOptions("sess", CreateOptions{Metadata: Metadata{Title: "Old", Goal: "Keep me", Tags: []string{"a", "b"}}})
+
+	eventNum, err := UpdateMetadata("sess", MetadataUpdate{
+		Title:      strPtr("New"),
+		AddTags:    []string{"c"},
+		RemoveTags: []string{"A"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if eventNum != 3 {
+		t.Errorf("expected event #3, got %d", eventNum)
+	}
+
+	sess, _ := LoadSession("sess")
+	got := sess.Metadata
+	if got.Title != "New" || got.Goal != "Keep me" {
+		t.Errorf("unexpected metadata: %+v", got)
+	}
+	if strings.Join(got.Tags, ",") != "b,c" {
+		t.Errorf("expected tags [b c], got %v", got.Tags)
+	}
+}
+
+func TestUpdateMetadataReplacesTags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+	CreateSessionWithOptions("sess", CreateOptions{Metadata: Metadata{Tags: []string{"a", "b"}}})
+
+	UpdateMetadata("sess", MetadataUpdate{Tags: []string{}})
+
+	sess, _ := LoadSession("sess")
+	if len(sess.Metadata.Tags) != 0 {
+		t.Errorf("expected tags to be cleared, got %v", sess.Metadata.Tags)
+	}
+}
+
+func TestUpdateMetadataInvalidTag(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+	CreateSession("sess")
+
+	for _, tag := range []string{"", "two words", "a,b"} {
+		_, err := UpdateMetadata("sess", MetadataUpdate{AddTags: []string{tag}})
+		if _, ok := err.(*errors.InvalidTagError); !ok {
+			t.Errorf("tag %q: expected InvalidTagError, got %v", tag, err)
+		}
+	}
+}
+
+func TestFormatStatusShowsMetadata(t *testing.T) {
+	sess := NewSession("sess")
+	sess.addEvent(NewSessionCreatedEvent("sess"))
+	sess.addEvent(NewSessionUpdatedEvent(Metadata{Title: "Auth", Goal: "Pick one", Tags: []string{"design", "api"}}))
+
+	output := FormatStatus(sess, 0)
+	for _, want := range []string{"Title: Auth\n", "Goal: Pick one\n", "Tags: design, api\n", "--- #2 | Session details updated ---"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Description:") {
+		t.Errorf("empty description should be omitted, got:\n%s", output)
+	}
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// Poll is a poll's question and options with the votes cast so far
+type Poll struct {
+	EventNum    int               `json:"event_num"`
+	Participant string            `json:"participant"` // who opened it
+	Question    string            `json:"question"`
+	Options     []string          `json:"options"`
+	Secret      bool              `json:"secret,omitempty"`
+	ClosesAfter int               `json:"closes_after,omitempty"`
+	Votes       map[string]string `json:"votes"` // each voter's current choice
+}
+
+// PollSpec describes a poll to open
+type PollSpec struct {
+	Question    string
+	Options     []string
+	Secret      bool // hide who voted for what
+	ClosesAfter int  // last event number votes count at; 0 to never close
+}
+
+// PollResult is the tally for one option of a poll
+type PollResult struct {
+	Choice string
+	Count  int
+	Voters []string // sorted; nil for secret polls
+}
+
+// addPoll records a poll opened at event number eventNum
+func (s *State) addPoll(eventNum int, e *PollEvent) {
+	if s.Polls == nil {
+		s.Polls = make(map[int]*Poll)
+	}
+	s.Polls[eventNum] = &Poll{
+		EventNum:    eventNum,
+		Participant: e.Participant,
+		Question:    e.Question,
+		Options:     e.Options,
+		Secret:      e.Secret,
+		ClosesAfter: e.ClosesAfter,
+		Votes:       make(map[string]string),
+	}
+}
+
+// SortedPolls returns the session's polls in the order they were opened
+func (s *State) SortedPolls() []*Poll {
+	polls := make([]*Poll, 0, len(s.Polls))
+	for _, p := range s.Polls {
+		polls = append(polls, p)
+	}
+	sort.Slice(polls, func(i, j int) bool { return polls[i].EventNum < polls[j].EventNum })
+	return polls
+}
+
+// ClosedAt checks if the poll no longer accepts votes once a session has
+// eventCount events
+func (p *Poll) ClosedAt(eventCount int) bool {
+	return p.ClosesAfter > 0 && eventCount >= p.ClosesAfter
+}
+
+// Tally counts the votes for each option, in the order the options were given
+func (p *Poll) Tally() []PollResult {
+	results := make([]PollResult, len(p.Options))
+	index := map[string]int{}
+	for i, option := range p.Options {
+		results[i].Choice = option
+		index[option] = i
+	}
+
+	voters := make([]string, 0, len(p.Votes))
+	for voter := range p.Votes {
+		voters = append(voters, voter)
+	}
+	sort.Strings(voters)
+	for _, voter := range voters {
+		i, ok := index[p.Votes[voter]]
+		if !ok {
+			continue
+		}
+		results[i].Count++
+		if !p.Secret {
+			results[i].Voters = append(results[i].Voters, voter)
+		}
+	}
+	return results
+}
+
+// choice returns the option matching a choice, ignoring case
+func (p *Poll) choice(choice string) (string, bool) {
+	choice = strings.TrimSpace(choice)
+	for _, option := range p.Options {
+		if strings.EqualFold(option, choice) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// normalized returns the spec with whitespace trimmed, checking it against
+// the session it's opened in as event number eventNum
+func (spec PollSpec) normalized(eventNum int) (PollSpec, error) {
+	spec.Question = strings.TrimSpace(spec.Question)
+	if spec.Question == "" {
+		return spec, &errors.InvalidPollError{Reason: "the question is empty"}
+	}
+
+	var options []string
+	seen := map[string]bool{}
+	for _, option := range spec.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return spec, &errors.InvalidPollError{Reason: "options can't be empty"}
+		}
+		if seen[strings.ToLower(option)] {
+			return spec, &errors.InvalidPollError{Reason: fmt.Sprintf("option '%s' is given twice", option)}
+		}
+		seen[strings.ToLower(option)] = true
+		options = append(options, option)
+	}
+	if len(options) < 2 {
+		return spec, &errors.InvalidPollError{Reason: "give at least two options"}
+	}
+	spec.Options = options
+
+	if spec.ClosesAfter < 0 || (spec.ClosesAfter > 0 && spec.ClosesAfter <= eventNum) {
+		return spec, &errors.InvalidPollError{Reason: fmt.Sprintf("--closes-after must be later than the poll's own event, #%d", eventNum)}
+	}
+	return spec, nil
+}
+
+// OpenPoll opens a poll in a session. Like posting, it's open to active
+// participants and the Moderator.
+// Returns the new event number (1-indexed for display), which votes refer to
+func OpenPoll(sessionID, participant string, spec PollSpec) (int, error) {
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+			return nil, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		}
+		normalized, err := spec.normalized(session.EventCount() + 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewPollEvent(participant, normalized), nil
+	})
+}
+
+// Vote casts a participant's vote in the poll opened at event pollNum,
+// replacing their earlier vote if they've voted before.
+// Returns the new event number (1-indexed for display)
+func Vote(sessionID, participant string, pollNum int, choice string) (int, error) {
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		option, err := validVote(session, participant, pollNum, choice)
+		if err != nil {
+			return nil, err
+		}
+		return NewVoteEvent(participant, pollNum, option), nil
+	})
+}
+
+// validVote checks a vote against a session's current state, returning the
+// option voted for
+func validVote(session *Session, participant string, pollNum int, choice string) (string, error) {
+	if !session.IsActiveParticipant(participant) {
+		return "", &errors.NotAParticipantError{Name: participant, SessionID: session.ID}
+	}
+	poll := session.Polls[pollNum]
+	if poll == nil {
+		return "", &errors.PollNotFoundError{SessionID: session.ID, EventNum: pollNum}
+	}
+	if poll.ClosedAt(session.EventCount()) {
+		return "", &errors.PollClosedError{EventNum: pollNum, ClosesAfter: poll.ClosesAfter}
+	}
+	option, ok := poll.choice(choice)
+	if !ok {
+		return "", &errors.InvalidChoiceError{EventNum: pollNum, Choice: choice, Options: poll.Options}
+	}
+	return option, nil
+}
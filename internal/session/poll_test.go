@@ -0,0 +1,164 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newPollSession creates a session where Alice and Bob have joined and Alice
+// has opened a poll as event #4
+func newPollSession(t *testing.T, spec PollSpec) {
+	t.Helper()
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Bob")
+	num, err := OpenPoll("sess", "Alice", spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if num != 4 {
+		t.Fatalf("expected the poll at #4, got #%d", num)
+	}
+}
+
+var restOrGraphQL = PollSpec{Question: "REST or GraphQL?", Options: []string{"REST", "GraphQL"}}
+
+func TestPollTally(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newPollSession(t, restOrGraphQL)
+
+			if _, err := Vote("sess", "Alice", 4, "rest"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			Vote("sess", "Bob", 4, "REST")
+			// Bob changes his mind
+			Vote("sess", "Bob", 4, "GraphQL")
+
+			sess, err := LoadSessionAfter("sess", 7)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			poll := sess.Polls[4]
+			if poll == nil || poll.Question != "REST or GraphQL?" || poll.Participant != "Alice" {
+				t.Fatalf("unexpected poll: %+v", poll)
+			}
+			tally := poll.Tally()
+			if tally[0].Choice != "REST" || tally[0].Count != 1 || strings.Join(tally[0].Voters, ",") != "Alice" {
+				t.Errorf("unexpected REST result: %+v", tally[0])
+			}
+			if tally[1].Count != 1 || strings.Join(tally[1].Voters, ",") != "Bob" {
+				t.Errorf("expected Bob's changed vote to count once, got %+v", tally[1])
+			}
+		})
+	}
+}
+
+func TestVoteRules(t *testing.T) {
+	useFileStore(t)
+	newPollSession(t, restOrGraphQL)
+
+	if _, err := Vote("sess", "Carol", 4, "REST"); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, err := Vote("sess", "Alice", 3, "REST"); !isErr[*errors.PollNotFoundError](err) {
+		t.Errorf("expected PollNotFoundError, got %T: %v", err, err)
+	}
+	if _, err := Vote("sess", "Alice", 4, "SOAP"); !isErr[*errors.InvalidChoiceError](err) {
+		t.Errorf("expected InvalidChoiceError, got %T: %v", err, err)
+	}
+
+	LeaveSession("sess", "Bob")
+	if _, err := Vote("sess", "Bob", 4, "REST"); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("participants who left shouldn't vote, got %v", err)
+	}
+}
+
+func TestPollCloses(t *testing.T) {
+	useFileStore(t)
+	spec := restOrGraphQL
+	spec.ClosesAfter = 5
+	newPollSession(t, spec)
+
+	if _, err := Vote("sess", "Alice", 4, "REST"); err != nil {
+		t.Fatalf("a vote at the closing event should count: %v", err)
+	}
+	_, err := Vote("sess", "Bob", 4, "REST")
+	if closed, ok := err.(*errors.PollClosedError); !ok || closed.ClosesAfter != 5 {
+		t.Errorf("expected PollClosedError, got %T: %v", err, err)
+	}
+}
+
+func TestOpenPollValidation(t *testing.T) {
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+
+	for name, spec := range map[string]PollSpec{
+		"no question":      {Question: " ", Options: []string{"A", "B"}},
+		"one option":       {Question: "Q?", Options: []string{"A"}},
+		"duplicate option": {Question: "Q?", Options: []string{"A", "a"}},
+		"closes too early": {Question: "Q?", Options: []string{"A", "B"}, ClosesAfter: 3},
+	} {
+		if _, err := OpenPoll("sess", "Alice", spec); !isErr[*errors.InvalidPollError](err) {
+			t.Errorf("%s: expected InvalidPollError, got %T: %v", name, err, err)
+		}
+	}
+
+	if _, err := OpenPoll("sess", "Bob", restOrGraphQL); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, err := OpenPoll("sess", "Moderator", restOrGraphQL); err != nil {
+		t.Errorf("the Moderator should be able to open polls: %v", err)
+	}
+}
+
+func TestFormatStatusPolls(t *testing.T) {
+	useFileStore(t)
+	spec := restOrGraphQL
+	spec.Secret = true
+	newPollSession(t, spec)
+	Vote("sess", "Bob", 4, "GraphQL")
+
+	sess, _ := LoadSession("sess")
+	out := FormatStatus(sess, 3)
+
+	for _, want := range []string{
+		"Polls:\n  #4 REST or GraphQL? [open, secret]\n    REST: 0\n    GraphQL: 1\n",
+		"--- #4 | Alice opened a poll: REST or GraphQL? (REST / GraphQL) ---",
+		"--- #5 | Bob voted in poll #4 ---",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "(Bob)") {
+		t.Errorf("secret polls shouldn't show voters:\n%s", out)
+	}
+}
+
+func TestCheckSessionVotes(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"poll","timestamp_millis":3,"participant":"Alice","question":"Q?","options":["A","B"],"closes_after":4}
+{"type":"vote","timestamp_millis":4,"participant":"Alice","poll":3,"choice":"C"}
+{"type":"vote","timestamp_millis":5,"participant":"Alice","poll":3,"choice":"A"}
+`)
+
+	report, _ := CheckSession("sess")
+	if len(report.Problems) != 2 || report.Problems[0].Line != 4 || report.Problems[1].Line != 5 {
+		t.Fatalf("expected problems on lines 4 and 5, got %+v", report.Problems)
+	}
+	if !strings.Contains(report.Problems[1].Message, "closed") {
+		t.Errorf("expected a closed poll problem, got %q", report.Problems[1].Message)
+	}
+}
+
+func isErr[T error](err error) bool {
+	_, ok := err.(T)
+	return ok
+}
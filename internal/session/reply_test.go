@@ -0,0 +1,101 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newReplySession creates a session where Alice posted #4, Bob posted #5,
+// Bob replied to #4 as #6 and Alice replied to that as #7
+func newReplySession(t *testing.T) {
+	t.Helper()
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	JoinSession("sess", "Bob")
+	PostMessage("sess", "Alice", "Use REST", "Bob", 3)
+	PostMessage("sess", "Bob", "Unrelated", "Alice", 4)
+	if _, err := PostReply("sess", "Bob", "Why not GraphQL?", "Alice", 4, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := PostReply("sess", "Alice", "Caching", "Bob", 6, 6); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPostReply(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newReplySession(t)
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if msg, ok := sess.Event(6).(*MessageEvent); !ok || msg.ReplyTo != 4 {
+				t.Errorf("expected #6 to reply to #4, got %+v", sess.Event(6))
+			}
+			if msg := sess.Event(5).(*MessageEvent); msg.ReplyTo != 0 {
+				t.Errorf("expected #5 not to be a reply, got %d", msg.ReplyTo)
+			}
+
+			out := FormatStatus(sess, 5)
+			if !strings.Contains(out, "--- #6 | Bob (re #4) ---\nWhy not GraphQL?\n--- End #6 | Bob | Next: Alice ---") {
+				t.Errorf("status should mark the reply:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestPostReplyRules(t *testing.T) {
+	useFileStore(t)
+	newReplySession(t)
+
+	for _, replyTo := range []int{2, 8, -1} {
+		_, err := PostReply("sess", "Bob", "Reply", "Alice", replyTo, 7)
+		if notFound, ok := err.(*errors.MessageNotFoundError); !ok || notFound.EventNum != replyTo {
+			t.Errorf("reply to #%d: expected MessageNotFoundError, got %T: %v", replyTo, err, err)
+		}
+	}
+}
+
+func TestFormatThread(t *testing.T) {
+	useFileStore(t)
+	newReplySession(t)
+	PostMessage("sess", "Bob", "Also unrelated", "Alice", 7)
+
+	sess, _ := LoadSession("sess")
+	out, err := FormatThread(sess, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Thread: #4\n", "--- #4 | Alice ---", "--- #6 | Bob (re #4) ---", "--- #7 | Alice (re #6) ---"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("thread should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "#5 |") || strings.Contains(out, "#8 |") {
+		t.Errorf("thread shouldn't contain unrelated messages:\n%s", out)
+	}
+
+	if _, err := FormatThread(sess, 3); !isErr[*errors.MessageNotFoundError](err) {
+		t.Errorf("expected MessageNotFoundError, got %T: %v", err, err)
+	}
+}
+
+func TestCheckSessionReplies(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"message","timestamp_millis":3,"participant":"Alice","content":"hi","next":"Moderator","reply_to":2}
+{"type":"message","timestamp_millis":4,"participant":"Alice","content":"hi","next":"Moderator","reply_to":3}
+{"type":"message","timestamp_millis":5,"participant":"Alice","content":"hi","next":"Moderator","reply_to":6}
+`)
+
+	report, _ := CheckSession("sess")
+	if len(report.Problems) != 2 || report.Problems[0].Line != 3 || report.Problems[1].Line != 5 {
+		t.Fatalf("expected problems on lines 3 and 5, got %+v", report.Problems)
+	}
+}
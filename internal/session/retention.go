@@ -0,0 +1,79 @@
+package session
+
+import (
+	"slices"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// ArchiveSession makes a session read-only and hides it from the default listing
+func ArchiveSession(sessionID string) error {
+	return currentStore.Archive(sessionID)
+}
+
+// DeleteSession permanently removes a session. The log isn't read, so
+// sessions that can't be loaded can still be deleted.
+func DeleteSession(sessionID string) error {
+	return currentStore.Delete(sessionID)
+}
+
+// SessionArchived checks if a session is archived without reading its log.
+// Returns *errors.SessionNotFoundError if it doesn't exist.
+func SessionArchived(sessionID string) (bool, error) {
+	exists, err := currentStore.Exists(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	archived, err := currentStore.ListArchived()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(archived, sessionID), nil
+}
+
+// RetentionPolicy ages sessions out by time since their last activity.
+// A zero duration disables that step.
+type RetentionPolicy struct {
+	ArchiveAfter time.Duration // archive active sessions idle this long
+	DeleteAfter  time.Duration // delete sessions (archived or not) idle this long
+}
+
+// GCPlan lists the sessions a retention policy would archive or delete
+type GCPlan struct {
+	Archive []Summary
+	Delete  []Summary
+}
+
+// PlanGC applies a retention policy to the given sessions as of now.
+// Sessions due for deletion are not also archived. Sessions that can't be
+// read, including encrypted ones without their key, have no known last
+// activity and are left alone.
+func PlanGC(active, archived []Summary, policy RetentionPolicy, now time.Time) GCPlan {
+	plan := GCPlan{Archive: []Summary{}, Delete: []Summary{}}
+
+	idleFor := func(s Summary) time.Duration {
+		return now.Sub(time.UnixMilli(s.LastActivityMillis))
+	}
+
+	for _, s := range active {
+		switch {
+		case s.Err != nil:
+		case policy.DeleteAfter > 0 && idleFor(s) >= policy.DeleteAfter:
+			plan.Delete = append(plan.Delete, s)
+		case policy.ArchiveAfter > 0 && idleFor(s) >= policy.ArchiveAfter:
+			plan.Archive = append(plan.Archive, s)
+		}
+	}
+
+	for _, s := range archived {
+		if s.Err == nil && policy.DeleteAfter > 0 && idleFor(s) >= policy.DeleteAfter {
+			plan.Delete = append(plan.Delete, s)
+		}
+	}
+
+	return plan
+}
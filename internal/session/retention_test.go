@@ -0,0 +1,162 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+func TestPlanGC(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) int64 {
+		return now.Add(-time.Duration(d) * 24 * time.Hour).UnixMilli()
+	}
+
+	active := []Summary{
+		{ID: "fresh", LastActivityMillis: daysAgo(1)},
+		{ID: "stale", LastActivityMillis: daysAgo(40)},
+		{ID: "ancient", LastActivityMillis: daysAgo(400)},
+	}
+	archived := []Summary{
+		{ID: "archived-recent", LastActivityMillis: daysAgo(60), Archived: true},
+		{ID: "archived-old", LastActivityMillis: daysAgo(200), Archived: true},
+	}
+
+	policy := RetentionPolicy{ArchiveAfter: 30 * 24 * time.Hour, DeleteAfter: 180 * 24 * time.Hour}
+	plan := PlanGC(active, archived, policy, now)
+
+	if len(plan.Archive) != 1 || plan.Archive[0].ID != "stale" {
+		t.Errorf("expected to archive [stale], got %v", plan.Archive)
+	}
+	if len(plan.Delete) != 2 || plan.Delete[0].ID != "ancient" || plan.Delete[1].ID != "archived-old" {
+		t.Errorf("expected to delete [ancient archived-old], got %v", plan.Delete)
+	}
+}
+
+func TestPlanGCDisabledSteps(t *testing.T) {
+	now := time.Now()
+	old := []Summary{{ID: "old", LastActivityMillis: now.Add(-1000 * time.Hour).UnixMilli()}}
+
+	plan := PlanGC(old, old, RetentionPolicy{}, now)
+	if len(plan.Archive) != 0 || len(plan.Delete) != 0 {
+		t.Errorf("empty policy should do nothing, got %+v", plan)
+	}
+}
+
+func TestStoreArchive(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			s.Append("sess", 0, NewSessionCreatedEvent("sess"), NewJoinedEvent("Alice"))
+
+			if err := s.Archive("sess"); err != nil {
+				t.Fatalf("archive failed: %v", err)
+			}
+
+			ids, _ := s.List()
+			if len(ids) != 0 {
+				t.Errorf("archived session should not be listed, got %v", ids)
+			}
+			archivedIDs, _ := s.ListArchived()
+			if len(archivedIDs) != 1 || archivedIDs[0] != "sess" {
+				t.Errorf("expected archived [sess], got %v", archivedIDs)
+			}
+
+			events, err := s.ReadFrom("sess", 0)
+			if err != nil {
+				t.Fatalf("archived session should stay readable: %v", err)
+			}
+			if len(events) != 2 {
+				t.Errorf("expected 2 events, got %d", len(events))
+			}
+
+			exists, _ := s.Exists("sess")
+			if !exists {
+				t.Error("archived session should exist")
+			}
+
+			err = s.Append("sess", 2, NewLeftEvent("Alice"))
+			if _, ok := err.(*errors.SessionArchivedError); !ok {
+				t.Errorf("expected SessionArchivedError on append, got %v", err)
+			}
+
+			err = s.Archive("sess")
+			if _, ok := err.(*errors.SessionArchivedError); !ok {
+				t.Errorf("expected SessionArchivedError on re-archive, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			s.Append("live", 0, NewSessionCreatedEvent("live"))
+			s.Append("old", 0, NewSessionCreatedEvent("old"))
+			s.Archive("old")
+
+			for _, id := range []string{"live", "old"} {
+				if err := s.Delete(id); err != nil {
+					t.Fatalf("delete %s failed: %v", id, err)
+				}
+				exists, _ := s.Exists(id)
+				if exists {
+					t.Errorf("%s should not exist after delete", id)
+				}
+			}
+
+			err := s.Delete("live")
+			if _, ok := err.(*errors.SessionNotFoundError); !ok {
+				t.Errorf("expected SessionNotFoundError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestArchivedSessionReadableViaLoadSessionAfter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useStore(t, NewFileStore())
+
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	ArchiveSession("sess")
+
+	sess, err := LoadSessionAfter("sess", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.Events) != 1 || !sess.IsActiveParticipant("Alice") {
+		t.Errorf("unexpected archived session state: %+v", sess)
+	}
+
+	_, err = JoinSession("sess", "Bob")
+	if _, ok := err.(*errors.SessionArchivedError); !ok {
+		t.Errorf("expected SessionArchivedError, got %v", err)
+	}
+}
+
+func TestDeleteUnreadableSession(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "broken", validLog+"not json\n")
+	CreateSession("old")
+	ArchiveSession("old")
+
+	if _, err := LoadSession("broken"); err == nil {
+		t.Fatal("expected the session to be unreadable")
+	}
+	if archived, err := SessionArchived("broken"); err != nil || archived {
+		t.Errorf("expected an active session, got archived=%v: %v", archived, err)
+	}
+	if archived, err := SessionArchived("old"); err != nil || !archived {
+		t.Errorf("expected an archived session, got archived=%v: %v", archived, err)
+	}
+
+	if err := DeleteSession("broken"); err != nil {
+		t.Fatalf("unreadable sessions should be deletable, got %v", err)
+	}
+	if _, err := SessionArchived("broken"); !isErr[*errors.SessionNotFoundError](err) {
+		t.Errorf("expected SessionNotFoundError after deletion, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// MessageRevision is the current version of a message that was edited or
+// retracted after it was posted
+type MessageRevision struct {
+	Content     string   // latest content; empty once retracted
+	Mentions    []string // participants mentioned in the latest content
+	Edited      bool
+	RetractedBy string // who retracted it, empty unless retracted
+	RetractedAt int    // event number of the retraction, 0 unless retracted
+}
+
+// Retracted checks if the message was retracted. A nil revision is an
+// unchanged message.
+func (r *MessageRevision) Retracted() bool {
+	return r != nil && r.RetractedAt != 0
+}
+
+// Tombstone is what's shown in place of a retracted message's content
+func (r *MessageRevision) Tombstone() string {
+	return fmt.Sprintf("[Retracted by %s at #%d]", r.RetractedBy, r.RetractedAt)
+}
+
+// Revisions collects the edits and retractions among the session's loaded
+// events, by the event number of the message they apply to. Edits always
+// come after the message they change, so every loaded message's revision is
+// complete.
+func (s *Session) Revisions() map[int]*MessageRevision {
+	revisions := map[int]*MessageRevision{}
+	revision := func(message int) *MessageRevision {
+		if revisions[message] == nil {
+			revisions[message] = &MessageRevision{}
+		}
+		return revisions[message]
+	}
+
+	for i, event := range s.Events {
+		switch e := event.(type) {
+		case *MessageEditedEvent:
+			r := revision(e.Message)
+			if !r.Retracted() {
+				r.Content = e.Content
+				r.Mentions = e.Mentions
+				r.Edited = true
+			}
+		case *MessageRetractedEvent:
+			r := revision(e.Message)
+			if !r.Retracted() {
+				r.Content = ""
+				r.RetractedBy = e.Participant
+				r.RetractedAt = s.Offset + i + 1
+			}
+		}
+	}
+	return revisions
+}
+
+// EditMessage replaces the content of the message at event number eventNum.
+// Only the message's author, while active, and the Moderator can edit it.
+// Mentions are found in the new content as if the author had posted it now,
+// replacing the message's earlier ones.
+// Returns the new event number (1-indexed for display)
+func EditMessage(sessionID, participant string, eventNum int, content string) (int, error) {
+	return appendToHistory(sessionID, func(session *Session) (Event, error) {
+		if err := validRevision(session, participant, eventNum); err != nil {
+			return nil, err
+		}
+		edited := NewMessageEditedEvent(participant, eventNum, content)
+		edited.Mentions = parseMentions(session, session.Event(eventNum).(*MessageEvent).Participant, content)
+		return edited, nil
+	})
+}
+
+// RetractMessage withdraws the message at event number eventNum, so it's
+// shown as a tombstone. Only the message's author, while active, and the
+// Moderator can retract it.
+// Returns the new event number (1-indexed for display)
+func RetractMessage(sessionID, participant string, eventNum int) (int, error) {
+	return appendToHistory(sessionID, func(session *Session) (Event, error) {
+		if err := validRevision(session, participant, eventNum); err != nil {
+			return nil, err
+		}
+		return NewMessageRetractedEvent(participant, eventNum), nil
+	})
+}
+
+// validRevision checks that participant may edit or retract the message at
+// event number eventNum of a fully loaded session
+func validRevision(session *Session, participant string, eventNum int) error {
+	msg, ok := session.Event(eventNum).(*MessageEvent)
+	if !ok {
+		return &errors.MessageNotFoundError{SessionID: session.ID, EventNum: eventNum}
+	}
+	if participant != "Moderator" {
+		if !session.IsActiveParticipant(participant) {
+			return &errors.NotAParticipantError{Name: participant, SessionID: session.ID}
+		}
+		if msg.Participant != participant {
+			return &errors.NotMessageAuthorError{Name: participant, EventNum: eventNum, Author: msg.Participant}
+		}
+	}
+
+	// Only events after the message can retract it
+	for i := eventNum - session.Offset; i < len(session.Events); i++ {
+		if e, ok := session.Events[i].(*MessageRetractedEvent); ok && e.Message == eventNum {
+			return &errors.MessageRetractedError{EventNum: eventNum, RetractedAt: session.Offset + i + 1}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newRevisionSession creates a session where Alice posted #4 and Bob #5
+func newRevisionSession(t *testing.T) {
+	t.Helper()
+	newAliceAndBobSession(t)
+	PostMessage("sess", "Alice", "The key is hunter2", "Bob", 3)
+	PostMessage("sess", "Bob", "Tests pass", "Alice", 4)
+}
+
+func TestEditAndRetract(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newRevisionSession(t)
+
+			if num, err := EditMessage("sess", "Bob", 5, "Tests pass on CI"); err != nil || num != 6 {
+				t.Fatalf("expected the edit at #6, got #%d: %v", num, err)
+			}
+			if _, err := RetractMessage("sess", "Moderator", 4); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sess.EventCount() != 7 || sess.LatestMessageNext() != "Alice" {
+				t.Errorf("edits shouldn't change numbering or turns, got %d events, next %q", sess.EventCount(), sess.LatestMessageNext())
+			}
+
+			out := FormatStatus(sess, 0)
+			for _, want := range []string{
+				"--- #4 | Alice ---\n[Retracted by Moderator at #7]\n--- End #4 | Alice | Next: Bob ---",
+				"--- #5 | Bob (edited) ---\nTests pass on CI\n--- End #5",
+				"--- #6 | Bob edited #5 ---\nTests pass on CI\n--- End #6 | Bob edited #5 ---",
+				"--- #7 | Moderator retracted #4 ---",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("status should contain %q:\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "hunter2") {
+				t.Errorf("retracted content shouldn't be shown:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestRevisionRules(t *testing.T) {
+	useFileStore(t)
+	newRevisionSession(t)
+
+	if _, err := EditMessage("sess", "Alice", 5, "x"); !isErr[*errors.NotMessageAuthorError](err) {
+		t.Errorf("expected NotMessageAuthorError, got %T: %v", err, err)
+	}
+	if _, err := RetractMessage("sess", "Alice", 3); !isErr[*errors.MessageNotFoundError](err) {
+		t.Errorf("expected MessageNotFoundError, got %T: %v", err, err)
+	}
+
+	RetractMessage("sess", "Alice", 4)
+	_, err := EditMessage("sess", "Alice", 4, "x")
+	if retracted, ok := err.(*errors.MessageRetractedError); !ok || retracted.RetractedAt != 6 {
+		t.Errorf("expected MessageRetractedError, got %T: %v", err, err)
+	}
+
+	LeaveSession("sess", "Bob")
+	if _, err := EditMessage("sess", "Bob", 5, "x"); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("authors who left shouldn't edit, got %v", err)
+	}
+}
+
+func TestEditAfterRetractionHidesContent(t *testing.T) {
+	useFileStore(t)
+	newRevisionSession(t)
+	EditMessage("sess", "Alice", 4, "The key is hunter3")
+	RetractMessage("sess", "Alice", 4)
+
+	sess, _ := LoadSession("sess")
+	if out := FormatStatus(sess, 0); strings.Contains(out, "hunter") {
+		t.Errorf("no version of a retracted message should be shown:\n%s", out)
+	}
+
+	var b strings.Builder
+	Export(&b, sess, ExportJSON)
+	if strings.Contains(b.String(), "hunter") || !strings.Contains(b.String(), `"retracted": true`) {
+		t.Errorf("export should only have the tombstone:\n%s", b.String())
+	}
+}
+
+func TestCheckSessionRevisions(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"joined","timestamp_millis":3,"participant":"Bob"}
+{"type":"message","timestamp_millis":4,"participant":"Alice","content":"hi","next":"Bob"}
+{"type":"message_edited","timestamp_millis":5,"participant":"Bob","message":4,"content":"bye"}
+{"type":"message_retracted","timestamp_millis":6,"participant":"Alice","message":4}
+{"type":"message_retracted","timestamp_millis":7,"participant":"Moderator","message":4}
+`)
+
+	report, _ := CheckSession("sess")
+	if len(report.Problems) != 2 || report.Problems[0].Line != 5 || report.Problems[1].Line != 7 {
+		t.Fatalf("expected problems on lines 5 and 7, got %+v", report.Problems)
+	}
+}
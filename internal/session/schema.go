@@ -0,0 +1,151 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// SchemaVersion is the event schema version written by this version of
+// council. Bump it when the shape of an event changes, adding a migration
+// from the previous version to migrations.
+const SchemaVersion = 1
+
+// migration upgrades an event's JSON fields from one schema version to the next
+type migration func(fields map[string]json.RawMessage) error
+
+// migrations[v] upgrades an event from schema version v to v+1, or is nil if
+// the shape didn't change. Version 0 covers events written before events
+// were versioned, which have the same shape as version 1.
+var migrations = []migration{
+	0: nil,
+}
+
+// upgradeEvent applies the migrations from version up to SchemaVersion to an
+// event line. The line is returned unchanged if no migration alters it.
+func upgradeEvent(line []byte, version int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	for v := version; v < SchemaVersion; v++ {
+		if migrations[v] == nil {
+			continue
+		}
+		if fields == nil {
+			if err := json.Unmarshal(line, &fields); err != nil {
+				return nil, err
+			}
+		}
+		if err := migrations[v](fields); err != nil {
+			return nil, err
+		}
+	}
+	if fields == nil {
+		return line, nil
+	}
+
+	fields["v"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+	return json.Marshal(fields)
+}
+
+// eventVersion reads the schema version of a decrypted event line
+func eventVersion(line []byte) (int, error) {
+	line, err := verifyChecksum(line)
+	if err != nil {
+		return 0, err
+	}
+	var raw rawEvent
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return 0, err
+	}
+	return raw.Version, nil
+}
+
+// Rewriter is implemented by stores that can replace a session's log, as
+// needed to migrate its events to a new schema version
+type Rewriter interface {
+	// Rewrite replaces the log with events if it still holds exactly
+	// expectedCount events, encoding them as Append does. Returns the path
+	// of a backup of the old log, or "" if the store keeps none. Archived
+	// sessions are read-only, so like Append it fails for them with
+	// *errors.SessionArchivedError.
+	Rewrite(sessionID string, expectedCount int, events []Event) (string, error)
+}
+
+// MigrateResult describes the migration of one session
+type MigrateResult struct {
+	SessionID string
+	Events    int
+	Migrated  int    // events that were written with an older schema version
+	Backup    string // backup of the log before migrating, if the store keeps one
+}
+
+// MigrateSession rewrites a session's events in the current schema version.
+// With dryRun, it only reports how many events would be migrated. Sessions
+// with events from a newer schema version are left alone, as are hash-chained
+// sessions whose chain is broken, since rewriting would hide the break.
+func MigrateSession(sessionID string, dryRun bool) (*MigrateResult, error) {
+	lines, err := currentStore.ReadRaw(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrateResult{SessionID: sessionID}
+	events := []Event{}
+	encrypted := false
+	for _, line := range lines {
+		data := bytes.TrimRight(line.Data, "\r")
+		if len(data) == 0 {
+			continue
+		}
+
+		num := len(events) + 1
+		if num == 1 {
+			encrypted = isEncrypted(data)
+		}
+		plain, err := openEventLine(sessionID, num, data, encrypted)
+		if err != nil {
+			return nil, eventError(err, sessionID, line.Num)
+		}
+		event, err := ParseEvent(plain)
+		if err != nil {
+			return nil, eventError(err, sessionID, line.Num)
+		}
+		version, err := eventVersion(plain)
+		if err != nil {
+			return nil, err
+		}
+		if version > SchemaVersion {
+			return nil, &errors.NewerSchemaError{SessionID: sessionID, Version: version, Supported: SchemaVersion}
+		}
+		if version < SchemaVersion {
+			result.Migrated++
+		}
+		setVersion(event, SchemaVersion)
+		events = append(events, event)
+	}
+	result.Events = len(events)
+
+	if result.Migrated == 0 || dryRun {
+		return result, nil
+	}
+
+	if chain := verifyLines(sessionID, lines); chain.Enabled && !chain.Intact() {
+		return nil, fmt.Errorf("session '%s' has a broken hash chain at line %d; migrating would hide it. Run 'council verify %s' for details.",
+			sessionID, chain.BrokenLine, sessionID)
+	}
+
+	rewriter, ok := currentStore.(Rewriter)
+	if !ok {
+		return nil, fmt.Errorf("the configured store doesn't support migrations")
+	}
+	result.Backup, err = rewriter.Rewrite(sessionID, len(events), events)
+	return result, err
+}
+
+// setVersion sets an event's schema version
+func setVersion(event Event, version int) {
+	if versioned, ok := event.(interface{ setVersion(int) }); ok {
+		versioned.setVersion(version)
+	}
+}
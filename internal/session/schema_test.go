@@ -0,0 +1,275 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+const legacyLog = `{"type":"session_created","timestamp_millis":1,"id":"sess"}
+{"type":"joined","timestamp_millis":2,"participant":"Alice"}
+{"type":"message","timestamp_millis":3,"participant":"Alice","content":"hi","next":"Moderator"}
+`
+
+func TestNewEventsHaveSchemaVersion(t *testing.T) {
+	data, _ := MarshalEvent(NewJoinedEvent("Alice"))
+	if !strings.Contains(string(data), `"v":1`) {
+		t.Errorf("expected schema version in %s", data)
+	}
+}
+
+func TestRawEventMarshalKeepsFields(t *testing.T) {
+	event, _ := ParseEvent([]byte(`{"type":"survey","v":2,"timestamp_millis":5,"prev_hash":"abc","options":["a","b"]}`))
+	raw := event.(*RawEvent)
+
+	raw.setPrevHash("def")
+	data, err := MarshalEvent(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	if fields["prev_hash"] != "def" {
+		t.Errorf("expected prev_hash to be updated, got %v", fields["prev_hash"])
+	}
+	if fields["v"] != float64(2) || fields["type"] != "survey" {
+		t.Errorf("expected type and version to be kept, got %v", fields)
+	}
+	if options, ok := fields["options"].([]any); !ok || len(options) != 2 {
+		t.Errorf("expected options to be kept, got %v", fields["options"])
+	}
+
+	raw.setPrevHash("")
+	data, _ = MarshalEvent(raw)
+	if strings.Contains(string(data), "prev_hash") {
+		t.Errorf("expected prev_hash to be cleared, got %s", data)
+	}
+}
+
+func TestFormatStatusRawEvent(t *testing.T) {
+	sess := NewSession("sess")
+	event, _ := ParseEvent([]byte(`{"type":"survey","timestamp_millis":5,"question":"ship?"}`))
+	sess.addEvent(event)
+
+	output := FormatStatus(sess, 0)
+	for _, want := range []string{"--- #1 | survey (unknown event type) ---", `{"question":"ship?"}`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestUpgradeEventAppliesMigrations(t *testing.T) {
+	prev := migrations[0]
+	migrations[0] = func(fields map[string]json.RawMessage) error {
+		if who, ok := fields["who"]; ok {
+			fields["participant"] = who
+			delete(fields, "who")
+		}
+		return nil
+	}
+	t.Cleanup(func() { migrations[0] = prev })
+
+	event, err := ParseEvent([]byte(`{"type":"joined","timestamp_millis":2,"who":"Alice"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	joined := event.(*JoinedEvent)
+	if joined.Participant != "Alice" {
+		t.Errorf("expected migrated participant, got %q", joined.Participant)
+	}
+	if joined.Version != SchemaVersion {
+		t.Errorf("expected version %d after upgrade, got %d", SchemaVersion, joined.Version)
+	}
+
+	// Current events are left alone
+	event, _ = ParseEvent([]byte(`{"type":"joined","v":1,"timestamp_millis":2,"who":"Alice"}`))
+	if event.(*JoinedEvent).Participant != "" {
+		t.Error("events at the current version shouldn't be migrated")
+	}
+}
+
+func TestMigrateSession(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", legacyLog)
+
+	result, err := MigrateSession("sess", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Migrated != 3 || result.Events != 3 {
+		t.Errorf("expected 3 of 3 events migrated, got %+v", result)
+	}
+
+	backup, err := os.ReadFile(result.Backup)
+	if err != nil || string(backup) != legacyLog {
+		t.Errorf("expected backup of the old log, got %q (%v)", backup, err)
+	}
+
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), `"v":1`) != 3 {
+		t.Errorf("expected every event to be versioned, got:\n%s", data)
+	}
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.EventCount() != 3 || !sess.IsActiveParticipant("Alice") {
+		t.Errorf("expected session to survive migration, got %+v", sess)
+	}
+
+	result, _ = MigrateSession("sess", false)
+	if result.Migrated != 0 {
+		t.Errorf("expected nothing left to migrate, got %+v", result)
+	}
+}
+
+func TestMigrateSessionDryRun(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", legacyLog)
+
+	result, err := MigrateSession("sess", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Migrated != 3 || result.Backup != "" {
+		t.Errorf("expected 3 events reported and no backup, got %+v", result)
+	}
+
+	path, _ := storage.SessionEventsPath("sess")
+	if data, _ := os.ReadFile(path); string(data) != legacyLog {
+		t.Error("dry run shouldn't change the log")
+	}
+}
+
+func TestRewriteArchivedSession(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			CreateSession("sess")
+			JoinSession("sess", "Alice")
+			if err := ArchiveSession("sess"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			before, _ := currentStore.ReadRaw("sess")
+
+			_, err := currentStore.(Rewriter).Rewrite("sess", 2, []Event{NewSessionCreatedEvent("sess")})
+			if _, ok := err.(*errors.SessionArchivedError); !ok {
+				t.Fatalf("expected SessionArchivedError, got %v", err)
+			}
+			if after, _ := currentStore.ReadRaw("sess"); len(after) != len(before) {
+				t.Errorf("archived log was rewritten: %d lines, was %d", len(after), len(before))
+			}
+		})
+	}
+}
+
+func TestRewriteChangesLength(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			CreateSession("sess")
+			JoinSession("sess", "Alice")
+			JoinSession("sess", "Bob")
+			rewriter := currentStore.(Rewriter)
+
+			shorter := []Event{NewSessionCreatedEvent("sess"), NewJoinedEvent("Carol")}
+			if _, err := rewriter.Rewrite("sess", 3, shorter); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sess, err := LoadHistory("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sess.EventCount() != 2 || sess.IsActiveParticipant("Bob") || !sess.IsActiveParticipant("Carol") {
+				t.Errorf("expected only the 2 rewritten events, got %d: %v", sess.EventCount(), sess.Participants)
+			}
+
+			longer := append(shorter, NewJoinedEvent("Dave"), NewJoinedEvent("Eve"))
+			if _, err := rewriter.Rewrite("sess", 2, longer); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sess, _ := LoadHistory("sess"); sess.EventCount() != 4 || !sess.IsActiveParticipant("Eve") {
+				t.Errorf("expected the 4 rewritten events, got %d", sess.EventCount())
+			}
+		})
+	}
+}
+
+func TestMigrateSessionSQLite(t *testing.T) {
+	s := storeFactories()["sqlite"](t)
+	useStore(t, s)
+	s.(*SQLiteStore).db.Exec(`INSERT INTO events (session_id, seq, data) VALUES ('sess', 1, ?), ('sess', 2, ?)`,
+		`{"type":"session_created","timestamp_millis":1,"id":"sess"}`,
+		`{"type":"joined","timestamp_millis":2,"participant":"Alice"}`)
+
+	result, err := MigrateSession("sess", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Migrated != 2 {
+		t.Errorf("expected 2 events migrated, got %+v", result)
+	}
+
+	lines, _ := s.ReadRaw("sess")
+	for _, line := range lines {
+		if !strings.Contains(string(line.Data), `"v":1`) {
+			t.Errorf("expected versioned event, got %s", line.Data)
+		}
+	}
+}
+
+func TestMigrateSessionNewerSchema(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", legacyLog+`{"type":"survey","v":99,"timestamp_millis":4}
+`)
+
+	_, err := MigrateSession("sess", false)
+	if _, ok := err.(*errors.NewerSchemaError); !ok {
+		t.Errorf("expected NewerSchemaError, got %v", err)
+	}
+}
+
+func TestMigrateSessionKeepsHashChain(t *testing.T) {
+	useFileStore(t)
+	newChainedSession(t)
+
+	// Strip the versions to make the events look like they predate versioning
+	path, _ := storage.SessionEventsPath("sess")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.ReplaceAll(string(data), `"v":1,`, "")), 0644)
+
+	if _, err := MigrateSession("sess", false); err == nil {
+		t.Fatal("expected migration to refuse a session with a broken chain")
+	}
+
+	// Re-chain the unversioned lines so only their versions are out of date
+	CurrentStore().(Rewriter).Rewrite("sess", 4, unversioned(t, "sess"))
+	if _, err := MigrateSession("sess", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := VerifyChain("sess")
+	if !report.Intact() {
+		t.Errorf("expected intact chain after migration, got %+v", report)
+	}
+}
+
+// unversioned loads a session's events with their schema versions cleared
+func unversioned(t *testing.T, sessionID string) []Event {
+	t.Helper()
+	sess, err := LoadSession(sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, event := range sess.Events {
+		setVersion(event, 0)
+	}
+	return sess.Events
+}
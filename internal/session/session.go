@@ -3,32 +3,69 @@ package session
 import (
 	"bufio"
 	"io"
-	"os"
-	"syscall"
 
 	"github.com/amterp/council/internal/errors"
-	"github.com/amterp/council/internal/storage"
 )
 
 // Session represents the in-memory state of a session
 type Session struct {
-	ID           string
-	Events       []Event
-	Participants map[string]bool // currently active participants (true = joined, false = left)
+	ID     string
+	Events []Event
+	State
+
+	// Offset is the number of events preceding Events[0]. It is 0 when the
+	// full log is loaded and non-zero for sessions loaded by LoadSessionAfter,
+	// whose derived state still reflects the whole log.
+	Offset int
+}
+
+// State is the session state derived by replaying events. It is
+// serializable so stores can cache it instead of replaying the full log.
+type State struct {
+	Participants map[string]bool   `json:"participants"` // currently active participants (true = joined, false = left)
+	Encrypted    bool              `json:"encrypted,omitempty"`
+	LatestNext   string            `json:"latest_next"` // Next field of the most recent message
+	ForkedFrom   *ForkOrigin       `json:"forked_from,omitempty"`
+	Metadata     Metadata          `json:"metadata"`            // from the most recent session_updated event
+	Polls        map[int]*Poll     `json:"polls,omitempty"`     // by event number
+	Decisions    map[int]*Decision `json:"decisions,omitempty"` // by event number
+	Facts        map[string]*Fact  `json:"facts,omitempty"`     // by key, including deleted ones
+	Tasks        map[int]*Task     `json:"tasks,omitempty"`     // by event number
+
+	CreatedMillis      int64 `json:"created_millis,omitempty"`       // timestamp of the session_created event
+	LastActivityMillis int64 `json:"last_activity_millis,omitempty"` // timestamp of the latest event
+
+	// Applied is the number of events replayed into the state, so each
+	// event applied knows its own number
+	Applied int `json:"applied"`
+}
+
+// ForkOrigin identifies the session and event a fork branched from
+type ForkOrigin struct {
+	SessionID   string `json:"session_id"`
+	EventNum    int    `json:"event_num"`
+	WithHistory bool   `json:"with_history"`
+}
+
+// newState creates an empty derived state
+func newState() State {
+	return State{
+		Participants: make(map[string]bool),
+	}
 }
 
 // NewSession creates a new empty session with the given ID
 func NewSession(id string) *Session {
 	return &Session{
-		ID:           id,
-		Events:       make([]Event, 0),
-		Participants: make(map[string]bool),
+		ID:     id,
+		Events: make([]Event, 0),
+		State:  newState(),
 	}
 }
 
 // EventCount returns the number of events in the session
 func (s *Session) EventCount() int {
-	return len(s.Events)
+	return s.Offset + len(s.Events)
 }
 
 // ActiveParticipants returns a list of currently active participants (excluding Moderator)
@@ -48,7 +85,7 @@ func (s *Session) IsActiveParticipant(name string) bool {
 }
 
 // PreviousSpeaker returns the participant who posted the message before the given one
-// Returns empty string if there's no previous message
+// Returns empty string if there's no previous message among the loaded events
 func (s *Session) PreviousSpeaker(excludeParticipant string) string {
 	// Walk backwards through events to find the last message not by excludeParticipant
 	for i := len(s.Events) - 1; i >= 0; i-- {
@@ -78,277 +115,386 @@ func (s *Session) RandomActiveParticipant(exclude string) string {
 	return candidates[0]
 }
 
+// Event returns the event with the given number (1-indexed), or nil if it
+// doesn't exist or wasn't loaded
+func (s *Session) Event(eventNum int) Event {
+	i := eventNum - s.Offset - 1
+	if i < 0 || i >= len(s.Events) {
+		return nil
+	}
+	return s.Events[i]
+}
+
 // LatestMessageNext returns the Next field from the most recent message event
 // Returns empty string if no messages exist
 func (s *Session) LatestMessageNext() string {
-	for i := len(s.Events) - 1; i >= 0; i-- {
-		if msg, ok := s.Events[i].(*MessageEvent); ok {
-			return msg.Next
-		}
-	}
-	return ""
+	return s.LatestNext
 }
 
 // addEvent adds an event and updates participant state
 func (s *Session) addEvent(event Event) {
 	s.Events = append(s.Events, event)
+	s.applyEvent(event)
+}
 
+// applyEvent updates derived state for an event without recording it
+func (s *Session) applyEvent(event Event) {
+	s.Applied++
+	s.LastActivityMillis = event.GetTimestamp()
 	switch e := event.(type) {
+	case *SessionCreatedEvent:
+		s.Encrypted = e.Encrypted
+		s.CreatedMillis = e.GetTimestamp()
 	case *JoinedEvent:
 		s.Participants[e.Participant] = true
 	case *LeftEvent:
 		s.Participants[e.Participant] = false
+	case *MessageEvent:
+		s.LatestNext = e.Next
+	case *SessionUpdatedEvent:
+		s.Metadata = e.Metadata
+	case *PollEvent:
+		s.addPoll(s.Applied, e)
+	case *VoteEvent:
+		if poll := s.Polls[e.Poll]; poll != nil {
+			poll.Votes[e.Participant] = e.Choice
+		}
+	case *DecisionEvent:
+		s.addDecision(s.Applied, e)
+	case *FactSetEvent:
+		s.setFact(s.Applied, e.GetTimestamp(), e.Participant, e.Key, e.Value, false)
+	case *FactDeletedEvent:
+		s.setFact(s.Applied, e.GetTimestamp(), e.Participant, e.Key, "", true)
+	case *TaskAddedEvent:
+		s.addTask(s.Applied, e)
+	case *TaskAssignedEvent:
+		if task := s.Tasks[e.Task]; task != nil {
+			task.Owner = e.Owner
+		}
+	case *TaskDoneEvent:
+		if task := s.Tasks[e.Task]; task != nil && task.DoneAt == 0 {
+			task.DoneBy = e.Participant
+			task.DoneAt = s.Applied
+		}
+	case *ForkedFromEvent:
+		// Participants of the parent don't carry over; they must join the fork
+		for name := range s.Participants {
+			s.Participants[name] = false
+		}
+		s.LatestNext = ""
+		s.ForkedFrom = &ForkOrigin{
+			SessionID:   e.ParentSession,
+			EventNum:    e.ParentEvent,
+			WithHistory: e.WithHistory,
+		}
 	}
 }
 
-// LoadSession reads and parses a session file
+// LoadSession reads a session's current state from the current store. If the
+// store keeps snapshots, the state starts from the newest valid one and only
+// the events after it are read, so Events holds just those, with Offset set
+// accordingly. Use LoadHistory where every event is needed.
+// A new snapshot is saved once enough events have been replayed.
 func LoadSession(sessionID string) (*Session, error) {
-	path, err := storage.SessionEventsPath(sessionID)
+	session := NewSession(sessionID)
+	snapshotter, _ := currentStore.(Snapshotter)
+	if snapshotter != nil {
+		if snap, err := snapshotter.LatestSnapshot(sessionID); err == nil && snap != nil {
+			session.State = snap.State
+			session.Offset = snap.EventCount
+		}
+	}
+
+	events, err := currentStore.ReadFrom(sessionID, session.Offset)
 	if err != nil {
 		return nil, err
 	}
-
-	file, err := os.Open(path)
-	if os.IsNotExist(err) {
-		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	session.Events = events
+	for _, event := range events {
+		session.applyEvent(event)
 	}
+	session.saveSnapshotSince(snapshotter, session.Offset)
+	return session, nil
+}
+
+// LoadHistory reads a session with every event from the current store, for
+// transcripts, threads, forks and validation against earlier messages.
+// Every event is replayed, so it saves snapshots like LoadSession does.
+func LoadHistory(sessionID string) (*Session, error) {
+	events, err := currentStore.ReadFrom(sessionID, 0)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	return readSessionFromReader(sessionID, file)
+	session := NewSession(sessionID)
+	for _, event := range events {
+		session.addEvent(event)
+	}
+
+	if snapshotter, ok := currentStore.(Snapshotter); ok {
+		since := 0
+		if snap, err := snapshotter.LatestSnapshot(sessionID); err == nil && snap != nil {
+			since = snap.EventCount
+		}
+		session.saveSnapshotSince(snapshotter, since)
+	}
+	return session, nil
+}
+
+// saveSnapshotSince saves a snapshot of a loaded session's state once
+// snapshotInterval events were replayed since the newest snapshot, as of
+// event number since (0 for none)
+func (s *Session) saveSnapshotSince(snapshotter Snapshotter, since int) {
+	if snapshotter != nil && s.EventCount()-since >= snapshotInterval {
+		// Best effort: a read-only session dir shouldn't break loads
+		_ = snapshotter.SaveSnapshot(s.ID, s.EventCount(), s.State)
+	}
+}
+
+// LoadSessionAfter loads a session's current state but only the events after
+// event number afterN. Stores implementing TailReader avoid decoding the
+// earlier events; others fall back to a full load.
+func LoadSessionAfter(sessionID string, afterN int) (*Session, error) {
+	if tr, ok := currentStore.(TailReader); ok {
+		return tr.ReadTail(sessionID, afterN)
+	}
+	return LoadHistory(sessionID)
 }
 
 // readSessionFromReader parses session events from a reader
 func readSessionFromReader(sessionID string, r io.Reader) (*Session, error) {
+	events, err := readEventsFromReader(r, sessionID, 1, false)
+	if err != nil {
+		return nil, err
+	}
+
 	session := NewSession(sessionID)
+	for _, event := range events {
+		session.addEvent(event)
+	}
+	return session, nil
+}
+
+// readEventsFromReader parses JSONL events from a reader, the first being
+// event number num of a session. encrypted says if the session is; it's
+// ignored when reading from its first event. Unparseable lines are reported
+// as *errors.CorruptSessionError without a session ID, and encrypted lines
+// that can't be decrypted with a key error.
+func readEventsFromReader(r io.Reader, sessionID string, num int, encrypted bool) ([]Event, error) {
+	events := []Event{}
 	scanner := bufio.NewScanner(r)
+	lineNum := 0
 
 	for scanner.Scan() {
+		lineNum++
 		line := scanner.Bytes()
 		if len(line) == 0 {
 			continue
 		}
 
-		event, err := ParseEvent(line)
+		if num == 1 {
+			encrypted = isEncrypted(line)
+		}
+		event, err := parseStoredEvent(sessionID, num, line, encrypted)
 		if err != nil {
-			return nil, err
+			return nil, eventError(err, "", lineNum)
 		}
-		session.addEvent(event)
+		events = append(events, event)
+		num++
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	return session, nil
-}
-
-// FileLocker provides exclusive file locking
-type FileLocker struct {
-	file *os.File
+	return events, nil
 }
 
-// AcquireLock opens the file and acquires an exclusive lock
-func AcquireLock(path string) (*FileLocker, error) {
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return nil, err
-	}
-
-	// Acquire exclusive lock (blocking)
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
-		f.Close()
-		return nil, err
+// withSessionID fills in the session ID of a CorruptSessionError or key error
+func withSessionID(err error, sessionID string) error {
+	switch e := err.(type) {
+	case *errors.CorruptSessionError:
+		e.SessionID = sessionID
+	case *errors.EncryptionKeyMissingError:
+		e.SessionID = sessionID
+	case *errors.EncryptionKeyMismatchError:
+		e.SessionID = sessionID
 	}
-
-	return &FileLocker{file: f}, nil
-}
-
-// Release unlocks and closes the file
-func (l *FileLocker) Release() error {
-	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
-	return l.file.Close()
+	return err
 }
 
-// File returns the underlying file for reading/writing
-func (l *FileLocker) File() *os.File {
-	return l.file
+// CreateOptions configures a new session
+type CreateOptions struct {
+	Metadata  Metadata
+	HashChain bool // chain each event to the one before it by hash
+	Encrypt   bool // encrypt each event with the configured key
 }
 
-// CreateSession creates a new session file with a session_created event
+// CreateSession creates a new session with a session_created event
 func CreateSession(sessionID string) error {
-	if err := storage.EnsureSessionDir(sessionID); err != nil {
-		return err
-	}
+	return CreateSessionWithOptions(sessionID, CreateOptions{})
+}
 
-	path, err := storage.SessionEventsPath(sessionID)
+// CreateSessionWithOptions creates a new session, recording any non-empty
+// metadata in a session_updated event written together with session_created.
+// Returns SessionExistsError if the ID is taken, including by an archived session.
+func CreateSessionWithOptions(sessionID string, opts CreateOptions) error {
+	events, err := initialEvents(sessionID, opts)
 	if err != nil {
 		return err
 	}
+	return createWithEvents(sessionID, events)
+}
 
-	lock, err := AcquireLock(path)
-	if err != nil {
-		return err
+// initialEvents builds the events that start a new session
+func initialEvents(sessionID string, opts CreateOptions) ([]Event, error) {
+	created := NewSessionCreatedEvent(sessionID)
+	created.HashChain = opts.HashChain
+	created.Encrypted = opts.Encrypt
+
+	// Fail before anything is written rather than leave an empty session
+	if opts.Encrypt {
+		if _, _, err := encryptionKey(); err != nil {
+			return nil, err
+		}
 	}
-	defer lock.Release()
 
-	event := NewSessionCreatedEvent(sessionID)
-	eventBytes, err := MarshalEvent(event)
-	if err != nil {
-		return err
+	events := []Event{created}
+	if meta := opts.Metadata; !meta.IsEmpty() {
+		normalized, err := meta.normalized()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, NewSessionUpdatedEvent(normalized))
 	}
+	return events, nil
+}
 
-	_, err = lock.File().Write(append(eventBytes, '\n'))
+// createWithEvents writes the initial events of a new session. The store's
+// count check makes this atomic: a session that already has events is never
+// appended to.
+func createWithEvents(sessionID string, events []Event) error {
+	err := currentStore.Append(sessionID, 0, events...)
+	switch err.(type) {
+	case *errors.StaleStateError, *errors.SessionArchivedError:
+		return &errors.SessionExistsError{SessionID: sessionID}
+	}
 	return err
 }
 
-// JoinSession adds a participant to a session
+// appendWithRetry builds an event from the latest session state and appends it.
+// If another writer appends first, the state is re-read and the event rebuilt,
+// so validation always runs against the state the event is appended to.
 // Returns the new event number (1-indexed for display)
-func JoinSession(sessionID, name string) (int, error) {
-	// Validate reserved name
-	if IsReservedName(name) {
-		return 0, &errors.ReservedNameError{Name: name}
-	}
-
-	path, err := storage.SessionEventsPath(sessionID)
-	if err != nil {
-		return 0, err
-	}
+func appendWithRetry(sessionID string, build func(*Session) (Event, error)) (int, error) {
+	return appendLoaded(LoadSession, sessionID, build)
+}
 
-	// Check session exists
-	exists, err := storage.SessionExists(sessionID)
-	if err != nil {
-		return 0, err
-	}
-	if !exists {
-		return 0, &errors.SessionNotFoundError{SessionID: sessionID}
-	}
+// appendToHistory is appendWithRetry for events validated against earlier
+// events rather than just the state, building them from every event
+func appendToHistory(sessionID string, build func(*Session) (Event, error)) (int, error) {
+	return appendLoaded(LoadHistory, sessionID, build)
+}
 
-	lock, err := AcquireLock(path)
-	if err != nil {
-		return 0, err
-	}
-	defer lock.Release()
+// appendLoaded implements appendWithRetry, loading the session with load
+func appendLoaded(load func(string) (*Session, error), sessionID string, build func(*Session) (Event, error)) (int, error) {
+	for {
+		session, err := load(sessionID)
+		if err != nil {
+			return 0, err
+		}
 
-	// Read current state
-	lock.File().Seek(0, io.SeekStart)
-	session, err := readSessionFromReader(sessionID, lock.File())
-	if err != nil {
-		return 0, err
-	}
+		event, err := build(session)
+		if err != nil {
+			return 0, err
+		}
 
-	// Check for duplicate name
-	if session.IsActiveParticipant(name) {
-		return 0, &errors.NameTakenError{Name: name}
-	}
+		err = currentStore.Append(sessionID, session.EventCount(), event)
+		if _, ok := err.(*errors.StaleStateError); ok {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
 
-	// Append joined event
-	event := NewJoinedEvent(name)
-	eventBytes, err := MarshalEvent(event)
-	if err != nil {
-		return 0, err
+		return session.EventCount() + 1, nil
 	}
+}
 
-	lock.File().Seek(0, io.SeekEnd)
-	_, err = lock.File().Write(append(eventBytes, '\n'))
-	if err != nil {
-		return 0, err
+// JoinSession adds a participant to a session
+// Returns the new event number (1-indexed for display)
+func JoinSession(sessionID, name string) (int, error) {
+	// Validate reserved name
+	if IsReservedName(name) {
+		return 0, &errors.ReservedNameError{Name: name}
 	}
 
-	// Return 1-indexed event number
-	return session.EventCount() + 1, nil
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		// Check for duplicate name
+		if session.IsActiveParticipant(name) {
+			return nil, &errors.NameTakenError{Name: name}
+		}
+		return NewJoinedEvent(name), nil
+	})
 }
 
 // LeaveSession removes a participant from a session
 func LeaveSession(sessionID, name string) error {
-	path, err := storage.SessionEventsPath(sessionID)
-	if err != nil {
-		return err
-	}
-
-	// Check session exists
-	exists, err := storage.SessionExists(sessionID)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return &errors.SessionNotFoundError{SessionID: sessionID}
-	}
-
-	lock, err := AcquireLock(path)
-	if err != nil {
-		return err
-	}
-	defer lock.Release()
-
-	// Read current state
-	lock.File().Seek(0, io.SeekStart)
-	session, err := readSessionFromReader(sessionID, lock.File())
-	if err != nil {
-		return err
-	}
-
-	// Check participant is active
-	if !session.IsActiveParticipant(name) {
-		return &errors.ParticipantNotInSessionError{Name: name, SessionID: sessionID}
-	}
-
-	// Append left event
-	event := NewLeftEvent(name)
-	eventBytes, err := MarshalEvent(event)
-	if err != nil {
-		return err
-	}
-
-	lock.File().Seek(0, io.SeekEnd)
-	_, err = lock.File().Write(append(eventBytes, '\n'))
+	_, err := appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		// Check participant is active
+		if !session.IsActiveParticipant(name) {
+			return nil, &errors.ParticipantNotInSessionError{Name: name, SessionID: sessionID}
+		}
+		return NewLeftEvent(name), nil
+	})
 	return err
 }
 
-// PostMessage posts a message to a session with optimistic locking
+// PostMessage posts a message to a session with optimistic locking.
+// @mentions of active participants and the Moderator are recorded with it.
 // Returns the new event number (1-indexed for display)
 func PostMessage(sessionID, participant, content, next string, afterEventNum int) (int, error) {
-	path, err := storage.SessionEventsPath(sessionID)
-	if err != nil {
-		return 0, err
-	}
+	return PostReply(sessionID, participant, content, next, 0, afterEventNum)
+}
 
-	// Check session exists
-	exists, err := storage.SessionExists(sessionID)
+// PostReply posts a message like PostMessage, marking it as a reply to the
+// message at event number replyTo (0 for none)
+func PostReply(sessionID, participant, content, next string, replyTo, afterEventNum int) (int, error) {
+	// The previous speaker and the message replied to may predate snapshots
+	session, err := LoadHistory(sessionID)
 	if err != nil {
 		return 0, err
 	}
-	if !exists {
-		return 0, &errors.SessionNotFoundError{SessionID: sessionID}
+
+	// Optimistic lock check (re-checked atomically by the store on append)
+	if session.EventCount() != afterEventNum {
+		return 0, &errors.StaleStateError{
+			ExpectedEventNum: afterEventNum,
+			ActualEventNum:   session.EventCount(),
+			SessionID:        sessionID,
+		}
 	}
 
-	lock, err := AcquireLock(path)
+	event, err := newValidMessage(session, participant, content, next, replyTo)
 	if err != nil {
 		return 0, err
 	}
-	defer lock.Release()
 
-	// Read current state
-	lock.File().Seek(0, io.SeekStart)
-	session, err := readSessionFromReader(sessionID, lock.File())
-	if err != nil {
+	// Append message event
+	if err := currentStore.Append(sessionID, afterEventNum, event); err != nil {
 		return 0, err
 	}
 
-	// Optimistic lock check
-	if session.EventCount() != afterEventNum {
-		return 0, &errors.StaleStateError{
-			ExpectedEventNum: afterEventNum,
-			ActualEventNum:   session.EventCount(),
-			SessionID:        sessionID,
-		}
-	}
+	// Return 1-indexed event number
+	return afterEventNum + 1, nil
+}
 
+// newValidMessage builds a message event posted to a session in its current
+// state, enforcing the posting rules and defaulting the next speaker
+func newValidMessage(session *Session, participant, content, next string, replyTo int) (*MessageEvent, error) {
 	// Check participant is active (Moderator is always allowed to post)
 	if participant != "Moderator" && !session.IsActiveParticipant(participant) {
-		return 0, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		return nil, &errors.NotAParticipantError{Name: participant, SessionID: session.ID}
 	}
 
 	// Determine next speaker if not provided
@@ -368,22 +514,27 @@ func PostMessage(sessionID, participant, content, next string, afterEventNum int
 
 	// Validate next is an active participant or "Moderator"
 	if next != "Moderator" && !session.IsActiveParticipant(next) {
-		return 0, &errors.InvalidNextParticipantError{Name: next}
+		return nil, &errors.InvalidNextParticipantError{Name: next}
 	}
 
-	// Append message event
-	event := NewMessageEvent(participant, content, next)
-	eventBytes, err := MarshalEvent(event)
-	if err != nil {
-		return 0, err
+	if err := validReplyTo(session, replyTo); err != nil {
+		return nil, err
 	}
 
-	lock.File().Seek(0, io.SeekEnd)
-	_, err = lock.File().Write(append(eventBytes, '\n'))
-	if err != nil {
-		return 0, err
-	}
+	event := NewMessageEvent(participant, content, next)
+	event.ReplyTo = replyTo
+	event.Mentions = parseMentions(session, participant, content)
+	return event, nil
+}
 
-	// Return 1-indexed event number
-	return session.EventCount() + 1, nil
+// validReplyTo checks that replyTo is 0 or the number of a message among the
+// session's loaded events
+func validReplyTo(session *Session, replyTo int) error {
+	if replyTo == 0 {
+		return nil
+	}
+	if _, ok := session.Event(replyTo).(*MessageEvent); !ok {
+		return &errors.MessageNotFoundError{SessionID: session.ID, EventNum: replyTo}
+	}
+	return nil
 }
@@ -280,11 +280,15 @@ not valid json
 }
 
 func TestReadSessionFromReaderUnknownEventType(t *testing.T) {
-	input := `{"type":"unknown_type","timestamp_millis":1234567890}
+	input := `{"type":"session_created","timestamp_millis":1,"id":"test"}
+{"type":"unknown_type","timestamp_millis":1234567890}
 `
 
-	_, err := readSessionFromReader("test", strings.NewReader(input))
-	if err == nil {
-		t.Error("expected error for unknown event type")
+	sess, err := readSessionFromReader("test", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := sess.Events[1].(*RawEvent); !ok {
+		t.Errorf("expected unknown event to be kept as *RawEvent, got %T", sess.Events[1])
 	}
 }
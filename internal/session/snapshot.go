@@ -0,0 +1,212 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amterp/council/internal/storage"
+)
+
+// snapshotVersion is bumped whenever the snapshot format or the derived
+// State changes shape, so snapshots written by older versions are ignored
+const snapshotVersion = 7
+
+// snapshotInterval is the number of events replayed since the last snapshot
+// after which LoadSession writes a new one
+const snapshotInterval = 100
+
+// snapshotsKept is the number of most recent snapshots kept per session
+const snapshotsKept = 2
+
+// Snapshot is a session's derived state as of an event, saved so loads can
+// skip replaying the events before it. It records the hash of that event's
+// stored line, so a snapshot no longer matching the log is never used.
+// Snapshots of encrypted sessions are stored encrypted, bound to the session
+// and event they're of.
+type Snapshot struct {
+	Version    int    `json:"version"`
+	EventCount int    `json:"event_count"` // events replayed into State
+	Offset     int64  `json:"offset"`      // byte offset of the last replayed event's line
+	LineHash   string `json:"line_hash"`   // hash of that line, as in hash chains
+	State      State  `json:"state"`
+}
+
+// Snapshotter is implemented by stores that keep snapshots of derived state
+// next to the log. Snapshots are a cache: deleting them only slows loads.
+type Snapshotter interface {
+	// LatestSnapshot returns the newest snapshot that matches the log, or
+	// nil if there is none
+	LatestSnapshot(sessionID string) (*Snapshot, error)
+
+	// SaveSnapshot stores state as of event number eventCount, which must be
+	// a complete line in the log
+	SaveSnapshot(sessionID string, eventCount int, state State) error
+}
+
+// LatestSnapshot implements Snapshotter
+func (s *FileStore) LatestSnapshot(sessionID string) (*Snapshot, error) {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	nums, err := snapshotNums(dir)
+	if err != nil || len(nums) == 0 {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(dir, storage.EventsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	for i := len(nums) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(snapshotPath(dir, nums[i]))
+		if err != nil {
+			continue
+		}
+		encrypted := isEncrypted(data)
+		if encrypted {
+			if data, err = openLine(data, lineAD(adSnapshot, sessionID, nums[i])); err != nil {
+				continue
+			}
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil || snap.Version != snapshotVersion || snap.EventCount != nums[i] {
+			continue
+		}
+		// Snapshots of encrypted sessions, whose lines are all encrypted,
+		// must be encrypted too
+		line, err := readLineAt(file, snap.Offset)
+		if err != nil || hashLine(line) != snap.LineHash || snap.State.Encrypted != encrypted || isEncrypted(line) != encrypted {
+			continue
+		}
+		if snap.State.Participants == nil {
+			snap.State.Participants = make(map[string]bool)
+		}
+		return &snap, nil
+	}
+	return nil, nil
+}
+
+// SaveSnapshot implements Snapshotter
+func (s *FileStore) SaveSnapshot(sessionID string, eventCount int, state State) error {
+	dir, _, err := s.sessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// The index, which loads keep up to date, locates the event's line
+	file, err := os.Open(filepath.Join(dir, storage.EventsFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	idx := loadIndex(dir)
+	if idx == nil || !idx.validFor(file) || eventCount < 1 || eventCount > len(idx.Offsets) {
+		return fmt.Errorf("event #%d of session '%s' isn't indexed", eventCount, sessionID)
+	}
+	offset := idx.Offsets[eventCount-1]
+	line, err := readLineAt(file, offset)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Snapshot{
+		Version:    snapshotVersion,
+		EventCount: eventCount,
+		Offset:     offset,
+		LineHash:   hashLine(line),
+		State:      state,
+	})
+	if err != nil {
+		return err
+	}
+	if state.Encrypted {
+		if data, err = sealLine(data, lineAD(adSnapshot, sessionID, eventCount)); err != nil {
+			return err
+		}
+	}
+	if err := writeFileAtomic(snapshotPath(dir, eventCount), data); err != nil {
+		return err
+	}
+	return pruneSnapshots(dir)
+}
+
+// readLineAt reads the newline-terminated line starting at offset, without
+// the newline
+func readLineAt(f *os.File, offset int64) ([]byte, error) {
+	var line []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := f.ReadAt(buf, offset+int64(len(line)))
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return append(line, buf[:i]...), nil
+		}
+		line = append(line, buf[:n]...)
+		if err != nil {
+			return nil, fmt.Errorf("no complete line at offset %d", offset)
+		}
+	}
+}
+
+// snapshotPath returns the path of the snapshot as of an event
+func snapshotPath(dir string, eventCount int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%d.json", storage.SnapshotPrefix, eventCount))
+}
+
+// snapshotNums returns the event numbers of the snapshots in a session
+// directory, in ascending order
+func snapshotNums(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	nums := []int{}
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), storage.SnapshotPrefix)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err == nil && strings.HasSuffix(name, ".json") {
+			nums = append(nums, n)
+		}
+	}
+	sort.Ints(nums)
+	return nums, nil
+}
+
+// pruneSnapshots removes all but the most recent snapshots in a session directory
+func pruneSnapshots(dir string) error {
+	nums, err := snapshotNums(dir)
+	if err != nil {
+		return err
+	}
+	for len(nums) > snapshotsKept {
+		if err := os.Remove(snapshotPath(dir, nums[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		nums = nums[1:]
+	}
+	return nil
+}
+
+// removeSnapshots deletes every snapshot in a session directory
+func removeSnapshots(dir string) error {
+	nums, err := snapshotNums(dir)
+	if err != nil {
+		return err
+	}
+	for _, n := range nums {
+		if err := os.Remove(snapshotPath(dir, n)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
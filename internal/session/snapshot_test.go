@@ -0,0 +1,171 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+	"github.com/amterp/council/internal/storage"
+)
+
+// newLongSession creates a file-backed session with Alice joined and enough
+// messages (102 events) for the loads done while posting to write a snapshot
+// as of event #100
+func newLongSession(t *testing.T) string {
+	t.Helper()
+	useFileStore(t)
+	CreateSession("sess")
+	JoinSession("sess", "Alice")
+	for i := 0; i < snapshotInterval; i++ {
+		if _, err := PostMessage("sess", "Alice", "hello", "Moderator", i+2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return sessionDirPath(t, "sess")
+}
+
+func TestLoadSessionWritesSnapshot(t *testing.T) {
+	dir := newLongSession(t)
+
+	nums, _ := snapshotNums(dir)
+	if len(nums) != 1 || nums[0] != snapshotInterval {
+		t.Fatalf("expected one snapshot at #%d, got %v", snapshotInterval, nums)
+	}
+
+	snap, err := NewFileStore().LatestSnapshot("sess")
+	if err != nil || snap == nil {
+		t.Fatalf("expected a valid snapshot, got %v (%v)", snap, err)
+	}
+	if !snap.State.Participants["Alice"] || snap.State.LatestNext != "Moderator" {
+		t.Errorf("unexpected snapshot state: %+v", snap.State)
+	}
+}
+
+func TestLoadSessionStartsFromSnapshot(t *testing.T) {
+	dir := newLongSession(t)
+	JoinSession("sess", "Bob")
+
+	// Doctor the snapshot's state to see that it's used rather than replayed
+	path := snapshotPath(dir, snapshotInterval)
+	var snap Snapshot
+	data, _ := os.ReadFile(path)
+	json.Unmarshal(data, &snap)
+	snap.State.Participants["Carol"] = true
+	data, _ = json.Marshal(snap)
+	os.WriteFile(path, data, 0644)
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sess.IsActiveParticipant("Carol") {
+		t.Error("expected state to start from the snapshot")
+	}
+	if !sess.IsActiveParticipant("Bob") {
+		t.Error("expected events after the snapshot to be replayed")
+	}
+	if sess.EventCount() != snapshotInterval+3 {
+		t.Errorf("expected all events to be loaded, got %d", sess.EventCount())
+	}
+}
+
+func TestLoadSessionSkipsEventsBeforeSnapshot(t *testing.T) {
+	dir := newLongSession(t)
+
+	// Garble an early line without moving any other; decoding it would fail
+	path := filepath.Join(dir, storage.EventsFile)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[4] = "{" + strings.Repeat("x", len(lines[4])-2) + "\n"
+	os.WriteFile(path, []byte(strings.Join(lines, "")), 0644)
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("events before the snapshot shouldn't be decoded, got %v", err)
+	}
+	if sess.Offset != snapshotInterval || len(sess.Events) != 2 || !sess.IsActiveParticipant("Alice") {
+		t.Errorf("expected state from the snapshot and the 2 events after it, got offset %d and %d events", sess.Offset, len(sess.Events))
+	}
+
+	if _, err := LoadHistory("sess"); !isErr[*errors.CorruptSessionError](err) {
+		t.Errorf("expected LoadHistory to decode every event, got %v", err)
+	}
+}
+
+func TestLatestSnapshotIgnoresEditedLog(t *testing.T) {
+	dir := newLongSession(t)
+
+	path := filepath.Join(dir, storage.EventsFile)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[snapshotInterval-1] = strings.Replace(lines[snapshotInterval-1], "hello", "HELLO", 1)
+	os.WriteFile(path, []byte(strings.Join(lines, "")), 0644)
+
+	snap, err := NewFileStore().LatestSnapshot("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap != nil {
+		t.Errorf("expected snapshot not matching the log to be ignored, got #%d", snap.EventCount)
+	}
+}
+
+func TestLatestSnapshotIgnoresTruncatedLog(t *testing.T) {
+	dir := newLongSession(t)
+
+	path := filepath.Join(dir, storage.EventsFile)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	os.WriteFile(path, []byte(strings.Join(lines[:10], "")), 0644)
+
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sess.EventCount() != 10 || !sess.IsActiveParticipant("Alice") {
+		t.Errorf("expected state replayed from the shorter log, got %d events", sess.EventCount())
+	}
+}
+
+func TestLoadSessionWithoutSnapshots(t *testing.T) {
+	dir := newLongSession(t)
+
+	if err := removeSnapshots(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sess, err := LoadSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sess.IsActiveParticipant("Alice") || sess.EventCount() != snapshotInterval+2 {
+		t.Errorf("unexpected session after deleting snapshots: %d events", sess.EventCount())
+	}
+}
+
+func TestSnapshotsArePruned(t *testing.T) {
+	dir := newLongSession(t)
+	removeSnapshots(dir)
+	store := NewFileStore()
+	state := newState()
+
+	for _, n := range []int{10, 20, 30} {
+		if err := store.SaveSnapshot("sess", n, state); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	nums, _ := snapshotNums(dir)
+	if len(nums) != snapshotsKept || nums[0] != 20 || nums[1] != 30 {
+		t.Errorf("expected snapshots [20 30], got %v", nums)
+	}
+}
+
+func TestSaveSnapshotRejectsMissingEvent(t *testing.T) {
+	newLongSession(t)
+
+	if err := NewFileStore().SaveSnapshot("sess", 1000, newState()); err == nil {
+		t.Error("expected error for a snapshot past the end of the log")
+	}
+}
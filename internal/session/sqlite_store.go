@@ -0,0 +1,418 @@
+package session
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/amterp/council/internal/errors"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	session_id TEXT NOT NULL,
+	seq        INTEGER NOT NULL,
+	data       TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+);
+CREATE TABLE IF NOT EXISTS archived (
+	session_id  TEXT PRIMARY KEY,
+	archived_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS artifacts (
+	session_id TEXT NOT NULL,
+	hash       TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	PRIMARY KEY (session_id, hash)
+)`
+
+// SQLiteStore stores all sessions in a single embedded SQLite database.
+// Each row holds one event as the same JSON used by the JSONL format,
+// keyed by session ID and 1-indexed event number.
+type SQLiteStore struct {
+	db *sql.DB
+
+	// Checksums adds a crc32 field to each event written
+	Checksums bool
+}
+
+// NewSQLiteStore opens (or creates) the database at the given path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	// Immediate transactions take the write lock up front so that the
+	// count check and insert in Append can't interleave across processes.
+	// Full sync makes each commit durable before Append returns.
+	dsn := fmt.Sprintf("file:%s?_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)",
+		url.PathEscape(path))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %w", path, err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Append implements Store
+func (s *SQLiteStore) Append(sessionID string, expectedCount int, events ...Event) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	archived, err := isArchived(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM events WHERE session_id = ?`, sessionID).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 && expectedCount > 0 {
+		return &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if count != expectedCount {
+		return &errors.StaleStateError{
+			ExpectedEventNum: expectedCount,
+			ActualEventNum:   count,
+			SessionID:        sessionID,
+		}
+	}
+
+	var first, last []byte
+	if count > 0 {
+		var firstData, lastData string
+		err = tx.QueryRow(`SELECT data FROM events WHERE session_id = ? AND seq = 1`, sessionID).Scan(&firstData)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(`SELECT data FROM events WHERE session_id = ? AND seq = ?`, sessionID, count).Scan(&lastData)
+		if err != nil {
+			return err
+		}
+		first, last = []byte(firstData), []byte(lastData)
+	}
+
+	lines, err := encodeAppend(sessionID, count, first, last, events, s.Checksums)
+	if err != nil {
+		return withSessionID(err, sessionID)
+	}
+	for i, line := range lines {
+		_, err = tx.Exec(`INSERT INTO events (session_id, seq, data) VALUES (?, ?, ?)`,
+			sessionID, expectedCount+i+1, string(line))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Rewrite implements Rewriter. The database keeps no backup of the old rows.
+func (s *SQLiteStore) Rewrite(sessionID string, expectedCount int, events []Event) (string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	archived, err := isArchived(tx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if archived {
+		return "", &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM events WHERE session_id = ?`, sessionID).Scan(&count)
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return "", &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	if count != expectedCount {
+		return "", &errors.StaleStateError{
+			ExpectedEventNum: expectedCount,
+			ActualEventNum:   count,
+			SessionID:        sessionID,
+		}
+	}
+
+	lines, err := encodeAppend(sessionID, 0, nil, nil, events, s.Checksums)
+	if err != nil {
+		return "", withSessionID(err, sessionID)
+	}
+	// The new log may be shorter or longer than the old one
+	for i, line := range lines {
+		_, err = tx.Exec(`INSERT INTO events (session_id, seq, data) VALUES (?, ?, ?)
+			ON CONFLICT (session_id, seq) DO UPDATE SET data = excluded.data`,
+			sessionID, i+1, string(line))
+		if err != nil {
+			return "", err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM events WHERE session_id = ? AND seq > ?`, sessionID, len(lines)); err != nil {
+		return "", err
+	}
+
+	return "", tx.Commit()
+}
+
+// ReadFrom implements Store
+func (s *SQLiteStore) ReadFrom(sessionID string, offset int) ([]Event, error) {
+	exists, err := s.Exists(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+
+	// Whether the session is encrypted shows in its first line, which the
+	// rows below only include when reading from the start
+	encrypted := false
+	if offset > 0 {
+		var first string
+		err := s.db.QueryRow(`SELECT data FROM events WHERE session_id = ? AND seq = 1`, sessionID).Scan(&first)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = isEncrypted([]byte(first))
+	}
+
+	rows, err := s.db.Query(`SELECT seq, data FROM events WHERE session_id = ? AND seq > ? ORDER BY seq`,
+		sessionID, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var seq int
+		var data string
+		if err := rows.Scan(&seq, &data); err != nil {
+			return nil, err
+		}
+		if seq == 1 {
+			encrypted = isEncrypted([]byte(data))
+		}
+		event, err := parseStoredEvent(sessionID, seq, []byte(data), encrypted)
+		if err != nil {
+			return nil, eventError(err, sessionID, seq)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// List implements Store
+func (s *SQLiteStore) List() ([]string, error) {
+	return s.queryIDs(`SELECT DISTINCT session_id FROM events
+		WHERE session_id NOT IN (SELECT session_id FROM archived) ORDER BY session_id`)
+}
+
+// ListArchived implements Store
+func (s *SQLiteStore) ListArchived() ([]string, error) {
+	return s.queryIDs(`SELECT session_id FROM archived ORDER BY session_id`)
+}
+
+// queryIDs runs a query returning a single column of session IDs
+func (s *SQLiteStore) queryIDs(query string) ([]string, error) {
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Exists implements Store
+func (s *SQLiteStore) Exists(sessionID string) (bool, error) {
+	var one int
+	err := s.db.QueryRow(`SELECT 1 FROM events WHERE session_id = ? LIMIT 1`, sessionID).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Archive implements Store
+func (s *SQLiteStore) Archive(sessionID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireSession(tx, sessionID); err != nil {
+		return err
+	}
+
+	archived, err := isArchived(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	_, err = tx.Exec(`INSERT INTO archived (session_id, archived_at) VALUES (?, ?)`, sessionID, Now())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete implements Store
+func (s *SQLiteStore) Delete(sessionID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireSession(tx, sessionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM events WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM archived WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM artifacts WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PutArtifact implements ArtifactStore
+func (s *SQLiteStore) PutArtifact(sessionID, hash string, data []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireSession(tx, sessionID); err != nil {
+		return err
+	}
+	if archived, err := isArchived(tx, sessionID); err != nil {
+		return err
+	} else if archived {
+		return &errors.SessionArchivedError{SessionID: sessionID}
+	}
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO artifacts (session_id, hash, data) VALUES (?, ?, ?)`,
+		sessionID, hash, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetArtifact implements ArtifactStore
+func (s *SQLiteStore) GetArtifact(sessionID, hash string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM artifacts WHERE session_id = ? AND hash = ?`, sessionID, hash).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, os.ErrNotExist
+	}
+	return data, err
+}
+
+// requireSession returns SessionNotFoundError if the session has no events
+func requireSession(tx *sql.Tx, sessionID string) error {
+	var one int
+	err := tx.QueryRow(`SELECT 1 FROM events WHERE session_id = ? LIMIT 1`, sessionID).Scan(&one)
+	if err == sql.ErrNoRows {
+		return &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+	return err
+}
+
+// isArchived checks if a session has been archived
+func isArchived(tx *sql.Tx, sessionID string) (bool, error) {
+	var one int
+	err := tx.QueryRow(`SELECT 1 FROM archived WHERE session_id = ?`, sessionID).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadRaw implements Store
+func (s *SQLiteStore) ReadRaw(sessionID string) ([]RawLine, error) {
+	return s.ReadRawFrom(sessionID, 1, 0)
+}
+
+// ReadRawFrom implements RawTailReader. Events are stored by number, so the
+// offset isn't needed.
+func (s *SQLiteStore) ReadRawFrom(sessionID string, num int, offset int64) ([]RawLine, error) {
+	exists, err := s.Exists(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &errors.SessionNotFoundError{SessionID: sessionID}
+	}
+
+	rows, err := s.db.Query(`SELECT seq, data FROM events WHERE session_id = ? AND seq >= ? ORDER BY seq`, sessionID, num)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []RawLine{}
+	for rows.Next() {
+		var seq int
+		var data string
+		if err := rows.Scan(&seq, &data); err != nil {
+			return nil, err
+		}
+		lines = append(lines, RawLine{Num: seq, Data: []byte(data), Terminated: true})
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,133 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/amterp/council/internal/config"
+	"github.com/amterp/council/internal/storage"
+)
+
+// Store persists session event logs.
+// Event numbers are positions in the log (1-indexed for display), so
+// implementations must never reorder or drop events.
+type Store interface {
+	// Append atomically appends events if the log currently holds exactly
+	// expectedCount events. An expectedCount of 0 creates the session.
+	// Returns *errors.StaleStateError if the count doesn't match and
+	// *errors.SessionNotFoundError if appending to a missing session.
+	Append(sessionID string, expectedCount int, events ...Event) error
+
+	// ReadFrom returns all events after the first offset events.
+	// Returns *errors.SessionNotFoundError if the session doesn't exist.
+	ReadFrom(sessionID string, offset int) ([]Event, error)
+
+	// List returns the IDs of all non-archived sessions, sorted
+	List() ([]string, error)
+
+	// Exists checks if a session has been created (archived or not)
+	Exists(sessionID string) (bool, error)
+
+	// Archive makes a session read-only and removes it from List.
+	// Archived sessions remain readable via ReadFrom.
+	Archive(sessionID string) error
+
+	// ListArchived returns the IDs of all archived sessions, sorted
+	ListArchived() ([]string, error)
+
+	// Delete permanently removes a session, archived or not
+	Delete(sessionID string) error
+
+	// ReadRaw returns the session's log lines as stored, without parsing,
+	// so integrity checks can report on lines that don't parse
+	ReadRaw(sessionID string) ([]RawLine, error)
+}
+
+// RawLine is one stored line of a session log
+type RawLine struct {
+	Num        int    // 1-indexed line number (event number for database stores)
+	Offset     int64  // byte offset of the line in the log file
+	Data       []byte // line contents without the trailing newline
+	Terminated bool   // false only for a final line missing its newline
+}
+
+// RawTailReader is implemented by stores that can read a log's raw lines
+// from a known line onwards, without reading the lines before it
+type RawTailReader interface {
+	// ReadRawFrom returns the lines from line number num, which starts at
+	// byte offset offset, onwards. It returns no lines if the log is no
+	// longer that long.
+	ReadRawFrom(sessionID string, num int, offset int64) ([]RawLine, error)
+}
+
+// TailRepairer is implemented by stores whose logs can be left with a
+// partially written tail by a process killed mid-write
+type TailRepairer interface {
+	// RepairTail moves everything from byte offset quarantineFrom onwards
+	// into a separate file and ensures the log ends with a newline. It
+	// fails if the log is no longer size bytes long, i.e. was written to
+	// since it was checked. Returns the quarantine file's path, or "" if
+	// nothing was quarantined.
+	RepairTail(sessionID string, size, quarantineFrom int64) (string, error)
+}
+
+// TailReader is implemented by stores that can load a session's derived state
+// without decoding every event. The returned session's Events holds only the
+// events after offset, with Session.Offset set accordingly.
+type TailReader interface {
+	ReadTail(sessionID string, offset int) (*Session, error)
+}
+
+// ArtifactStore is implemented by stores that keep session artifacts: files
+// shared in a session, stored under a hex hash named by the caller (the
+// content hash, or a keyed hash of it for encrypted sessions). Artifacts are
+// stored as given; sealing them for encrypted sessions is up to the caller.
+type ArtifactStore interface {
+	// PutArtifact stores data under hash, doing nothing if something is
+	// already stored under it. Returns *errors.SessionNotFoundError or
+	// *errors.SessionArchivedError if the session can't take artifacts.
+	PutArtifact(sessionID, hash string, data []byte) error
+
+	// GetArtifact returns the data stored under hash, or an error satisfying
+	// os.IsNotExist if there's none
+	GetArtifact(sessionID, hash string) ([]byte, error)
+}
+
+// currentStore is the store used by the package-level session operations
+var currentStore Store = NewFileStore()
+
+// SetStore replaces the store used by session operations
+func SetStore(s Store) {
+	currentStore = s
+}
+
+// CurrentStore returns the store used by session operations
+func CurrentStore() Store {
+	return currentStore
+}
+
+// OpenStore creates the store selected by the given config
+func OpenStore(cfg config.Config) (Store, error) {
+	switch cfg.Store {
+	case config.StoreFile, "":
+		store := NewFileStore()
+		store.Checksums = cfg.Checksums
+		store.LockTimeout = cfg.LockTimeoutDuration()
+		if cfg.LockStrategy == config.LockLockfile {
+			store.Locker = LockfileLocker{Lease: cfg.LockLeaseDuration()}
+		}
+		return store, nil
+	case config.StoreSQLite:
+		path, err := storage.DatabasePath()
+		if err != nil {
+			return nil, err
+		}
+		store, err := NewSQLiteStore(path)
+		if err != nil {
+			return nil, err
+		}
+		store.Checksums = cfg.Checksums
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown store: %s", cfg.Store)
+	}
+}
@@ -0,0 +1,278 @@
+package session
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// storeFactories returns a fresh instance of each Store implementation,
+// isolated from the real ~/.council directory
+func storeFactories() map[string]func(t *testing.T) Store {
+	return map[string]func(t *testing.T) Store{
+		"file": func(t *testing.T) Store {
+			t.Setenv("HOME", t.TempDir())
+			return NewFileStore()
+		},
+		"sqlite": func(t *testing.T) Store {
+			s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "council.db"))
+			if err != nil {
+				t.Fatalf("failed to open sqlite store: %v", err)
+			}
+			t.Cleanup(func() { s.Close() })
+			return s
+		},
+	}
+}
+
+// useStore installs a store for the duration of a test
+func useStore(t *testing.T, s Store) {
+	t.Helper()
+	prev := CurrentStore()
+	SetStore(s)
+	t.Cleanup(func() { SetStore(prev) })
+}
+
+// newAliceAndBobSession creates a session "sess" in the current store that
+// Alice and Bob have joined (3 events)
+func newAliceAndBobSession(t *testing.T) {
+	t.Helper()
+	if err := CreateSession("sess"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := JoinSession("sess", name); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestStoreAppendAndReadFrom(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+
+			if err := s.Append("sess", 0, NewSessionCreatedEvent("sess")); err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			if err := s.Append("sess", 1, NewJoinedEvent("Alice"), NewJoinedEvent("Bob")); err != nil {
+				t.Fatalf("append failed: %v", err)
+			}
+
+			events, err := s.ReadFrom("sess", 0)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if len(events) != 3 {
+				t.Fatalf("expected 3 events, got %d", len(events))
+			}
+
+			tail, err := s.ReadFrom("sess", 2)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if len(tail) != 1 {
+				t.Fatalf("expected 1 event after offset 2, got %d", len(tail))
+			}
+			joined, ok := tail[0].(*JoinedEvent)
+			if !ok || joined.Participant != "Bob" {
+				t.Errorf("expected Bob's joined event, got %#v", tail[0])
+			}
+		})
+	}
+}
+
+func TestStoreAppendStaleCount(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			s.Append("sess", 0, NewSessionCreatedEvent("sess"))
+
+			err := s.Append("sess", 0, NewJoinedEvent("Alice"))
+			stale, ok := err.(*errors.StaleStateError)
+			if !ok {
+				t.Fatalf("expected StaleStateError, got %v", err)
+			}
+			if stale.ActualEventNum != 1 {
+				t.Errorf("expected actual event count 1, got %d", stale.ActualEventNum)
+			}
+
+			events, _ := s.ReadFrom("sess", 0)
+			if len(events) != 1 {
+				t.Errorf("stale append should not write, got %d events", len(events))
+			}
+		})
+	}
+}
+
+func TestStoreMissingSession(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+
+			if _, err := s.ReadFrom("missing", 0); err == nil {
+				t.Error("expected error reading missing session")
+			} else if _, ok := err.(*errors.SessionNotFoundError); !ok {
+				t.Errorf("expected SessionNotFoundError, got %v", err)
+			}
+
+			err := s.Append("missing", 3, NewJoinedEvent("Alice"))
+			if _, ok := err.(*errors.SessionNotFoundError); !ok {
+				t.Errorf("expected SessionNotFoundError, got %v", err)
+			}
+
+			exists, err := s.Exists("missing")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exists {
+				t.Error("session should not exist")
+			}
+		})
+	}
+}
+
+func TestStoreListAndExists(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+
+			ids, err := s.List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ids) != 0 {
+				t.Errorf("expected no sessions, got %v", ids)
+			}
+
+			s.Append("beta", 0, NewSessionCreatedEvent("beta"))
+			s.Append("alpha", 0, NewSessionCreatedEvent("alpha"))
+
+			ids, err = s.List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ids) != 2 || ids[0] != "alpha" || ids[1] != "beta" {
+				t.Errorf("expected [alpha beta], got %v", ids)
+			}
+
+			exists, err := s.Exists("alpha")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !exists {
+				t.Error("alpha should exist")
+			}
+		})
+	}
+}
+
+func TestSessionOperationsAgainstStores(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+
+			if err := CreateSession("sess"); err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			eventNum, err := JoinSession("sess", "Alice")
+			if err != nil {
+				t.Fatalf("join failed: %v", err)
+			}
+			if eventNum != 2 {
+				t.Errorf("expected join as event #2, got #%d", eventNum)
+			}
+
+			eventNum, err = PostMessage("sess", "Alice", "hello", "", 2)
+			if err != nil {
+				t.Fatalf("post failed: %v", err)
+			}
+			if eventNum != 3 {
+				t.Errorf("expected post as event #3, got #%d", eventNum)
+			}
+
+			if _, err := PostMessage("sess", "Alice", "stale", "", 2); err == nil {
+				t.Error("expected stale post to fail")
+			}
+
+			if err := LeaveSession("sess", "Alice"); err != nil {
+				t.Fatalf("leave failed: %v", err)
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			if sess.EventCount() != 4 {
+				t.Errorf("expected 4 events, got %d", sess.EventCount())
+			}
+			if sess.IsActiveParticipant("Alice") {
+				t.Error("Alice should have left")
+			}
+		})
+	}
+}
+
+func TestCreateSessionRejectsExisting(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+
+			CreateSession("taken")
+			JoinSession("taken", "Alice")
+			err := CreateSession("taken")
+			if _, ok := err.(*errors.SessionExistsError); !ok {
+				t.Fatalf("expected SessionExistsError, got %v", err)
+			}
+
+			sess, _ := LoadSession("taken")
+			if sess.EventCount() != 2 {
+				t.Errorf("existing session should be untouched, got %d events", sess.EventCount())
+			}
+
+			CreateSession("old")
+			ArchiveSession("old")
+			if _, ok := CreateSession("old").(*errors.SessionExistsError); !ok {
+				t.Error("expected SessionExistsError for an archived session's ID")
+			}
+		})
+	}
+}
+
+func TestConcurrentJoinsAllSucceed(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			CreateSession("sess")
+
+			names := []string{"Alice", "Bob", "Charlie", "Dave", "Eve"}
+			var wg sync.WaitGroup
+			errs := make(chan error, len(names))
+			for _, n := range names {
+				wg.Add(1)
+				go func(n string) {
+					defer wg.Done()
+					if _, err := JoinSession("sess", n); err != nil {
+						errs <- err
+					}
+				}(n)
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Errorf("join failed: %v", err)
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			if len(sess.ActiveParticipants()) != len(names) {
+				t.Errorf("expected %d participants, got %v", len(names), sess.ActiveParticipants())
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// Task is an action item recorded in a session, with its current owner and
+// whether it's done
+type Task struct {
+	EventNum        int    `json:"event_num"`
+	TimestampMillis int64  `json:"timestamp_millis"`
+	Participant     string `json:"participant"` // who added it
+	Description     string `json:"description"`
+	Owner           string `json:"owner,omitempty"`   // empty while unassigned
+	DoneBy          string `json:"done_by,omitempty"` // empty while open
+	DoneAt          int    `json:"done_at,omitempty"` // event number it was marked done at; 0 while open
+}
+
+// Done checks if the task was marked done
+func (t *Task) Done() bool {
+	return t.DoneAt != 0
+}
+
+// Summary describes the task on one line, without its status marker
+func (t *Task) Summary() string {
+	owner := "unassigned"
+	if t.Owner != "" {
+		owner = "owner: " + t.Owner
+	}
+	if t.Done() {
+		return fmt.Sprintf("#%d %s (%s, done by %s at #%d)", t.EventNum, t.Description, owner, t.DoneBy, t.DoneAt)
+	}
+	return fmt.Sprintf("#%d %s (%s)", t.EventNum, t.Description, owner)
+}
+
+// addTask records a task added at event number eventNum
+func (s *State) addTask(eventNum int, e *TaskAddedEvent) {
+	if s.Tasks == nil {
+		s.Tasks = make(map[int]*Task)
+	}
+	s.Tasks[eventNum] = &Task{
+		EventNum:        eventNum,
+		TimestampMillis: e.GetTimestamp(),
+		Participant:     e.Participant,
+		Description:     e.Description,
+		Owner:           e.Owner,
+	}
+}
+
+// SortedTasks returns the session's tasks in the order they were added
+func (s *State) SortedTasks() []*Task {
+	tasks := make([]*Task, 0, len(s.Tasks))
+	for _, t := range s.Tasks {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].EventNum < tasks[j].EventNum })
+	return tasks
+}
+
+// OpenTasks returns the tasks not yet done, in the order they were added
+func (s *State) OpenTasks() []*Task {
+	var open []*Task
+	for _, t := range s.SortedTasks() {
+		if !t.Done() {
+			open = append(open, t)
+		}
+	}
+	return open
+}
+
+// normalizeTaskField trims a task's description or owner, which must fit on
+// one line
+func normalizeTaskField(field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "\r\n") {
+		return "", &errors.InvalidTaskError{Reason: fmt.Sprintf("the %s must be one line", field)}
+	}
+	return value, nil
+}
+
+// AddTask records an action item, optionally with an owner, who doesn't have
+// to be a participant: tasks usually outlive the session. Like posting, it's
+// open to active participants and the Moderator.
+// Returns the new event number (1-indexed for display), which identifies the
+// task
+func AddTask(sessionID, participant, description, owner string) (int, error) {
+	description, err := normalizeTaskField("description", description)
+	if err != nil {
+		return 0, err
+	}
+	if description == "" {
+		return 0, &errors.InvalidTaskError{Reason: "the description is empty"}
+	}
+	if owner, err = normalizeTaskField("owner", owner); err != nil {
+		return 0, err
+	}
+
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+			return nil, &errors.NotAParticipantError{Name: participant, SessionID: sessionID}
+		}
+		return NewTaskAddedEvent(participant, description, owner), nil
+	})
+}
+
+// AssignTask hands the open task at event number task to owner.
+// Returns the new event number (1-indexed for display)
+func AssignTask(sessionID, participant string, task int, owner string) (int, error) {
+	owner, err := normalizeTaskField("owner", owner)
+	if err != nil {
+		return 0, err
+	}
+	if owner == "" {
+		return 0, &errors.InvalidTaskError{Reason: "the owner is empty"}
+	}
+
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if err := validTaskChange(session, participant, task); err != nil {
+			return nil, err
+		}
+		return NewTaskAssignedEvent(participant, task, owner), nil
+	})
+}
+
+// CompleteTask marks the open task at event number task as done.
+// Returns the new event number (1-indexed for display)
+func CompleteTask(sessionID, participant string, task int) (int, error) {
+	return appendWithRetry(sessionID, func(session *Session) (Event, error) {
+		if err := validTaskChange(session, participant, task); err != nil {
+			return nil, err
+		}
+		return NewTaskDoneEvent(participant, task), nil
+	})
+}
+
+// validTaskChange checks that participant may change the task at event
+// number task: it must exist and still be open
+func validTaskChange(session *Session, participant string, task int) error {
+	if participant != "Moderator" && !session.IsActiveParticipant(participant) {
+		return &errors.NotAParticipantError{Name: participant, SessionID: session.ID}
+	}
+	return validOpenTask(session, task)
+}
+
+// validOpenTask checks that the task at event number task exists and is open
+func validOpenTask(session *Session, task int) error {
+	t := session.Tasks[task]
+	if t == nil {
+		return &errors.TaskNotFoundError{SessionID: session.ID, EventNum: task}
+	}
+	if t.Done() {
+		return &errors.TaskDoneError{EventNum: task, DoneAt: t.DoneAt}
+	}
+	return nil
+}
+
+// FormatTasks generates the human-readable task list of a session, open and
+// done tasks alike unless openOnly is set
+func FormatTasks(sess *Session, openOnly bool) string {
+	tasks := sess.SortedTasks()
+	if openOnly {
+		tasks = sess.OpenTasks()
+	}
+	if len(tasks) == 0 {
+		if openOnly {
+			return fmt.Sprintf("No open tasks in session %s.\n", sess.ID)
+		}
+		return fmt.Sprintf("No tasks in session %s. Add one with 'council task add'.\n", sess.ID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== Tasks: %s ===\n", sess.ID)
+	for _, t := range tasks {
+		marker := "[ ]"
+		if t.Done() {
+			marker = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s\n", marker, t.Summary())
+	}
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
+
+// newTaskSession creates a session where Alice added task #4 for the docs
+// team and Bob added unassigned task #5
+func newTaskSession(t *testing.T) {
+	t.Helper()
+	newAliceAndBobSession(t)
+	if num, err := AddTask("sess", "Alice", " Write the migration guide ", "Docs team"); err != nil || num != 4 {
+		t.Fatalf("expected task #4, got #%d: %v", num, err)
+	}
+	AddTask("sess", "Bob", "Benchmark cursor pagination", "")
+}
+
+func TestAddAssignAndCompleteTasks(t *testing.T) {
+	for name, newStore := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			useStore(t, newStore(t))
+			newTaskSession(t)
+
+			if _, err := AssignTask("sess", "Alice", 5, "Bob"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if num, err := CompleteTask("sess", "Moderator", 4); err != nil || num != 7 {
+				t.Fatalf("expected the completion at #7, got #%d: %v", num, err)
+			}
+
+			sess, err := LoadSession("sess")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tasks := sess.SortedTasks()
+			if len(tasks) != 2 {
+				t.Fatalf("expected 2 tasks, got %+v", tasks)
+			}
+			if done := tasks[0]; done.Description != "Write the migration guide" || !done.Done() || done.DoneBy != "Moderator" || done.DoneAt != 7 {
+				t.Errorf("unexpected done task: %+v", done)
+			}
+			open := sess.OpenTasks()
+			if len(open) != 1 || open[0].EventNum != 5 || open[0].Owner != "Bob" {
+				t.Errorf("expected task #5 open and owned by Bob, got %+v", open)
+			}
+		})
+	}
+}
+
+func TestTaskRules(t *testing.T) {
+	useFileStore(t)
+	newTaskSession(t)
+
+	if _, err := AddTask("sess", "Alice", "  ", ""); !isErr[*errors.InvalidTaskError](err) {
+		t.Errorf("expected InvalidTaskError, got %T: %v", err, err)
+	}
+	if _, err := AddTask("sess", "Alice", "two\nlines", ""); !isErr[*errors.InvalidTaskError](err) {
+		t.Errorf("expected InvalidTaskError, got %T: %v", err, err)
+	}
+	if _, err := AssignTask("sess", "Alice", 5, ""); !isErr[*errors.InvalidTaskError](err) {
+		t.Errorf("expected InvalidTaskError, got %T: %v", err, err)
+	}
+	if _, err := AddTask("sess", "Carol", "x", ""); !isErr[*errors.NotAParticipantError](err) {
+		t.Errorf("expected NotAParticipantError, got %T: %v", err, err)
+	}
+	if _, err := CompleteTask("sess", "Alice", 3); !isErr[*errors.TaskNotFoundError](err) {
+		t.Errorf("expected TaskNotFoundError, got %T: %v", err, err)
+	}
+
+	CompleteTask("sess", "Alice", 4)
+	_, err := AssignTask("sess", "Bob", 4, "Bob")
+	if done, ok := err.(*errors.TaskDoneError); !ok || done.DoneAt != 6 {
+		t.Errorf("expected TaskDoneError, got %T: %v", err, err)
+	}
+}
+
+func TestFormatStatusShowsOpenTasks(t *testing.T) {
+	useFileStore(t)
+	newTaskSession(t)
+	AssignTask("sess", "Alice", 5, "Bob")
+	CompleteTask("sess", "Bob", 4)
+
+	// The header comes from state, so it's complete with --after too
+	sess, err := LoadSessionAfter("sess", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := FormatStatus(sess, 7)
+	if !strings.Contains(out, "Open tasks:\n  #5 Benchmark cursor pagination (owner: Bob)\n") {
+		t.Errorf("header should list open tasks:\n%s", out)
+	}
+	if strings.Contains(out, "#4 Write") {
+		t.Errorf("done tasks shouldn't be in the header:\n%s", out)
+	}
+
+	sess, _ = LoadSession("sess")
+	out = FormatStatus(sess, 0)
+	for _, want := range []string{
+		"--- #4 | Alice added task #4: Write the migration guide (owner: Docs team) ---",
+		"--- #5 | Bob added task #5: Benchmark cursor pagination (unassigned) ---",
+		"--- #6 | Alice assigned task #5 to Bob ---",
+		"--- #7 | Bob completed task #4 ---",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatTasks(t *testing.T) {
+	useFileStore(t)
+	newTaskSession(t)
+	CompleteTask("sess", "Bob", 4)
+	sess, _ := LoadSession("sess")
+
+	want := "=== Tasks: sess ===\n" +
+		"[x] #4 Write the migration guide (owner: Docs team, done by Bob at #6)\n" +
+		"[ ] #5 Benchmark cursor pagination (unassigned)\n"
+	if out := FormatTasks(sess, false); out != want {
+		t.Errorf("unexpected task list:\n%s", out)
+	}
+	if out := FormatTasks(sess, true); strings.Contains(out, "#4") || !strings.Contains(out, "[ ] #5") {
+		t.Errorf("expected only open tasks:\n%s", out)
+	}
+
+	CompleteTask("sess", "Bob", 5)
+	sess, _ = LoadSession("sess")
+	if out := FormatTasks(sess, true); out != "No open tasks in session sess.\n" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestExportIncludesTasks(t *testing.T) {
+	useFileStore(t)
+	newTaskSession(t)
+	CompleteTask("sess", "Bob", 4)
+	sess, _ := LoadSession("sess")
+
+	var tr Transcript
+	if err := json.Unmarshal([]byte(export(t, sess, ExportJSON)), &tr); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(tr.Tasks) != 2 || tr.Tasks[0].DoneAt != 6 || tr.Tasks[1].Owner != "" {
+		t.Errorf("unexpected tasks: %+v", tr.Tasks)
+	}
+
+	md := export(t, sess, ExportMarkdown)
+	for _, want := range []string{
+		"## Tasks\n\n- [x] #4 Write the migration guide (owner: Docs team, done by Bob at #6)\n- [ ] #5 Benchmark",
+		"Bob completed task #4",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown should contain %q:\n%s", want, md)
+		}
+	}
+	if html := export(t, sess, ExportHTML); !strings.Contains(html, `<input type="checkbox" disabled checked> #4 Write the migration guide`) {
+		t.Errorf("HTML should list tasks with their status:\n%s", html)
+	}
+}
+
+func TestCheckSessionTasks(t *testing.T) {
+	useFileStore(t)
+	writeLog(t, "sess", validLog+
+		`{"type":"task_added","timestamp_millis":4,"participant":"Alice","description":"Write docs"}
+{"type":"task_done","timestamp_millis":5,"participant":"Alice","task":4}
+{"type":"task_assigned","timestamp_millis":6,"participant":"Alice","task":4,"owner":"Bob"}
+{"type":"task_done","timestamp_millis":7,"participant":"Alice","task":2}
+`)
+
+	report, err := CheckSession("sess")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %+v", report.Problems)
+	}
+	if report.Problems[0].Line != 6 || !strings.Contains(report.Problems[0].Message, "already marked done") {
+		t.Errorf("unexpected problem: %+v", report.Problems[0])
+	}
+	if report.Problems[1].Line != 7 || !strings.Contains(report.Problems[1].Message, "not a task") {
+		t.Errorf("unexpected problem: %+v", report.Problems[1])
+	}
+}
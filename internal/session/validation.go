@@ -1,5 +1,11 @@
 package session
 
+import (
+	"regexp"
+
+	"github.com/amterp/council/internal/errors"
+)
+
 // ReservedNames contains names that cannot be used by participants
 var ReservedNames = map[string]bool{
 	"Moderator": true,
@@ -9,3 +15,14 @@ var ReservedNames = map[string]bool{
 func IsReservedName(name string) bool {
 	return ReservedNames[name]
 }
+
+// sessionIDPattern restricts IDs to characters safe in paths, URLs and shells
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateSessionID checks a user-provided session ID
+func ValidateSessionID(id string) error {
+	if !sessionIDPattern.MatchString(id) {
+		return &errors.InvalidSessionIDError{SessionID: id}
+	}
+	return nil
+}
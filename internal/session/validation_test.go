@@ -1,6 +1,11 @@
 package session
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/amterp/council/internal/errors"
+)
 
 func TestIsReservedName(t *testing.T) {
 	tests := []struct {
@@ -25,3 +30,36 @@ func TestIsReservedName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSessionID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"my-design-review", true},
+		{"ci_run_42", true},
+		{"A", true},
+		{"", false},
+		{"-leading-dash", false},
+		{"has space", false},
+		{"../escape", false},
+		{"a/b", false},
+		{"dotted.name", false},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			err := ValidateSessionID(tt.id)
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.id, err)
+			}
+			if !tt.valid {
+				if _, ok := err.(*errors.InvalidSessionIDError); !ok {
+					t.Errorf("expected InvalidSessionIDError for %q, got %v", tt.id, err)
+				}
+			}
+		})
+	}
+}
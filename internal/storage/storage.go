@@ -6,17 +6,75 @@ import (
 )
 
 const (
-	CouncilDir  = ".council"
-	SessionsDir = "sessions"
+	CouncilDir   = ".council"
+	SessionsDir  = "sessions"
+	ArchiveDir   = "archive"
+	ConfigFile   = "config.json"
+	DatabaseFile = "council.db"
+	KeyFile      = "key"
+	EventsFile   = "events.jsonl"
+	IndexFile    = "index.json"
+
+	// ArtifactsDir holds a session's artifacts inside its directory, one
+	// file per artifact named by its content hash
+	ArtifactsDir = "artifacts"
+
+	// SnapshotPrefix starts the name of each snapshot file in a session
+	// directory, followed by the event number and ".json"
+	SnapshotPrefix = "snapshot-"
+
+	// LockInfoSuffix is appended to a locked file's name for the sidecar
+	// describing the lock's holder
+	LockInfoSuffix = ".lock-info"
+
+	// LockFileSuffix is appended to a locked file's name for the lockfile
+	// used by lockfile-based locking
+	LockFileSuffix = ".lock"
 )
 
+// CouncilPath returns the path to the council directory (~/.council)
+func CouncilPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, CouncilDir), nil
+}
+
+// ConfigPath returns the path to the user config file (~/.council/config.json)
+func ConfigPath() (string, error) {
+	councilDir, err := CouncilPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(councilDir, ConfigFile), nil
+}
+
+// DatabasePath returns the path to the SQLite database (~/.council/council.db)
+func DatabasePath() (string, error) {
+	councilDir, err := CouncilPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(councilDir, DatabaseFile), nil
+}
+
+// KeyPath returns the default path to the encryption key file (~/.council/key)
+func KeyPath() (string, error) {
+	councilDir, err := CouncilPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(councilDir, KeyFile), nil
+}
+
 // SessionsPath returns the path to the sessions directory (~/.council/sessions)
 func SessionsPath() (string, error) {
-	home, err := os.UserHomeDir()
+	councilDir, err := CouncilPath()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, CouncilDir, SessionsDir), nil
+	return filepath.Join(councilDir, SessionsDir), nil
 }
 
 // SessionDirPath returns the path to a specific session directory
@@ -34,7 +92,34 @@ func SessionEventsPath(sessionID string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(sessionDir, "events.jsonl"), nil
+	return filepath.Join(sessionDir, EventsFile), nil
+}
+
+// ArchivePath returns the path to the archived sessions directory (~/.council/archive)
+func ArchivePath() (string, error) {
+	councilDir, err := CouncilPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(councilDir, ArchiveDir), nil
+}
+
+// ArchivedSessionDirPath returns the path to an archived session's directory
+func ArchivedSessionDirPath(sessionID string) (string, error) {
+	archiveDir, err := ArchivePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(archiveDir, sessionID), nil
+}
+
+// ArchivedSessionExists checks if an archived session's events file exists
+func ArchivedSessionExists(sessionID string) (bool, error) {
+	dir, err := ArchivedSessionDirPath(sessionID)
+	if err != nil {
+		return false, err
+	}
+	return fileExists(filepath.Join(dir, EventsFile))
 }
 
 // EnsureSessionDir creates a session's directory if it doesn't exist
@@ -61,7 +146,12 @@ func SessionExists(sessionID string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	_, err = os.Stat(path)
+	return fileExists(path)
+}
+
+// fileExists checks if a file exists, treating only "not exist" as false
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		return false, nil
 	}
@@ -1,15 +1,18 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"mime"
 	"net"
 	"net/http"
 	"os/exec"
 	"runtime"
 	"sort"
 	"strconv"
+	"sync"
 
 	"github.com/amterp/council/internal/errors"
 	"github.com/amterp/council/internal/session"
@@ -20,6 +23,11 @@ type Server struct {
 	sessionID string
 	port      int
 	mux       *http.ServeMux
+
+	// chains caches the latest hash chain report of each session, so status
+	// polls only verify the events appended since the last one
+	chainsMu sync.Mutex
+	chains   map[string]*session.ChainReport
 }
 
 // NewServer creates a new web server for the given session
@@ -28,6 +36,7 @@ func NewServer(sessionID string, port int) *Server {
 		sessionID: sessionID,
 		port:      port,
 		mux:       http.NewServeMux(),
+		chains:    map[string]*session.ChainReport{},
 	}
 	s.setupRoutes()
 	return s
@@ -37,6 +46,9 @@ func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("/api/status", s.handleStatus)
 	s.mux.HandleFunc("/api/post", s.handlePost)
 	s.mux.HandleFunc("/api/participants", s.handleParticipants)
+	s.mux.HandleFunc("/api/export", s.handleExport)
+	s.mux.HandleFunc("/api/artifact", s.handleArtifact)
+	s.mux.HandleFunc("/api/facts", s.handleFacts)
 
 	// Serve embedded frontend with SPA fallback
 	distFS, err := fs.Sub(WebAssets, "dist")
@@ -106,7 +118,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	sess, err := session.LoadSession(sessionID)
+	sess, err := session.LoadSessionAfter(sessionID, afterN)
 	if err != nil {
 		if _, ok := err.(*errors.SessionNotFoundError); ok {
 			writeJSONError(w, "session not found", http.StatusNotFound)
@@ -116,14 +128,34 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert events to API format, filtering by after parameter
+	// Convert events to API format, filtering by after parameter. Messages
+	// are sent as revised, and retracted content is never sent.
+	revisions := sess.Revisions()
 	apiEvents := make([]APIEvent, 0)
 	for i, event := range sess.Events {
-		eventNum := i + 1 // 1-indexed
+		eventNum := sess.Offset + i + 1 // 1-indexed
 		if eventNum <= afterN {
 			continue
 		}
-		apiEvents = append(apiEvents, convertToAPIEvent(event, eventNum))
+		api := convertToAPIEvent(event, eventNum)
+		if poll := sess.Polls[api.Poll]; poll != nil && poll.Secret {
+			api.Choice = ""
+		}
+		if _, ok := event.(*session.MessageEvent); ok {
+			if r := revisions[eventNum]; r.Retracted() {
+				api.Content = ""
+				api.Mentions = nil
+				api.Retracted = true
+			} else if r != nil {
+				api.Content = r.Content
+				api.Mentions = r.Mentions
+				api.Edited = true
+			}
+		} else if revisions[api.Message].Retracted() {
+			api.Content = ""
+			api.Mentions = nil
+		}
+		apiEvents = append(apiEvents, api)
 	}
 
 	participants := sess.ActiveParticipants()
@@ -133,7 +165,31 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		SessionID:    sessionID,
 		Participants: participants,
 		EventCount:   sess.EventCount(),
-		Events:       apiEvents,
+		Metadata: Metadata{
+			Title:       sess.Metadata.Title,
+			Goal:        sess.Metadata.Goal,
+			Description: sess.Metadata.Description,
+			Tags:        sess.Metadata.Tags,
+		},
+		Polls:  convertPolls(sess),
+		Facts:  convertFacts(sess),
+		Tasks:  convertTasks(sess),
+		Events: apiEvents,
+	}
+	if resp.Metadata.Tags == nil {
+		resp.Metadata.Tags = []string{}
+	}
+	if verification, err := s.verifySession(sessionID); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if verification != nil {
+		resp.Verification = verification
+	}
+	if sess.ForkedFrom != nil {
+		resp.ForkedFrom = &ForkOrigin{
+			SessionID: sess.ForkedFrom.SessionID,
+			EventNum:  sess.ForkedFrom.EventNum,
+		}
 	}
 
 	writeJSON(w, resp)
@@ -168,14 +224,16 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
 		next = *req.Next
 	}
 
-	eventNum, err := session.PostMessage(req.Session, "Moderator", req.Content, next, req.After)
+	eventNum, err := session.PostReply(req.Session, "Moderator", req.Content, next, req.ReplyTo, req.After)
 	if err != nil {
 		switch err.(type) {
 		case *errors.SessionNotFoundError:
 			writeJSONError(w, "session not found", http.StatusNotFound)
 		case *errors.StaleStateError:
 			writeJSONError(w, err.Error(), http.StatusConflict)
-		case *errors.InvalidNextParticipantError:
+		case *errors.SessionArchivedError:
+			writeJSONError(w, err.Error(), http.StatusForbidden)
+		case *errors.InvalidNextParticipantError, *errors.MessageNotFoundError:
 			writeJSONError(w, err.Error(), http.StatusBadRequest)
 		default:
 			writeJSONError(w, err.Error(), http.StatusInternalServerError)
@@ -215,6 +273,131 @@ func (s *Server) handleParticipants(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, ParticipantsResponse{Participants: participants})
 }
 
+// exportContentTypes maps export formats to the content types they're served as
+var exportContentTypes = map[string]string{
+	session.ExportMarkdown: "text/markdown; charset=utf-8",
+	session.ExportHTML:     "text/html; charset=utf-8",
+	session.ExportJSON:     "application/json",
+	session.ExportCSV:      "text/csv; charset=utf-8",
+}
+
+// handleExport implements GET /api/export, serving a transcript as a download
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session")
+	if sessionID == "" {
+		writeJSONError(w, "session parameter required", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = session.ExportMarkdown
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		writeJSONError(w, "invalid format parameter", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := session.LoadHistory(sessionID)
+	if err != nil {
+		if _, ok := err.(*errors.SessionNotFoundError); ok {
+			writeJSONError(w, "session not found", http.StatusNotFound)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := session.Export(&buf, sess, format); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, sessionID, format))
+	w.Write(buf.Bytes())
+}
+
+// verifySession checks a session's hash chain, returning nil for sessions
+// created without one. Only links added since the last check are verified.
+func (s *Server) verifySession(sessionID string) (*Verification, error) {
+	s.chainsMu.Lock()
+	defer s.chainsMu.Unlock()
+
+	report, err := session.VerifyChainSince(sessionID, s.chains[sessionID])
+	if err != nil {
+		return nil, err
+	}
+	s.chains[sessionID] = report
+	if !report.Enabled && report.BrokenLine == 0 {
+		return nil, nil
+	}
+	return &Verification{
+		Intact:     report.Intact(),
+		Events:     report.Events,
+		BrokenLine: report.BrokenLine,
+		Reason:     report.Reason,
+		Head:       report.Head,
+	}, nil
+}
+
+// handleArtifact implements GET /api/artifact, downloading an artifact by
+// name (its latest version) or hash
+func (s *Server) handleArtifact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session")
+	if sessionID == "" {
+		writeJSONError(w, "session parameter required", http.StatusBadRequest)
+		return
+	}
+	ref := r.URL.Query().Get("hash")
+	if ref == "" {
+		ref = r.URL.Query().Get("name")
+	}
+	if ref == "" {
+		writeJSONError(w, "name or hash parameter required", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := session.LoadHistory(sessionID)
+	if err != nil {
+		if _, ok := err.(*errors.SessionNotFoundError); ok {
+			writeJSONError(w, "session not found", http.StatusNotFound)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	artifact, data, err := session.GetArtifact(sess, ref)
+	if err != nil {
+		if _, ok := err.(*errors.ArtifactNotFoundError); ok {
+			writeJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Artifacts are whatever participants shared, so they're always
+	// downloaded rather than rendered by the browser
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+	w.Write(data)
+}
+
 // convertToAPIEvent converts an internal Event to an APIEvent
 func convertToAPIEvent(event session.Event, number int) APIEvent {
 	api := APIEvent{
@@ -234,11 +417,163 @@ func convertToAPIEvent(event session.Event, number int) APIEvent {
 		api.Participant = e.Participant
 		api.Content = e.Content
 		api.Next = e.Next
+		api.ReplyTo = e.ReplyTo
+		api.Mentions = e.Mentions
+	case *session.ForkedFromEvent:
+		api.ForkSession = e.ParentSession
+		api.ForkEvent = e.ParentEvent
+	case *session.ForkedEvent:
+		api.ForkSession = e.ChildSession
+		api.ForkEvent = e.AtEvent
+	case *session.PollEvent:
+		api.Participant = e.Participant
+		api.Question = e.Question
+		api.Options = e.Options
+	case *session.VoteEvent:
+		api.Participant = e.Participant
+		api.Poll = e.Poll
+		api.Choice = e.Choice
+	case *session.MessageEditedEvent:
+		api.Participant = e.Participant
+		api.Message = e.Message
+		api.Content = e.Content
+		api.Mentions = e.Mentions
+	case *session.MessageRetractedEvent:
+		api.Participant = e.Participant
+		api.Message = e.Message
+	case *session.ArtifactAddedEvent:
+		api.Participant = e.Participant
+		api.Name = e.Name
+		api.Size = e.Size
+		api.Hash = e.Hash
+	case *session.FactSetEvent:
+		api.Participant = e.Participant
+		api.Key = e.Key
+		api.Value = e.Value
+		api.FactVersion = e.FactVersion
+	case *session.FactDeletedEvent:
+		api.Participant = e.Participant
+		api.Key = e.Key
+		api.FactVersion = e.FactVersion
+	case *session.TaskAddedEvent:
+		api.Participant = e.Participant
+		api.Description = e.Description
+		api.Owner = e.Owner
+	case *session.TaskAssignedEvent:
+		api.Participant = e.Participant
+		api.Task = e.Task
+		api.Owner = e.Owner
+	case *session.TaskDoneEvent:
+		api.Participant = e.Participant
+		api.Task = e.Task
+	case *session.DecisionEvent:
+		api.Participant = e.Participant
+		api.Title = e.Title
+		api.Rationale = e.Rationale
+		api.Alternatives = e.Alternatives
+		api.Supersedes = e.Supersedes
+	case *session.RawEvent:
+		api.Data = e.Fields()
 	}
 
 	return api
 }
 
+// handleFacts implements GET /api/facts, serving the facts that are set with
+// their versions. With a key parameter, only that fact is served.
+func (s *Server) handleFacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session")
+	if sessionID == "" {
+		writeJSONError(w, "session parameter required", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := session.LoadSession(sessionID)
+	if err != nil {
+		if _, ok := err.(*errors.SessionNotFoundError); ok {
+			writeJSONError(w, "session not found", http.StatusNotFound)
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	facts := convertFacts(sess)
+	if key := r.URL.Query().Get("key"); key != "" {
+		fact, err := session.GetFact(sess, key)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		facts = []Fact{convertFact(fact)}
+	}
+
+	writeJSON(w, FactsResponse{SessionID: sessionID, EventCount: sess.EventCount(), Facts: facts})
+}
+
+// convertFacts converts a session's facts that are set to API format
+func convertFacts(sess *session.Session) []Fact {
+	facts := make([]Fact, 0)
+	for _, f := range sess.SortedFacts() {
+		facts = append(facts, convertFact(f))
+	}
+	return facts
+}
+
+// convertFact converts one fact to API format
+func convertFact(f *session.Fact) Fact {
+	return Fact{
+		Key:         f.Key,
+		Value:       f.Value,
+		Version:     f.Version,
+		Participant: f.Participant,
+		EventNum:    f.EventNum,
+	}
+}
+
+// convertTasks converts a session's tasks to API format
+func convertTasks(sess *session.Session) []Task {
+	tasks := make([]Task, 0)
+	for _, t := range sess.SortedTasks() {
+		tasks = append(tasks, Task{
+			EventNum:    t.EventNum,
+			Participant: t.Participant,
+			Description: t.Description,
+			Owner:       t.Owner,
+			Done:        t.Done(),
+			DoneBy:      t.DoneBy,
+			DoneAt:      t.DoneAt,
+		})
+	}
+	return tasks
+}
+
+// convertPolls converts a session's polls to API format with their tallies
+func convertPolls(sess *session.Session) []Poll {
+	polls := []Poll{}
+	for _, p := range sess.SortedPolls() {
+		poll := Poll{
+			EventNum:    p.EventNum,
+			Participant: p.Participant,
+			Question:    p.Question,
+			Secret:      p.Secret,
+			ClosesAfter: p.ClosesAfter,
+			Closed:      p.ClosedAt(sess.EventCount()),
+			Results:     []PollResult{},
+		}
+		for _, r := range p.Tally() {
+			poll.Results = append(poll.Results, PollResult{Choice: r.Choice, Count: r.Count, Voters: r.Voters})
+		}
+		polls = append(polls, poll)
+	}
+	return polls
+}
+
 func writeJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
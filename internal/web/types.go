@@ -1,23 +1,137 @@
 package web
 
+import "encoding/json"
+
 // APIEvent represents a single event in the API response.
 // This is a flattened structure for JSON serialization.
 type APIEvent struct {
-	Number          int    `json:"number"`
-	Type            string `json:"type"`
-	TimestampMillis int64  `json:"timestamp_millis"`
-	Participant     string `json:"participant,omitempty"`
-	Content         string `json:"content,omitempty"`
-	Next            string `json:"next,omitempty"`
-	ID              string `json:"id,omitempty"`
+	Number          int      `json:"number"`
+	Type            string   `json:"type"`
+	TimestampMillis int64    `json:"timestamp_millis"`
+	Participant     string   `json:"participant,omitempty"`
+	Content         string   `json:"content,omitempty"`
+	Next            string   `json:"next,omitempty"`
+	ReplyTo         int      `json:"reply_to,omitempty"` // event number of the message replied to
+	Mentions        []string `json:"mentions,omitempty"`
+	Message         int      `json:"message,omitempty"` // event number of the message edited or retracted
+	Edited          bool     `json:"edited,omitempty"`
+	Retracted       bool     `json:"retracted,omitempty"` // content is left out
+	ID              string   `json:"id,omitempty"`
+	ForkSession     string   `json:"fork_session,omitempty"` // parent for forked_from, child for forked
+	ForkEvent       int      `json:"fork_event,omitempty"`
+
+	// Poll and vote fields. Choice is omitted for votes in secret polls.
+	Question string   `json:"question,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Poll     int      `json:"poll,omitempty"`
+	Choice   string   `json:"choice,omitempty"`
+
+	// Artifact fields. The content is downloaded from /api/artifact.
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	Hash string `json:"hash,omitempty"`
+
+	// Fact fields. Value is empty for deletions.
+	Key         string `json:"key,omitempty"`
+	Value       string `json:"value,omitempty"`
+	FactVersion int    `json:"fact_version,omitempty"`
+
+	// Task fields. Task is the event number of the task assigned or done.
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Task        int    `json:"task,omitempty"`
+
+	// Decision fields
+	Title        string   `json:"title,omitempty"`
+	Rationale    string   `json:"rationale,omitempty"`
+	Alternatives []string `json:"alternatives,omitempty"`
+	Supersedes   int      `json:"supersedes,omitempty"`
+
+	// Data holds the fields of events of a type this version doesn't know
+	Data map[string]json.RawMessage `json:"data,omitempty"`
+}
+
+// ForkOrigin identifies the session and event a forked session branched from
+type ForkOrigin struct {
+	SessionID string `json:"session_id"`
+	EventNum  int    `json:"event_num"`
+}
+
+// Metadata describes what a session is about
+type Metadata struct {
+	Title       string   `json:"title"`
+	Goal        string   `json:"goal"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// Verification reports the hash chain of a session created with one
+type Verification struct {
+	Intact     bool   `json:"intact"`
+	Events     int    `json:"events"`                // events verified before any broken link
+	BrokenLine int    `json:"broken_line,omitempty"` // first line that fails verification
+	Reason     string `json:"reason,omitempty"`
+	Head       string `json:"head,omitempty"` // hash of the last verified line
+}
+
+// Poll is a poll with its live results
+type Poll struct {
+	EventNum    int          `json:"event_num"`
+	Participant string       `json:"participant"`
+	Question    string       `json:"question"`
+	Secret      bool         `json:"secret"`
+	ClosesAfter int          `json:"closes_after,omitempty"`
+	Closed      bool         `json:"closed"`
+	Results     []PollResult `json:"results"`
+}
+
+// PollResult is the tally for one option of a poll. Voters is omitted for
+// secret polls.
+type PollResult struct {
+	Choice string   `json:"choice"`
+	Count  int      `json:"count"`
+	Voters []string `json:"voters,omitempty"`
+}
+
+// Fact is a fact that's set, with the version a compare-and-set expects
+type Fact struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Version     int    `json:"version"`
+	Participant string `json:"participant"` // who made the latest change
+	EventNum    int    `json:"event_num"`
+}
+
+// Task is an action item with its current owner and status
+type Task struct {
+	EventNum    int    `json:"event_num"`
+	Participant string `json:"participant"` // who added it
+	Description string `json:"description"`
+	Owner       string `json:"owner,omitempty"`
+	Done        bool   `json:"done"`
+	DoneBy      string `json:"done_by,omitempty"`
+	DoneAt      int    `json:"done_at,omitempty"`
+}
+
+// FactsResponse is the response for GET /api/facts
+type FactsResponse struct {
+	SessionID  string `json:"session_id"`
+	EventCount int    `json:"event_count"`
+	Facts      []Fact `json:"facts"`
 }
 
 // StatusResponse is the response for GET /api/status
 type StatusResponse struct {
-	SessionID    string     `json:"session_id"`
-	Participants []string   `json:"participants"`
-	EventCount   int        `json:"event_count"`
-	Events       []APIEvent `json:"events"`
+	SessionID    string        `json:"session_id"`
+	Participants []string      `json:"participants"`
+	EventCount   int           `json:"event_count"`
+	Metadata     Metadata      `json:"metadata"`
+	ForkedFrom   *ForkOrigin   `json:"forked_from,omitempty"`
+	Verification *Verification `json:"verification,omitempty"`
+	Polls        []Poll        `json:"polls"`
+	Facts        []Fact        `json:"facts"`
+	Tasks        []Task        `json:"tasks"`
+	Events       []APIEvent    `json:"events"`
 }
 
 // PostRequest is the request body for POST /api/post
@@ -26,6 +140,7 @@ type PostRequest struct {
 	Content string  `json:"content"`
 	After   int     `json:"after"`
 	Next    *string `json:"next,omitempty"`
+	ReplyTo int     `json:"reply_to,omitempty"`
 }
 
 // PostResponse is the response for POST /api/post
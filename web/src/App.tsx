@@ -6,7 +6,7 @@ import { ComposeBox } from './components/ComposeBox';
 
 function App() {
   const sessionId = new URLSearchParams(window.location.search).get('session') || '';
-  const { events, participants, eventCount, loading, error, refetch } = useSession(sessionId);
+  const { events, participants, forkedFrom, metadata, verification, polls, facts, tasks, eventCount, loading, error, refetch } = useSession(sessionId);
   const { theme, setTheme } = useTheme();
 
   if (!sessionId) {
@@ -46,10 +46,16 @@ function App() {
       <Header
         sessionId={sessionId}
         participants={participants}
+        forkedFrom={forkedFrom}
+        metadata={metadata}
+        verification={verification}
+        polls={polls}
+        facts={facts}
+        tasks={tasks}
         theme={theme}
         onThemeChange={setTheme}
       />
-      <MessageList events={events} />
+      <MessageList sessionId={sessionId} events={events} />
       <ComposeBox
         sessionId={sessionId}
         participants={participants}
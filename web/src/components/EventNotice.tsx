@@ -1,13 +1,15 @@
 import type { APIEvent } from '../types';
-import { formatTimestamp } from '../utils/time';
+import { formatSize, formatTimestamp } from '../utils/time';
 
 interface EventNoticeProps {
+  sessionId: string;
   event: APIEvent;
 }
 
-export function EventNotice({ event }: EventNoticeProps) {
+export function EventNotice({ sessionId, event }: EventNoticeProps) {
   let text = '';
   let icon = '';
+  let download = '';
 
   switch (event.type) {
     case 'session_created':
@@ -22,8 +24,76 @@ export function EventNotice({ event }: EventNoticeProps) {
       text = `${event.participant} left`;
       icon = '←';
       break;
+    case 'session_updated':
+      text = `Session details updated`;
+      icon = '✎';
+      break;
+    case 'forked_from':
+      text = `Forked from ${event.fork_session} at #${event.fork_event}`;
+      icon = '⑂';
+      break;
+    case 'forked':
+      text = `Forked to ${event.fork_session} at #${event.fork_event}`;
+      icon = '⑂';
+      break;
+    case 'poll':
+      text = `${event.participant} opened a poll: ${event.question} (${event.options?.join(' / ')})`;
+      icon = '☑';
+      break;
+    case 'vote':
+      text = event.choice
+        ? `${event.participant} voted ${event.choice} in poll #${event.poll}`
+        : `${event.participant} voted in poll #${event.poll}`;
+      icon = '☑';
+      break;
+    case 'message_edited':
+      text = `${event.participant} edited #${event.message}`;
+      icon = '✎';
+      break;
+    case 'message_retracted':
+      text = `${event.participant} retracted #${event.message}`;
+      icon = '⌫';
+      break;
+    case 'artifact_added':
+      text = `${event.participant} added artifact ${event.name} (${formatSize(event.size ?? 0)})`;
+      icon = '📎';
+      download = `/api/artifact?session=${encodeURIComponent(sessionId)}&hash=${encodeURIComponent(event.hash ?? '')}`;
+      break;
+    case 'fact_set':
+      text = `${event.participant} set fact ${event.key} = ${event.value} (v${event.fact_version})`;
+      icon = '📌';
+      break;
+    case 'fact_deleted':
+      text = `${event.participant} deleted fact ${event.key} (v${event.fact_version})`;
+      icon = '📌';
+      break;
+    case 'task_added':
+      text = `${event.participant} added task #${event.number}: ${event.description}`;
+      if (event.owner) {
+        text += ` (owner: ${event.owner})`;
+      }
+      icon = '☐';
+      break;
+    case 'task_assigned':
+      text = `${event.participant} assigned task #${event.task} to ${event.owner}`;
+      icon = '☐';
+      break;
+    case 'task_done':
+      text = `${event.participant} completed task #${event.task}`;
+      icon = '☑';
+      break;
+    case 'decision':
+      text = `${event.participant} decided: ${event.title}`;
+      if (event.supersedes) {
+        text += ` (supersedes #${event.supersedes})`;
+      }
+      icon = '⚖';
+      break;
     default:
-      return null;
+      // An event type from a newer version of council
+      text = event.data ? `${event.type}: ${JSON.stringify(event.data)}` : event.type;
+      icon = '?';
+      break;
   }
 
   return (
@@ -33,6 +103,11 @@ export function EventNotice({ event }: EventNoticeProps) {
         <span className="text-gray-400 dark:text-gray-500">#{event.number}</span>
         <span>{icon}</span>
         <span>{text}</span>
+        {download && (
+          <a href={download} download={event.name} className="text-blue-600 hover:underline dark:text-blue-400">
+            Download
+          </a>
+        )}
       </div>
     </div>
   );
@@ -1,25 +1,65 @@
 import type { Theme } from '../hooks/useTheme';
+import type { Fact, ForkOrigin, Metadata, Poll, Task, Verification } from '../types';
 
 interface HeaderProps {
   sessionId: string;
   participants: string[];
+  forkedFrom: ForkOrigin | null;
+  metadata: Metadata | null;
+  verification: Verification | null;
+  polls: Poll[];
+  facts: Fact[];
+  tasks: Task[];
   theme: Theme;
   onThemeChange: (theme: Theme) => void;
 }
 
-export function Header({ sessionId, participants, theme, onThemeChange }: HeaderProps) {
+export function Header({ sessionId, participants, forkedFrom, metadata, verification, polls, facts, tasks, theme, onThemeChange }: HeaderProps) {
   return (
     <div className="border-b border-gray-200 bg-white px-4 py-3 dark:border-gray-700 dark:bg-gray-900">
       <div className="flex items-center justify-between">
         <div>
           <h1 className="text-lg font-semibold text-gray-900 dark:text-gray-100">
-            Council: {sessionId}
+            Council: {metadata?.title || sessionId}
           </h1>
+          {metadata?.title && (
+            <p className="text-xs text-gray-500 dark:text-gray-500">{sessionId}</p>
+          )}
+          {metadata?.goal && (
+            <p className="text-sm text-gray-600 dark:text-gray-400">Goal: {metadata.goal}</p>
+          )}
+          {metadata?.description && (
+            <p className="text-sm text-gray-600 dark:text-gray-400">{metadata.description}</p>
+          )}
+          {metadata && metadata.tags.length > 0 && (
+            <div className="mt-1 flex flex-wrap gap-1">
+              {metadata.tags.map((tag) => (
+                <span
+                  key={tag}
+                  className="rounded-full bg-gray-100 px-2 py-0.5 text-xs text-gray-600 dark:bg-gray-800 dark:text-gray-400"
+                >
+                  {tag}
+                </span>
+              ))}
+            </div>
+          )}
+          {verification && <VerificationBadge verification={verification} />}
+          {forkedFrom && (
+            <p className="text-sm text-gray-600 dark:text-gray-400">
+              Forked from {forkedFrom.session_id} at #{forkedFrom.event_num}
+            </p>
+          )}
           <p className="text-sm text-gray-600 dark:text-gray-400">
             Participants: {participants.length > 0 ? participants.join(', ') : 'None yet'}
           </p>
+          {facts.length > 0 && <FactList facts={facts} />}
+          <OpenTasks tasks={tasks} />
+          {polls.map((poll) => (
+            <PollTally key={poll.event_num} poll={poll} />
+          ))}
         </div>
         <div className="flex items-center gap-1">
+          <ExportLinks sessionId={sessionId} />
           <ThemeButton
             icon="☀️"
             label="Light"
@@ -44,6 +84,121 @@ export function Header({ sessionId, participants, theme, onThemeChange }: Header
   );
 }
 
+function VerificationBadge({ verification }: { verification: Verification }) {
+  if (verification.intact) {
+    return (
+      <span
+        title={`Head ${verification.head}`}
+        className="mt-1 inline-block rounded-full bg-green-100 px-2 py-0.5 text-xs text-green-800 dark:bg-green-900 dark:text-green-200"
+      >
+        ✓ Chain verified ({verification.events} events)
+      </span>
+    );
+  }
+  return (
+    <span
+      title={verification.reason}
+      className="mt-1 inline-block rounded-full bg-red-100 px-2 py-0.5 text-xs text-red-800 dark:bg-red-900 dark:text-red-200"
+    >
+      ✗ Chain broken at line {verification.broken_line}
+    </span>
+  );
+}
+
+function FactList({ facts }: { facts: Fact[] }) {
+  return (
+    <div className="mt-1 text-sm text-gray-600 dark:text-gray-400">
+      <span className="font-medium">Facts</span>
+      <dl className="grid grid-cols-[auto_1fr] gap-x-2 text-xs">
+        {facts.map((fact) => (
+          <div key={fact.key} className="contents" title={`v${fact.version}, ${fact.participant} at #${fact.event_num}`}>
+            <dt className="font-mono">{fact.key}</dt>
+            <dd>{fact.value}</dd>
+          </div>
+        ))}
+      </dl>
+    </div>
+  );
+}
+
+function OpenTasks({ tasks }: { tasks: Task[] }) {
+  const open = tasks.filter((task) => !task.done);
+  if (open.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="mt-1 text-sm text-gray-600 dark:text-gray-400">
+      <span className="font-medium">Open tasks</span>
+      <ul className="text-xs">
+        {open.map((task) => (
+          <li key={task.event_num}>
+            #{task.event_num} {task.description}{' '}
+            <span className="text-gray-500">({task.owner ? `owner: ${task.owner}` : 'unassigned'})</span>
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+}
+
+function PollTally({ poll }: { poll: Poll }) {
+  let state = 'open';
+  if (poll.closed) {
+    state = `closed after #${poll.closes_after}`;
+  } else if (poll.closes_after) {
+    state = `open until #${poll.closes_after}`;
+  }
+  if (poll.secret) {
+    state += ', secret';
+  }
+
+  return (
+    <div className="mt-1 text-sm text-gray-600 dark:text-gray-400">
+      <span className="font-medium">
+        #{poll.event_num} {poll.question}
+      </span>{' '}
+      <span className="text-xs text-gray-500">[{state}]</span>
+      <div className="flex flex-wrap gap-2 text-xs">
+        {poll.results.map((result) => (
+          <span
+            key={result.choice}
+            title={result.voters?.join(', ')}
+            className="rounded-full bg-gray-100 px-2 py-0.5 dark:bg-gray-800"
+          >
+            {result.choice}: {result.count}
+          </span>
+        ))}
+      </div>
+    </div>
+  );
+}
+
+const EXPORT_FORMATS = [
+  { format: 'md', label: 'Markdown' },
+  { format: 'html', label: 'HTML' },
+  { format: 'json', label: 'JSON' },
+  { format: 'csv', label: 'CSV' },
+];
+
+function ExportLinks({ sessionId }: { sessionId: string }) {
+  return (
+    <div className="mr-2 flex items-center gap-1 text-xs text-gray-500 dark:text-gray-400">
+      <span>Export:</span>
+      {EXPORT_FORMATS.map(({ format, label }) => (
+        <a
+          key={format}
+          href={`/api/export?session=${encodeURIComponent(sessionId)}&format=${format}`}
+          download={`${sessionId}.${format}`}
+          className="rounded px-1.5 py-0.5 hover:bg-gray-100 dark:hover:bg-gray-800"
+        >
+          {label}
+        </a>
+      ))}
+    </div>
+  );
+}
+
 interface ThemeButtonProps {
   icon: string;
   label: string;
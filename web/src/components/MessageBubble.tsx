@@ -11,7 +11,7 @@ export function MessageBubble({ event }: MessageBubbleProps) {
 
   return (
     <div
-      className={`mb-4 rounded-lg p-4 ${
+      className={`mb-4 rounded-lg p-4 ${event.reply_to ? 'ml-8 ' : ''}${
         isModerator
           ? 'border-2 border-blue-500 bg-blue-50 dark:border-blue-400 dark:bg-blue-950'
           : 'border border-gray-200 bg-white dark:border-gray-700 dark:bg-gray-800'
@@ -20,6 +20,15 @@ export function MessageBubble({ event }: MessageBubbleProps) {
       <div className="mb-2 flex items-center justify-between">
         <span className={`font-semibold ${isModerator ? 'text-blue-700 dark:text-blue-300' : 'text-gray-900 dark:text-gray-100'}`}>
           {event.participant}
+          {event.reply_to && (
+            <span className="ml-2 text-sm font-normal text-gray-500 dark:text-gray-400">re #{event.reply_to}</span>
+          )}
+          {event.mentions && event.mentions.length > 0 && (
+            <span className="ml-2 text-sm font-normal text-amber-600 dark:text-amber-400">
+              {event.mentions.map((name) => `@${name}`).join(', ')}
+            </span>
+          )}
+          {event.edited && <span className="ml-2 text-sm font-normal text-gray-500 dark:text-gray-400">(edited)</span>}
         </span>
         <div className="flex items-center gap-2 text-xs text-gray-400 dark:text-gray-500">
           <span>{formatTimestamp(event.timestamp_millis)}</span>
@@ -27,7 +36,11 @@ export function MessageBubble({ event }: MessageBubbleProps) {
         </div>
       </div>
       <div className="markdown-content text-gray-800 dark:text-gray-200">
-        <Markdown>{event.content || ''}</Markdown>
+        {event.retracted ? (
+          <p className="italic text-gray-500 dark:text-gray-400">This message was retracted.</p>
+        ) : (
+          <Markdown>{event.content || ''}</Markdown>
+        )}
       </div>
       {event.next && (
         <div className="mt-2 text-right text-sm text-gray-500 dark:text-gray-400">
@@ -4,10 +4,11 @@ import { MessageBubble } from './MessageBubble';
 import { EventNotice } from './EventNotice';
 
 interface MessageListProps {
+  sessionId: string;
   events: APIEvent[];
 }
 
-export function MessageList({ events }: MessageListProps) {
+export function MessageList({ sessionId, events }: MessageListProps) {
   const containerRef = useRef<HTMLDivElement>(null);
   const prevEventCountRef = useRef(0);
 
@@ -30,7 +31,7 @@ export function MessageList({ events }: MessageListProps) {
           event.type === 'message' ? (
             <MessageBubble key={event.number} event={event} />
           ) : (
-            <EventNotice key={event.number} event={event} />
+            <EventNotice key={event.number} sessionId={sessionId} event={event} />
           )
         )
       )}
@@ -1,12 +1,19 @@
 import { useState, useEffect, useCallback, useRef } from 'react';
-import type { APIEvent } from '../types';
+import type { APIEvent, Fact, ForkOrigin, Metadata, Poll, Task, Verification } from '../types';
 import { fetchStatus } from '../api/client';
+import { applyRevisions } from '../utils/revisions';
 
 const POLL_INTERVAL = 1000;
 
 interface UseSessionResult {
   events: APIEvent[];
   participants: string[];
+  forkedFrom: ForkOrigin | null;
+  metadata: Metadata | null;
+  verification: Verification | null;
+  polls: Poll[];
+  facts: Fact[];
+  tasks: Task[];
   sessionId: string;
   eventCount: number;
   loading: boolean;
@@ -17,6 +24,12 @@ interface UseSessionResult {
 export function useSession(sessionId: string): UseSessionResult {
   const [events, setEvents] = useState<APIEvent[]>([]);
   const [participants, setParticipants] = useState<string[]>([]);
+  const [forkedFrom, setForkedFrom] = useState<ForkOrigin | null>(null);
+  const [metadata, setMetadata] = useState<Metadata | null>(null);
+  const [verification, setVerification] = useState<Verification | null>(null);
+  const [polls, setPolls] = useState<Poll[]>([]);
+  const [facts, setFacts] = useState<Fact[]>([]);
+  const [tasks, setTasks] = useState<Task[]>([]);
   const [eventCount, setEventCount] = useState(0);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
@@ -29,10 +42,15 @@ export function useSession(sessionId: string): UseSessionResult {
       const data = await fetchStatus(sessionId, lastEventNumRef.current);
 
       if (data.events.length > 0) {
-        setEvents((prev) => [...prev, ...data.events]);
+        setEvents((prev) => applyRevisions(prev, data.events));
       }
 
       setParticipants(data.participants);
+      setMetadata(data.metadata);
+      setVerification(data.verification ?? null);
+      setPolls(data.polls);
+      setFacts(data.facts);
+      setTasks(data.tasks);
       setEventCount(data.event_count);
       lastEventNumRef.current = data.event_count;
       setError(null);
@@ -49,6 +67,12 @@ export function useSession(sessionId: string): UseSessionResult {
     // Reset state when sessionId changes
     setEvents([]);
     setParticipants([]);
+    setForkedFrom(null);
+    setMetadata(null);
+    setVerification(null);
+    setPolls([]);
+    setFacts([]);
+    setTasks([]);
     setEventCount(0);
     setLoading(true);
     setError(null);
@@ -58,8 +82,14 @@ export function useSession(sessionId: string): UseSessionResult {
     const initialFetch = async () => {
       try {
         const data = await fetchStatus(sessionId);
-        setEvents(data.events);
+        setEvents(applyRevisions([], data.events));
         setParticipants(data.participants);
+        setForkedFrom(data.forked_from ?? null);
+        setMetadata(data.metadata);
+        setVerification(data.verification ?? null);
+        setPolls(data.polls);
+        setFacts(data.facts);
+        setTasks(data.tasks);
         setEventCount(data.event_count);
         lastEventNumRef.current = data.event_count;
         setLoading(false);
@@ -81,5 +111,5 @@ export function useSession(sessionId: string): UseSessionResult {
     return () => clearInterval(interval);
   }, [sessionId, poll]);
 
-  return { events, participants, sessionId, eventCount, loading, error, refetch };
+  return { events, participants, forkedFrom, metadata, verification, polls, facts, tasks, sessionId, eventCount, loading, error, refetch };
 }
@@ -1,4 +1,4 @@
-export type EventType = 'session_created' | 'joined' | 'left' | 'message';
+export type EventType = 'session_created' | 'joined' | 'left' | 'message' | 'forked_from' | 'forked' | 'session_updated' | 'poll' | 'vote' | 'decision' | 'message_edited' | 'message_retracted' | 'artifact_added' | 'fact_set' | 'fact_deleted' | 'task_added' | 'task_assigned' | 'task_done';
 
 export interface APIEvent {
   number: number;
@@ -7,13 +7,107 @@ export interface APIEvent {
   participant?: string;
   content?: string;
   next?: string;
+  reply_to?: number;
+  // Participants @mentioned in the content, omitted once retracted
+  mentions?: string[];
+  // Edits and retractions: message is the event number of the message changed.
+  // Retracted messages are sent without content.
+  message?: number;
+  edited?: boolean;
+  retracted?: boolean;
   id?: string;
+  fork_session?: string;
+  fork_event?: number;
+  // Poll and vote fields; choice is omitted for votes in secret polls
+  question?: string;
+  options?: string[];
+  poll?: number;
+  choice?: string;
+  // Artifact fields; the content is downloaded from /api/artifact
+  name?: string;
+  size?: number;
+  hash?: string;
+  // Fact fields; value is omitted for deletions
+  key?: string;
+  value?: string;
+  fact_version?: number;
+  // Task fields; task is the event number of the task assigned or done
+  description?: string;
+  owner?: string;
+  task?: number;
+  // Decision fields
+  title?: string;
+  rationale?: string;
+  alternatives?: string[];
+  supersedes?: number;
+  // Fields of event types this version of council doesn't know
+  data?: Record<string, unknown>;
+}
+
+export interface ForkOrigin {
+  session_id: string;
+  event_num: number;
+}
+
+export interface Metadata {
+  title: string;
+  goal: string;
+  description: string;
+  tags: string[];
+}
+
+export interface Verification {
+  intact: boolean;
+  events: number;
+  broken_line?: number;
+  reason?: string;
+  head?: string;
+}
+
+export interface PollResult {
+  choice: string;
+  count: number;
+  voters?: string[];
+}
+
+export interface Poll {
+  event_num: number;
+  participant: string;
+  question: string;
+  secret: boolean;
+  closes_after?: number;
+  closed: boolean;
+  results: PollResult[];
+}
+
+export interface Fact {
+  key: string;
+  value: string;
+  version: number;
+  participant: string;
+  event_num: number;
+}
+
+export interface Task {
+  event_num: number;
+  participant: string;
+  description: string;
+  owner?: string;
+  done: boolean;
+  done_by?: string;
+  done_at?: number;
 }
 
 export interface StatusResponse {
   session_id: string;
   participants: string[];
   event_count: number;
+  metadata: Metadata;
+  forked_from?: ForkOrigin;
+  verification?: Verification;
+  polls: Poll[];
+  facts: Fact[];
+  tasks: Task[];
   events: APIEvent[];
 }
 
@@ -22,6 +116,7 @@ export interface PostRequest {
   content: string;
   after: number;
   next?: string;
+  reply_to?: number;
 }
 
 export interface PostResponse {
@@ -0,0 +1,21 @@
+import type { APIEvent } from '../types';
+
+// applyRevisions appends new events to the ones already shown, updating
+// earlier messages that the new events edit or retract
+export function applyRevisions(prev: APIEvent[], incoming: APIEvent[]): APIEvent[] {
+  const events = [...prev, ...incoming];
+  for (const change of incoming) {
+    if (change.type !== 'message_edited' && change.type !== 'message_retracted') {
+      continue;
+    }
+    const i = events.findIndex((e) => e.type === 'message' && e.number === change.message);
+    if (i === -1 || events[i].retracted) {
+      continue;
+    }
+    events[i] =
+      change.type === 'message_edited'
+        ? { ...events[i], content: change.content, mentions: change.mentions, edited: true }
+        : { ...events[i], content: undefined, mentions: undefined, edited: false, retracted: true };
+  }
+  return events;
+}